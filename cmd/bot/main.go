@@ -6,7 +6,9 @@ import (
 	"os"
 	"os/signal"
 	"strings"
+	"time"
 
+	"whitelist-bot/internal/callbacks"
 	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/core/db"
 	"whitelist-bot/internal/core/kv"
@@ -15,27 +17,41 @@ import (
 	"whitelist-bot/internal/fsm"
 	memoryFSM "whitelist-bot/internal/fsm/memory"
 	"whitelist-bot/internal/handlers"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/jobs"
+	"whitelist-bot/internal/locker"
 	memoryLocker "whitelist-bot/internal/locker/memory"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/outbox"
+	"whitelist-bot/internal/ratelimit"
 	"whitelist-bot/internal/router"
 	"whitelist-bot/internal/router/matcher"
+	"whitelist-bot/internal/scheduler"
+	"whitelist-bot/internal/sender"
 	"whitelist-bot/internal/wp"
 
 	bh "whitelist-bot/internal/eventbus/handlers"
 
 	memoryEventBus "whitelist-bot/internal/eventbus/memory"
+	natsEventBus "whitelist-bot/internal/eventbus/nats"
+	natsFSM "whitelist-bot/internal/fsm/nats"
+	postgresFSM "whitelist-bot/internal/fsm/postgres"
+	natsLocker "whitelist-bot/internal/locker/nats"
 	natsMetastore "whitelist-bot/internal/metastore/nats"
+	memoryRateLimit "whitelist-bot/internal/ratelimit/memory"
+	natsRateLimit "whitelist-bot/internal/ratelimit/nats"
+	postgresDeadLetterRepository "whitelist-bot/internal/repository/dead_letter/postgres"
+	postgresOutboxRepository "whitelist-bot/internal/repository/outbox/postgres"
+	userCache "whitelist-bot/internal/repository/user/cache"
 	postgresUserRepository "whitelist-bot/internal/repository/user/postgres"
 	postgresWLRequestRepository "whitelist-bot/internal/repository/wl_request/postgres"
+
+	"github.com/go-telegram/bot/models"
 )
 
 // TODO: write tests !!!!!!!!!!
 // TODO: add validation for nickname. Length, special characters, etc.
-// TODO: add middleware for checking permissions.
-// TODO: add middleware for recovering panics.
-// TODO: add requests limit for users.
 // TODO: refactor to use Must methods for initialization.
-// TODO: add custom update context, set user to context.
-// TODO: add wrapper for bot sending message methods. Retry logic, error handling, default parse mode.
 
 func main() {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
@@ -51,8 +67,15 @@ func main() {
 	logger.InitLogger(cfg.Logs)
 	slog.Info("Logger initialized successfully")
 
-	lockerService := memoryLocker.New()
-	fsmService := memoryFSM.New()
+	if cfg.Templates.Dir != "" {
+		if err := msgs.LoadTemplates(cfg.Templates.Dir); err != nil {
+			slog.Error("Failed to load message templates", "error", err.Error())
+			os.Exit(1)
+		}
+		go msgs.WatchTemplates(ctx, cfg.Templates.ReloadInterval)
+		slog.Info("Message templates loaded", "dir", cfg.Templates.Dir)
+	}
+	msgs.SetServer(cfg.Server.Address)
 
 	dbPG, err := db.GetPostgresDB(ctx, cfg.Postgres.URL)
 	if err != nil {
@@ -68,17 +91,100 @@ func main() {
 	}
 	defer conn.Drain()
 
-	userRepo := postgresUserRepository.NewUserRepository(dbPG)
+	userRepo := userCache.NewUserRepository(postgresUserRepository.NewUserRepository(dbPG), cfg.Cache.UserTTL)
 	wlRequestRepo := postgresWLRequestRepository.NewWLRequestRepository(dbPG)
+	deadLetterRepo := postgresDeadLetterRepository.NewDeadLetterRepository(dbPG)
+	outboxRepo := postgresOutboxRepository.NewOutboxRepository(dbPG)
 
-	metastoreService, err := natsMetastore.New(ctx, conn, "whitelist-bot", cfg.Nats.MetastoreReplicas)
+	metastoreService, err := natsMetastore.New(ctx, conn, "whitelist-bot", cfg.Nats.MetastoreReplicas, natsMetastore.DefaultTTL)
 	if err != nil {
 		slog.Error("Failed to create NATS metastore", "error", err.Error())
 		os.Exit(1)
 	}
+	// The chats with a role menu must outlive any uptime, or a removed role keeps its menu.
+	commandMenuStore, err := natsMetastore.New(ctx, conn, "whitelist-bot-command-menu", cfg.Nats.MetastoreReplicas, 0)
+	if err != nil {
+		slog.Error("Failed to create NATS command menu store", "error", err.Error())
+		os.Exit(1)
+	}
+
+	stateTTL := fsm.StateTTL{
+		fsm.StateWaitingWLNickname: cfg.FSM.WaitingWLNicknameTTL,
+	}
+	var fsmService fsm.IFSM
+	switch cfg.FSM.Backend {
+	case "postgres":
+		fsmService = postgresFSM.NewFSM(dbPG, stateTTL)
+	case "nats":
+		fsmService = natsFSM.New(metastoreService, stateTTL)
+	default:
+		fsmService = memoryFSM.New(stateTTL)
+	}
+	slog.Info("FSM initialized", "backend", cfg.FSM.Backend)
+
+	var lockerService locker.ILocker
+	switch cfg.Locker.Backend {
+	case "nats":
+		lockerService, err = natsLocker.New(
+			ctx,
+			conn,
+			"whitelist-bot-locks",
+			cfg.Nats.MetastoreReplicas,
+			cfg.Locker.LeaseTTL,
+			cfg.Locker.HoldTimeout,
+		)
+		if err != nil {
+			slog.Error("Failed to create NATS locker", "error", err.Error())
+			os.Exit(1)
+		}
+	default:
+		lockerService = memoryLocker.New(cfg.Locker.HoldTimeout)
+	}
+	slog.Info("Locker initialized", "backend", cfg.Locker.Backend)
 
-	eBus := memoryEventBus.New(10)
+	var rateLimitStore ratelimit.IStore
+	switch cfg.RateLimit.Backend {
+	case "nats":
+		rateLimitStore = natsRateLimit.New(metastoreService)
+	default:
+		rateLimitStore = memoryRateLimit.New()
+	}
+	rateLimiter := ratelimit.NewLimiter(rateLimitStore, ratelimit.Limits{
+		ratelimit.ClassMessage:         {Burst: cfg.RateLimit.MessageBurst, Interval: cfg.RateLimit.MessageInterval},
+		ratelimit.ClassCallback:        {Burst: cfg.RateLimit.CallbackBurst, Interval: cfg.RateLimit.CallbackInterval},
+		ratelimit.ClassWLRequestSubmit: {Burst: cfg.RateLimit.SubmitBurst, Interval: cfg.RateLimit.SubmitInterval},
+		ratelimit.ClassWarning:         {Burst: 1, Interval: cfg.RateLimit.WarningInterval},
+	})
+	slog.Info("Rate limiter initialized", "backend", cfg.RateLimit.Backend)
+
+	var eBus eventbus.EventBus
+	switch cfg.EventBus.Backend {
+	case "nats":
+		eBus, err = natsEventBus.New(ctx, conn, "whitelist-bot-events", cfg.Nats.MetastoreReplicas)
+		if err != nil {
+			slog.Error("Failed to create NATS event bus", "error", err.Error())
+			os.Exit(1)
+		}
+	default:
+		topicConfigs, err := memoryEventBus.NewTopicConfigs(cfg.EventBus.TopicCapacity, cfg.EventBus.TopicOverflow)
+		if err != nil {
+			slog.Error("Failed to configure memory event bus", "error", err.Error())
+			os.Exit(1)
+		}
+		eBus = memoryEventBus.New(memoryEventBus.TopicConfig{
+			Capacity: cfg.EventBus.BufferCapacity,
+			Overflow: memoryEventBus.OverflowPolicy(cfg.EventBus.Overflow),
+		}, topicConfigs)
+	}
 	defer eBus.Close()
+	slog.Info("Event bus initialized", "backend", cfg.EventBus.Backend)
+
+	schedulerLocation, err := time.LoadLocation(cfg.Scheduler.Timezone)
+	if err != nil {
+		slog.Error("Failed to load scheduler timezone", "error", err.Error())
+		os.Exit(1)
+	}
+	jobScheduler := scheduler.New()
 
 	sem, err := wp.NewSemaphore(10)
 	if err != nil {
@@ -88,6 +194,14 @@ func main() {
 	r, err := router.NewTelegramRouter(
 		fsmService,
 		lockerService,
+		cfg.Locker.WaitTimeout,
+		cfg.Telegram.ProfileSyncInterval,
+		rateLimiter,
+		router.Roles{
+			router.RoleAdmin:  cfg.Telegram.AdminIDs,
+			router.RoleOnDuty: cfg.Telegram.OnDutyAdminIDs(),
+			router.RoleOwner:  cfg.Telegram.OwnerIDs,
+		},
 		userRepo,
 		handlers.GlobalErrorHandler(),
 		handlers.GlobalSuccessHandler(cfg),
@@ -106,63 +220,142 @@ func main() {
 		os.Exit(1)
 	}
 
+	messageSender := sender.New(r.Bot(), sender.Config{
+		GlobalLimit: ratelimit.Limit{Burst: cfg.Sender.GlobalRate, Interval: time.Second / time.Duration(cfg.Sender.GlobalRate)},
+		ChatLimit:   ratelimit.Limit{Burst: 1, Interval: cfg.Sender.ChatInterval},
+		MaxRetries:  cfg.Sender.MaxRetries,
+		BaseBackoff: cfg.Sender.BaseBackoff,
+		MaxBackoff:  cfg.Sender.MaxBackoff,
+		Workers:     cfg.Sender.Workers,
+		ParseMode:   models.ParseMode(cfg.Sender.ParseMode),
+	})
+	go messageSender.Run(ctx)
+	r.SetSender(messageSender)
+
+	r.Use(
+		router.Recover(),
+		router.Logging(),
+		router.Maintenance(cfg.Server.Maintenance, cfg.Telegram.AdminIDs...),
+	)
+	// Inline button data is signed, forged or stale buttons are rejected before any handler runs.
+	callbackRegistry := callbacks.NewRegistry(callbacks.NewCodec(cfg.Callback.Key(cfg.Telegram.Token), cfg.Callback.TTL))
+
 	// START HANDLER
-	r.RegisterHandlerMatchFunc(
-		matcher.Command(core.CommandCancel),
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandCancel, Description: i18n.MenuCancel},
+		nil,
 		handlers.Cancel(),
 	)
 
 	// INFO HANDLER
 	r.RegisterHandlerMatchFunc(
 		matcher.And(
-			matcher.MsgText(core.CommandInfo),
-			r.StateMatchFunc(ctx, fsm.StateIdle),
+			matcher.LocalizedMsgText(core.CommandInfo),
+			matcher.State(fsm.StateIdle),
 		),
-		handlers.Info(userRepo),
+		handlers.Info(),
+	)
+
+	// RULES HANDLER
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandRules, Description: i18n.MenuRules},
+		matcher.State(fsm.StateIdle),
+		handlers.Rules(),
+	)
+
+	// LANGUAGE HANDLER
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandLanguage, Description: i18n.MenuLanguage},
+		matcher.State(fsm.StateIdle),
+		handlers.Language(userRepo),
+	)
+
+	// DEAD LETTERS HANDLER
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandDeadLetters, Description: i18n.MenuDeadLetters, Role: router.RoleAdmin},
+		matcher.State(fsm.StateIdle),
+		handlers.DeadLetters(deadLetterRepo, eBus),
+	)
+
+	// JOBS HANDLER
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandJobs, Description: i18n.MenuJobs, Role: router.RoleAdmin},
+		matcher.State(fsm.StateIdle),
+		handlers.Jobs(jobScheduler),
+	)
+
+	// WL REQUEST ADMIN COMMANDS
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandApprove, Description: i18n.MenuApprove, Role: router.RoleAdmin},
+		matcher.State(fsm.StateIdle),
+		handlers.ApproveWLRequestCommand(userRepo, wlRequestRepo),
+	)
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandDecline, Description: i18n.MenuDecline, Role: router.RoleAdmin},
+		matcher.State(fsm.StateIdle),
+		handlers.DeclineWLRequestCommand(userRepo, wlRequestRepo),
+	)
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandRevoke, Description: i18n.MenuRevoke, Role: router.RoleAdmin},
+		matcher.State(fsm.StateIdle),
+		handlers.RevokeWLRequestCommand(userRepo, wlRequestRepo),
+	)
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandShow, Description: i18n.MenuShow, Role: router.RoleAdmin},
+		matcher.State(fsm.StateIdle),
+		handlers.ShowWLRequest(userRepo, wlRequestRepo),
 	)
 
 	// NEW WL REQUEST HANDLERS
 	r.RegisterHandlerMatchFunc(
-		matcher.And(matcher.MsgText(core.CommandNewWLRequest), r.StateMatchFunc(ctx, fsm.StateIdle)),
+		matcher.And(matcher.LocalizedMsgText(core.CommandNewWLRequest), matcher.State(fsm.StateIdle)),
 		handlers.NewWLRequest(),
 	)
 	r.RegisterHandlerMatchFunc(
 		matcher.And(
-			matcher.MsgText(core.CommandViewPendingWLRequests),
-			r.StateMatchFunc(ctx, fsm.StateIdle),
-			matcher.MatchTelegramIDs(cfg.Telegram.AdminIDs...),
+			matcher.LocalizedMsgText(core.CommandViewPendingWLRequests),
+			matcher.State(fsm.StateIdle),
+			matcher.Role(router.RoleAdmin),
 		),
-		handlers.ViewPendingWLRequests(wlRequestRepo),
+		handlers.ViewPendingWLRequests(wlRequestRepo, userRepo, callbackRegistry),
 	)
 	r.RegisterHandlerMatchFunc(
-		r.StateMatchFunc(ctx, fsm.StateWaitingWLNickname),
-		handlers.SubmitWLRequestNickname(userRepo, wlRequestRepo, eBus),
+		matcher.And(matcher.State(fsm.StateWaitingWLNickname), matcher.TextMessage()),
+		r.RateLimited(ratelimit.ClassWLRequestSubmit, handlers.SubmitWLRequestNickname(wlRequestRepo)),
 	)
 
-	r.RegisterHandlerMatchFunc(
-		matcher.And(
-			matcher.CallbackAction(core.ActionWLRequestApprove),
-			matcher.MatchTelegramIDs(cfg.Telegram.AdminIDs...),
-		),
-		handlers.ApproveWLRequest(userRepo, wlRequestRepo))
-	r.RegisterHandlerMatchFunc(
-		matcher.And(
-			matcher.CallbackAction(core.ActionWLRequestDecline),
-			matcher.MatchTelegramIDs(cfg.Telegram.AdminIDs...),
-		),
-		handlers.DeclineWLRequest(userRepo, wlRequestRepo))
+	// CALLBACK HANDLERS
+	callbacks.Register(callbackRegistry,
+		callbacks.ActionWLRequestApprove,
+		callbacks.DecodeWLRequestData,
+		handlers.ApproveWLRequest(userRepo, wlRequestRepo),
+		router.RequireRole(router.RoleAdmin),
+	)
+	callbacks.Register(callbackRegistry,
+		callbacks.ActionWLRequestDecline,
+		callbacks.DecodeWLRequestData,
+		handlers.DeclineWLRequest(userRepo, wlRequestRepo),
+		router.RequireRole(router.RoleAdmin),
+	)
+	r.RegisterHandlerMatchFunc(callbackRegistry.Match, callbackRegistry.Handle)
 
 	// START HANDLER
-	r.RegisterHandlerMatchFunc(
-		matcher.Command(core.CommandStart),
+	r.RegisterCommand(
+		router.BotCommand{Name: core.CommandStart, Description: i18n.MenuStart},
+		nil,
 		handlers.Start(),
 	)
 
 	consumerPool := eventbus.NewConsumerPool(eBus, []eventbus.ConsumerUnit{
-		{
-			Topic:   core.TopicWLRequestCreated,
-			Handler: bh.HandleWLRequestCreatedEvent(metastoreService, metastoreService, r.Bot(), cfg.Telegram.AdminIDs),
-		},
+		eventbus.Subscribe(bh.TopicWLRequestCreated, "", bh.HandleWLRequestCreatedEvent(
+			metastoreService,
+			metastoreService,
+			messageSender.WithPriority(sender.PriorityNotification),
+			userRepo,
+			cfg.Telegram.AdminIDs,
+		)),
+		eventbus.Subscribe(bh.TopicWLRequestExpired, "", bh.HandleWLRequestExpiredEvent(messageSender.WithPriority(sender.PriorityNotification), userRepo)),
+		eventbus.Subscribe(eventbus.DeadLetterTopic, "", bh.HandleDeadLetterEvent(deadLetterRepo)),
 	}, sem)
 	err = consumerPool.Start(ctx)
 	if err != nil {
@@ -170,7 +363,95 @@ func main() {
 		os.Exit(1)
 	}
 
-	r.Start(ctx)
+	outboxRelay := outbox.NewRelay(
+		outboxRepo,
+		eBus,
+		cfg.Outbox.PollInterval,
+		cfg.Outbox.BatchSize,
+		cfg.Outbox.LeaseTTL,
+		cfg.Outbox.Retention,
+	)
+	go outboxRelay.Run(ctx)
+
+	outboxCleanupSchedule, err := scheduler.ParseCron(cfg.Outbox.CleanupSchedule, schedulerLocation)
+	if err != nil {
+		slog.Error("Failed to parse outbox cleanup schedule", "error", err.Error())
+		os.Exit(1)
+	}
+	err = jobScheduler.Add(scheduler.Job{
+		Name:     "outbox-cleanup",
+		Schedule: outboxCleanupSchedule,
+		Run:      outboxRelay.Cleanup,
+		Timeout:  cfg.Scheduler.JobTimeout,
+		Jitter:   cfg.Scheduler.Jitter,
+	})
+	if err != nil {
+		slog.Error("Failed to add outbox cleanup job", "error", err.Error())
+		os.Exit(1)
+	}
+
+	wlRequestExpirationSchedule, err := scheduler.ParseCron(cfg.WLRequest.ExpirationSchedule, schedulerLocation)
+	if err != nil {
+		slog.Error("Failed to parse wl request expiration schedule", "error", err.Error())
+		os.Exit(1)
+	}
+	err = jobScheduler.Add(scheduler.Job{
+		Name:     "wl-request-expiration",
+		Schedule: wlRequestExpirationSchedule,
+		Run: jobs.ExpireWLRequests(
+			wlRequestRepo,
+			messageSender.WithPriority(sender.PriorityBulk),
+			userRepo,
+			cfg.Telegram.AdminIDs,
+			cfg.WLRequest.MaxPendingAge,
+			cfg.WLRequest.ExpirationBatchSize,
+		),
+		Timeout: cfg.Scheduler.JobTimeout,
+		Jitter:  cfg.Scheduler.Jitter,
+	})
+	if err != nil {
+		slog.Error("Failed to add wl request expiration job", "error", err.Error())
+		os.Exit(1)
+	}
+
+	wlRequestReminderSchedule, err := scheduler.ParseCron(cfg.WLRequest.ReminderSchedule, schedulerLocation)
+	if err != nil {
+		slog.Error("Failed to parse wl request reminder schedule", "error", err.Error())
+		os.Exit(1)
+	}
+	err = jobScheduler.Add(scheduler.Job{
+		Name:     "wl-request-reminders",
+		Schedule: wlRequestReminderSchedule,
+		Run: jobs.RemindAboutWLRequests(
+			wlRequestRepo,
+			metastoreService,
+			messageSender.WithPriority(sender.PriorityBulk),
+			userRepo,
+			cfg.Telegram.OnDutyAdminIDs(),
+			cfg.Telegram.OwnerIDs,
+			cfg.WLRequest.RemindAfter,
+			cfg.WLRequest.EscalateAfter,
+			cfg.WLRequest.MaxPendingAge,
+		),
+		Timeout: cfg.Scheduler.JobTimeout,
+		Jitter:  cfg.Scheduler.Jitter,
+	})
+	if err != nil {
+		slog.Error("Failed to add wl request reminders job", "error", err.Error())
+		os.Exit(1)
+	}
+	jobScheduler.Start(ctx)
+
+	// The menus are built from the registered commands, so they are published once every route is added.
+	if err := r.PublishCommands(ctx, commandMenuStore); err != nil {
+		slog.Error("Failed to publish command menus", "error", err.Error())
+	}
+
+	if err := r.Start(ctx); err != nil {
+		slog.Error("Failed to start bot", "error", err.Error())
+		cancel()
+	}
 
 	consumerPool.Wait()
+	jobScheduler.Wait()
 }
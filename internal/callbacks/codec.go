@@ -0,0 +1,87 @@
+package callbacks
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"fmt"
+	"time"
+	"whitelist-bot/internal/core"
+)
+
+const (
+	version1 byte = 1
+	// headerSize is the version, the action and the issue time in Unix seconds.
+	headerSize = 1 + 1 + 4
+	// tagSize is the length of the truncated HMAC-SHA256 tag.
+	tagSize = 10
+	// MaxDataLength is the Telegram limit of callback data.
+	MaxDataLength = 64
+)
+
+var encoding = base64.RawURLEncoding
+
+// Codec signs callback data, so buttons can't be forged, and expires it after the TTL.
+// The data is base64url of: version | action | issued at | payload | tag.
+type Codec struct {
+	key []byte
+	ttl time.Duration
+	now func() time.Time
+}
+
+// NewCodec creates a codec signing with the key. A zero TTL makes the data never expire.
+func NewCodec(key []byte, ttl time.Duration) *Codec {
+	return &Codec{key: key, ttl: ttl, now: time.Now}
+}
+
+func (c *Codec) Encode(action Action, payload []byte) (string, error) {
+	raw := make([]byte, headerSize, headerSize+len(payload)+tagSize)
+	raw[0] = version1
+	raw[1] = byte(action)
+	binary.BigEndian.PutUint32(raw[2:headerSize], uint32(c.now().Unix()))
+	raw = append(raw, payload...)
+	raw = append(raw, c.tag(raw)...)
+
+	data := encoding.EncodeToString(raw)
+	if len(data) > MaxDataLength {
+		return "", fmt.Errorf("callback data of %d bytes exceeds the limit of %d", len(data), MaxDataLength)
+	}
+	return data, nil
+}
+
+// Decode verifies the data and returns its action and payload.
+// It fails with core.ErrInvalidCallback for malformed or tampered data and core.ErrCallbackExpired for stale data.
+// The legacy JSON data of buttons sent before the signing is accepted until the cutoff in legacy.go.
+func (c *Codec) Decode(data string) (Action, []byte, error) {
+	if isLegacy(data) {
+		return decodeLegacy(data, c.now())
+	}
+	raw, err := encoding.DecodeString(data)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %w", core.ErrInvalidCallback, err)
+	}
+	if len(raw) < headerSize+tagSize {
+		return 0, nil, fmt.Errorf("%w: too short", core.ErrInvalidCallback)
+	}
+	if raw[0] != version1 {
+		return 0, nil, fmt.Errorf("%w: unsupported version %d", core.ErrInvalidCallback, raw[0])
+	}
+
+	signed, tag := raw[:len(raw)-tagSize], raw[len(raw)-tagSize:]
+	if !hmac.Equal(tag, c.tag(signed)) {
+		return 0, nil, fmt.Errorf("%w: bad signature", core.ErrInvalidCallback)
+	}
+
+	issuedAt := time.Unix(int64(binary.BigEndian.Uint32(signed[2:headerSize])), 0)
+	if c.ttl > 0 && c.now().Sub(issuedAt) > c.ttl {
+		return 0, nil, fmt.Errorf("%w: issued at %s", core.ErrCallbackExpired, issuedAt.Format(time.RFC3339))
+	}
+	return Action(signed[1]), signed[headerSize:], nil
+}
+
+func (c *Codec) tag(signed []byte) []byte {
+	mac := hmac.New(sha256.New, c.key)
+	mac.Write(signed)
+	return mac.Sum(nil)[:tagSize]
+}
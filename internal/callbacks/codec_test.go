@@ -0,0 +1,120 @@
+package callbacks
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCodec_RoundTrip(t *testing.T) {
+	codec := NewCodec([]byte("secret"), time.Hour)
+	id := uuid.New()
+
+	data, err := codec.Encode(ActionWLRequestApprove, id[:])
+	require.NoError(t, err)
+	assert.LessOrEqual(t, len(data), MaxDataLength)
+
+	action, payload, err := codec.Decode(data)
+	require.NoError(t, err)
+	assert.Equal(t, ActionWLRequestApprove, action)
+	assert.Equal(t, id[:], payload)
+}
+
+func TestCodec_Decode_Errors(t *testing.T) {
+	// Before the legacy cutoff, so the legacy cases fail for their data and not for its age.
+	now := legacyAcceptedUntil.Add(-time.Hour)
+	codec := NewCodec([]byte("secret"), time.Hour)
+	codec.now = func() time.Time { return now }
+	id := uuid.New()
+	valid, err := codec.Encode(ActionWLRequestDecline, id[:])
+	require.NoError(t, err)
+	raw, err := base64.RawURLEncoding.DecodeString(valid)
+	require.NoError(t, err)
+
+	tamper := func(f func(raw []byte)) string {
+		changed := append([]byte(nil), raw...)
+		f(changed)
+		return base64.RawURLEncoding.EncodeToString(changed)
+	}
+
+	tests := []struct {
+		name    string
+		data    string
+		codec   *Codec
+		wantErr error
+	}{
+		{name: "legacy bad id", data: `{"id":"x","action":"wlapp"}`, wantErr: core.ErrInvalidCallback},
+		{name: "legacy unknown action", data: `{"id":"` + id.String() + `","action":"wlrev"}`, wantErr: core.ErrInvalidCallback},
+		{name: "legacy malformed", data: `{"id":`, wantErr: core.ErrInvalidCallback},
+		{name: "too short", data: base64.RawURLEncoding.EncodeToString(raw[:headerSize]), wantErr: core.ErrInvalidCallback},
+		{name: "unknown version", data: tamper(func(raw []byte) { raw[0] = 2 }), wantErr: core.ErrInvalidCallback},
+		{name: "changed action", data: tamper(func(raw []byte) { raw[1] = byte(ActionWLRequestApprove) }), wantErr: core.ErrInvalidCallback},
+		{name: "changed payload", data: tamper(func(raw []byte) { raw[headerSize] ^= 1 }), wantErr: core.ErrInvalidCallback},
+		{name: "other key", data: valid, codec: NewCodec([]byte("other"), time.Hour), wantErr: core.ErrInvalidCallback},
+		{
+			name: "expired",
+			data: valid,
+			codec: &Codec{key: []byte("secret"), ttl: time.Hour, now: func() time.Time {
+				return now.Add(2 * time.Hour)
+			}},
+			wantErr: core.ErrCallbackExpired,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := codec
+			if tt.codec != nil {
+				c = tt.codec
+			}
+			_, _, err := c.Decode(tt.data)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+}
+
+func TestCodec_Decode_Legacy(t *testing.T) {
+	codec := NewCodec([]byte("secret"), time.Hour)
+	codec.now = func() time.Time { return legacyAcceptedUntil.Add(-time.Second) }
+	id := uuid.New()
+
+	tests := []struct {
+		data       string
+		wantAction Action
+	}{
+		{data: `{"id":"` + id.String() + `","action":"wlapp"}`, wantAction: ActionWLRequestApprove},
+		{data: `{"id":"` + id.String() + `","action":"wldec"}`, wantAction: ActionWLRequestDecline},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.wantAction.String(), func(t *testing.T) {
+			action, payload, err := codec.Decode(tt.data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantAction, action)
+
+			data, err := DecodeWLRequestData(payload)
+			require.NoError(t, err)
+			assert.Equal(t, id, uuid.UUID(data.ID))
+		})
+	}
+}
+
+func TestCodec_Decode_LegacyAfterCutoff(t *testing.T) {
+	codec := NewCodec([]byte("secret"), 0)
+	codec.now = func() time.Time { return legacyAcceptedUntil }
+
+	_, _, err := codec.Decode(`{"id":"` + uuid.NewString() + `","action":"wlapp"}`)
+	require.ErrorIs(t, err, core.ErrCallbackExpired)
+}
+
+func TestCodec_Encode_TooLong(t *testing.T) {
+	codec := NewCodec([]byte("secret"), 0)
+
+	_, err := codec.Encode(ActionWLRequestApprove, make([]byte, MaxDataLength))
+	require.Error(t, err)
+}
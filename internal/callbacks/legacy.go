@@ -0,0 +1,50 @@
+package callbacks
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"whitelist-bot/internal/core"
+
+	"github.com/google/uuid"
+)
+
+// Buttons sent before the data was signed carry JSON like {"id":"<wl request ID>","action":"wlapp"}.
+// They are accepted until legacyAcceptedUntil, so the buttons already posted in admin chats keep working
+// through the transition. After it they are rejected as expired; remove this file and its tests then.
+var legacyAcceptedUntil = time.Date(2026, time.November, 30, 0, 0, 0, 0, time.UTC)
+
+var legacyActions = map[string]Action{
+	"wlapp": ActionWLRequestApprove,
+	"wldec": ActionWLRequestDecline,
+}
+
+// isLegacy tells the JSON data apart: a brace is not in the base64url alphabet.
+func isLegacy(data string) bool {
+	return strings.HasPrefix(data, "{")
+}
+
+// decodeLegacy returns the action and the payload of the legacy data in the current format.
+// The data is neither signed nor dated, the handlers of its actions check the role of the user anyway.
+func decodeLegacy(data string, now time.Time) (Action, []byte, error) {
+	if !now.Before(legacyAcceptedUntil) {
+		return 0, nil, fmt.Errorf("%w: legacy data is accepted until %s", core.ErrCallbackExpired, legacyAcceptedUntil.Format(time.DateOnly))
+	}
+	var legacy struct {
+		ID     string `json:"id"`
+		Action string `json:"action"`
+	}
+	if err := json.Unmarshal([]byte(data), &legacy); err != nil {
+		return 0, nil, fmt.Errorf("%w: %w", core.ErrInvalidCallback, err)
+	}
+	action, ok := legacyActions[legacy.Action]
+	if !ok {
+		return 0, nil, fmt.Errorf("%w: unknown legacy action %q", core.ErrInvalidCallback, legacy.Action)
+	}
+	id, err := uuid.Parse(legacy.ID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("%w: %w", core.ErrInvalidCallback, err)
+	}
+	return action, id[:], nil
+}
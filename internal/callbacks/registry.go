@@ -0,0 +1,103 @@
+package callbacks
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/router"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+var ErrNoData = errors.New("no callback data in context")
+
+type dataKey struct{}
+
+type route struct {
+	decode      func(payload []byte) (any, error)
+	handler     router.HandlerFunc
+	middlewares []router.Middleware
+}
+
+// Registry routes callback queries by action. The data is verified and decoded once per update,
+// handlers take the typed payload with DataFromContext.
+type Registry struct {
+	codec  *Codec
+	routes map[Action]route
+}
+
+func NewRegistry(codec *Codec) *Registry {
+	return &Registry{codec: codec, routes: make(map[Action]route)}
+}
+
+// Register binds the action to the payload decoder and the handler. The middlewares run after the payload is decoded.
+// It panics if the action is already registered, as two handlers for one button are a programming error.
+func Register[T any](
+	r *Registry,
+	action Action,
+	decode func(payload []byte) (T, error),
+	handler router.HandlerFunc,
+	middlewares ...router.Middleware,
+) {
+	if _, ok := r.routes[action]; ok {
+		panic(fmt.Sprintf("callback action %s is already registered", action))
+	}
+	r.routes[action] = route{
+		decode: func(payload []byte) (any, error) {
+			return decode(payload)
+		},
+		handler:     handler,
+		middlewares: middlewares,
+	}
+}
+
+// Encode signs the payload for a button of the action.
+func (r *Registry) Encode(action Action, payload []byte) (string, error) {
+	return r.codec.Encode(action, payload)
+}
+
+// Match matches every callback query, the registry handles unknown actions itself.
+func (r *Registry) Match(_ context.Context, update *models.Update) bool {
+	return update.CallbackQuery != nil
+}
+
+// Handle decodes the callback data and runs the handler of its action.
+func (r *Registry) Handle(ctx context.Context, b *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+	action, payload, err := r.codec.Decode(update.CallbackQuery.Data)
+	if err != nil {
+		return state, nil, fmt.Errorf("failed to decode callback data: %w", err)
+	}
+	ctx = logger.WithLogValue(ctx, logger.CallbackActionField, action.String())
+
+	route, ok := r.routes[action]
+	if !ok {
+		return state, nil, fmt.Errorf("%w: unknown action %s", core.ErrInvalidCallback, action)
+	}
+	data, err := route.decode(payload)
+	if err != nil {
+		return state, nil, fmt.Errorf("%w: failed to decode payload: %w", core.ErrInvalidCallback, err)
+	}
+	slog.DebugContext(ctx, "Callback data decoded")
+
+	return router.Chain(route.handler, route.middlewares...)(WithData(ctx, data), b, update, state)
+}
+
+// WithData stores the decoded callback payload in the context.
+func WithData(ctx context.Context, data any) context.Context {
+	return context.WithValue(ctx, dataKey{}, data)
+}
+
+// DataFromContext returns the decoded callback payload of the update.
+func DataFromContext[T any](ctx context.Context) (T, error) {
+	data, ok := ctx.Value(dataKey{}).(T)
+	if !ok {
+		var zero T
+		return zero, ErrNoData
+	}
+	return data, nil
+}
@@ -0,0 +1,96 @@
+package callbacks
+
+import (
+	"context"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/router"
+
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func callbackUpdate(data string) *models.Update {
+	return &models.Update{CallbackQuery: &models.CallbackQuery{ID: "1", Data: data}}
+}
+
+func TestRegistry_Handle(t *testing.T) {
+	codec := NewCodec([]byte("secret"), time.Hour)
+	registry := NewRegistry(codec)
+
+	var handled []string
+	var got WLRequestData
+	handler := func(name string) router.HandlerFunc {
+		return func(ctx context.Context, _ *bot.Bot, _ *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+			handled = append(handled, name)
+			data, err := DataFromContext[WLRequestData](ctx)
+			got = data
+			return state, nil, err
+		}
+	}
+	middleware := func(next router.HandlerFunc) router.HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+			handled = append(handled, "middleware")
+			return next(ctx, b, update, state)
+		}
+	}
+	Register(registry, ActionWLRequestApprove, DecodeWLRequestData, handler("approve"), middleware)
+	Register(registry, ActionWLRequestDecline, DecodeWLRequestData, handler("decline"))
+
+	id := domainWLRequest.ID(uuid.New())
+	data, err := registry.Encode(ActionWLRequestApprove, NewWLRequestData(id).Bytes())
+	require.NoError(t, err)
+
+	_, _, err = registry.Handle(context.Background(), nil, callbackUpdate(data), fsm.StateIdle)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"middleware", "approve"}, handled)
+	assert.Equal(t, id, got.ID)
+}
+
+func TestRegistry_Handle_Errors(t *testing.T) {
+	codec := NewCodec([]byte("secret"), time.Hour)
+	registry := NewRegistry(codec)
+	Register(registry, ActionWLRequestApprove, DecodeWLRequestData,
+		func(ctx context.Context, _ *bot.Bot, _ *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+			t.Fatal("handler must not run")
+			return state, nil, nil
+		},
+	)
+
+	unknown, err := codec.Encode(ActionWLRequestDecline, make([]byte, 16))
+	require.NoError(t, err)
+	badPayload, err := codec.Encode(ActionWLRequestApprove, []byte{1, 2, 3})
+	require.NoError(t, err)
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "tampered", data: "garbage"},
+		{name: "unknown action", data: unknown},
+		{name: "bad payload", data: badPayload},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := registry.Handle(context.Background(), nil, callbackUpdate(tt.data), fsm.StateIdle)
+			require.ErrorIs(t, err, core.ErrInvalidCallback)
+		})
+	}
+}
+
+func TestRegister_Duplicate(t *testing.T) {
+	registry := NewRegistry(NewCodec([]byte("secret"), time.Hour))
+	Register(registry, ActionWLRequestApprove, DecodeWLRequestData, nil)
+
+	assert.Panics(t, func() {
+		Register(registry, ActionWLRequestApprove, DecodeWLRequestData, nil)
+	})
+}
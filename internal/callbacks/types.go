@@ -1,5 +1,22 @@
 package callbacks
 
-type Data interface {
-	Action() string
+import "fmt"
+
+// Action identifies what a button does. Codes are stored in buttons already sent, so they must never be reused.
+type Action byte
+
+const (
+	ActionWLRequestApprove Action = 1
+	ActionWLRequestDecline Action = 2
+)
+
+func (a Action) String() string {
+	switch a {
+	case ActionWLRequestApprove:
+		return "wl_request_approve"
+	case ActionWLRequestDecline:
+		return "wl_request_decline"
+	default:
+		return fmt.Sprintf("unknown(%d)", byte(a))
+	}
 }
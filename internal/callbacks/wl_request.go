@@ -1,89 +1,30 @@
 package callbacks
 
 import (
-	"context"
-	"encoding/json"
-	"log/slog"
-	"whitelist-bot/internal/core"
-	"whitelist-bot/internal/core/logger"
-	"whitelist-bot/internal/core/utils"
+	"fmt"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
-)
-
-type WLRequestCallbackData struct {
-	id     domainWLRequest.ID
-	action string
-}
-
-func (c WLRequestCallbackData) Action() string {
-	return c.action
-}
-
-func (c WLRequestCallbackData) IsApprove() bool {
-	return c.action == core.ActionWLRequestApprove
-}
-
-func (c WLRequestCallbackData) IsDecline() bool {
-	return c.action == core.ActionWLRequestDecline
-}
-
-func (c WLRequestCallbackData) ID() domainWLRequest.ID {
-	return c.id
-}
 
-func (c WLRequestCallbackData) MarshalJSON() ([]byte, error) {
-	aux := struct {
-		ID     string `json:"id"`
-		Action string `json:"action"`
-	}{
-		ID:     c.id.String(),
-		Action: c.action,
-	}
+	"github.com/google/uuid"
+)
 
-	return json.Marshal(aux)
+// WLRequestData is the payload of the approve and decline buttons: the raw 16 bytes of the request ID.
+type WLRequestData struct {
+	ID domainWLRequest.ID
 }
 
-func (c *WLRequestCallbackData) UnmarshalJSON(data []byte) error {
-	var aux struct {
-		ID     string `json:"id"`
-		Action string `json:"action"`
-	}
-	if err := json.Unmarshal(data, &aux); err != nil {
-		return err
-	}
-	id, err := utils.UUIDFromString[domainWLRequest.ID](aux.ID)
-	if err != nil {
-		return err
-	}
-	c.id = id
-	c.action = aux.Action
-	return nil
+func NewWLRequestData(id domainWLRequest.ID) WLRequestData {
+	return WLRequestData{ID: id}
 }
 
-func NewWLRequestCallbackData(id domainWLRequest.ID, action string) WLRequestCallbackData {
-	return WLRequestCallbackData{
-		id:     id,
-		action: action,
-	}
-}
-
-func ApproveWLRequestData(ctx context.Context, id domainWLRequest.ID) string {
-	json, err := json.Marshal(NewWLRequestCallbackData(id, core.ActionWLRequestApprove))
-	if err != nil {
-		slog.ErrorContext(ctx, "Failed to marshal approve WL request data", logger.ErrorField, err.Error())
-		// TODO: return specific callback data error for future handling.
-		return ""
-	}
-	slog.DebugContext(ctx, "Approve WL request data marshalled", "data", string(json))
-	return string(json)
+func (d WLRequestData) Bytes() []byte {
+	id := uuid.UUID(d.ID)
+	return id[:]
 }
 
-func DeclineWLRequestData(ctx context.Context, id domainWLRequest.ID) string {
-	json, err := json.Marshal(NewWLRequestCallbackData(id, core.ActionWLRequestDecline))
+func DecodeWLRequestData(payload []byte) (WLRequestData, error) {
+	id, err := uuid.FromBytes(payload)
 	if err != nil {
-		slog.ErrorContext(ctx, "Failed to marshal decline WL request data", logger.ErrorField, err.Error())
-		return ""
+		return WLRequestData{}, fmt.Errorf("failed to parse wl request ID: %w", err)
 	}
-	slog.DebugContext(ctx, "Decline WL request data marshalled", "data", string(json))
-	return string(json)
+	return WLRequestData{ID: domainWLRequest.ID(id)}, nil
 }
@@ -1,13 +1,25 @@
 package core
 
+import "whitelist-bot/internal/i18n"
+
+const (
+	CommandStart       = "start"
+	CommandCancel      = "cancel"
+	CommandLanguage    = "language"
+	CommandDeadLetters = "dead_letters"
+	CommandJobs        = "jobs"
+	CommandApprove     = "approve"
+	CommandDecline     = "decline"
+	CommandRevoke      = "revoke"
+	CommandShow        = "show"
+	CommandRules       = "rules"
+)
+
+// Reply keyboard commands are matched against their translations in every language.
 const (
-	CommandStart                 = "start"
-	CommandCancel                = "cancel"
-	CommandInfo                  = "Информация"
-	CommandNewWLRequest          = "Новая заявка"
-	CommandViewPendingWLRequests = "Посмотреть заявки"
-	CommandApproveWLRequest      = "Подтвердить"
-	CommandDeclineWLRequest      = "Отклонить"
-	ActionWLRequestApprove       = "wlapp"
-	ActionWLRequestDecline       = "wldec"
+	CommandInfo                  = i18n.ButtonInfo
+	CommandNewWLRequest          = i18n.ButtonNewWLRequest
+	CommandViewPendingWLRequests = i18n.ButtonViewPendingWLRequests
+	CommandApproveWLRequest      = i18n.ButtonApproveWLRequest
+	CommandDeclineWLRequest      = i18n.ButtonDeclineWLRequest
 )
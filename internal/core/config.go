@@ -1,7 +1,9 @@
 package core
 
 import (
+	"crypto/sha256"
 	"fmt"
+	"time"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/ilyakaznacheev/cleanenv"
@@ -11,12 +13,23 @@ import (
 type TelegramToken string
 
 type Config struct {
-	Logs     LogsConfig     `env-prefix:"LOGS_"`
-	Sqlite   SqliteConfig   `env-prefix:"SQLITE_"`
-	Postgres PostgresConfig `env-prefix:"POSTGRES_"`
-	Telegram TelegramConfig `env-prefix:"TELEGRAM_"`
-	Server   ServerConfig   `env-prefix:"SERVER_"`
-	Nats     NatsConfig     `env-prefix:"NATS_"`
+	Logs      LogsConfig      `env-prefix:"LOGS_"`
+	Sqlite    SqliteConfig    `env-prefix:"SQLITE_"`
+	Postgres  PostgresConfig  `env-prefix:"POSTGRES_"`
+	Telegram  TelegramConfig  `env-prefix:"TELEGRAM_"`
+	Server    ServerConfig    `env-prefix:"SERVER_"`
+	Nats      NatsConfig      `env-prefix:"NATS_"`
+	Templates TemplatesConfig `env-prefix:"TEMPLATES_"`
+	FSM       FSMConfig       `env-prefix:"FSM_"`
+	Locker    LockerConfig    `env-prefix:"LOCKER_"`
+	EventBus  EventBusConfig  `env-prefix:"EVENTBUS_"`
+	Outbox    OutboxConfig    `env-prefix:"OUTBOX_"`
+	Scheduler SchedulerConfig `env-prefix:"SCHEDULER_"`
+	WLRequest WLRequestConfig `env-prefix:"WL_REQUEST_"`
+	RateLimit RateLimitConfig `env-prefix:"RATELIMIT_"`
+	Sender    SenderConfig    `env-prefix:"SENDER_"`
+	Cache     CacheConfig     `env-prefix:"CACHE_"`
+	Callback  CallbackConfig  `env-prefix:"CALLBACK_"`
 }
 
 type LogsConfig struct {
@@ -37,11 +50,29 @@ type PostgresConfig struct {
 type TelegramConfig struct {
 	Token    TelegramToken `env:"TOKEN"     validate:"required"`
 	AdminIDs []int64       `env:"ADMIN_IDS" validate:"required,min=1"`
-	Debug    bool          `env:"DEBUG"                               env-default:"false"`
+	// OnDutyIDs get reminders about unreviewed requests. Empty means all admins.
+	OnDutyIDs []int64 `env:"ON_DUTY_IDS"`
+	// OwnerIDs get escalations about requests that stay unreviewed. Empty disables escalation.
+	OwnerIDs []int64 `env:"OWNER_IDS"`
+	Debug    bool    `env:"DEBUG"       env-default:"false"`
+	// ProfileSyncInterval is the minimum time between two saves of a user's name and username changes.
+	ProfileSyncInterval time.Duration `env:"PROFILE_SYNC_INTERVAL" env-default:"1m" validate:"min=0"`
+}
+
+// OnDutyAdminIDs returns the admins that get reminders.
+func (c TelegramConfig) OnDutyAdminIDs() []int64 {
+	if len(c.OnDutyIDs) == 0 {
+		return c.AdminIDs
+	}
+	return c.OnDutyIDs
 }
 
 type ServerConfig struct {
 	MaxRequestsPerUser int `env:"MAX_REQUESTS_PER_USER" env-default:"3" validate:"min=1"`
+	// Maintenance rejects updates from everyone but admins.
+	Maintenance bool `env:"MAINTENANCE" env-default:"false"`
+	// Address is the game server address, available to the message templates as .Server.Address.
+	Address string `env:"ADDRESS"`
 }
 
 type NatsConfig struct {
@@ -51,6 +82,109 @@ type NatsConfig struct {
 	NKeyPublic        string `env:"NKEY_PUBLIC"                                       validate:"required"`
 }
 
+type TemplatesConfig struct {
+	Dir            string        `env:"DIR"`
+	ReloadInterval time.Duration `env:"RELOAD_INTERVAL" env-default:"30s" validate:"min=1s"`
+}
+
+type FSMConfig struct {
+	Backend              string        `env:"BACKEND"                 env-default:"nats" validate:"oneof=memory nats postgres"`
+	WaitingWLNicknameTTL time.Duration `env:"WAITING_WL_NICKNAME_TTL" env-default:"1h"   validate:"min=1m"`
+}
+
+type LockerConfig struct {
+	Backend     string        `env:"BACKEND"      env-default:"nats" validate:"oneof=memory nats"`
+	LeaseTTL    time.Duration `env:"LEASE_TTL"    env-default:"30s"  validate:"min=3s"`
+	WaitTimeout time.Duration `env:"WAIT_TIMEOUT" env-default:"5s"   validate:"min=100ms"`
+	HoldTimeout time.Duration `env:"HOLD_TIMEOUT" env-default:"2m"   validate:"min=1s"`
+}
+
+type EventBusConfig struct {
+	Backend        string `env:"BACKEND"         env-default:"nats"        validate:"oneof=memory nats"`
+	BufferCapacity int    `env:"BUFFER_CAPACITY" env-default:"10"          validate:"min=1"`
+	Overflow       string `env:"OVERFLOW"        env-default:"drop-oldest" validate:"oneof=drop-oldest drop-newest block error"`
+	// TopicCapacity and TopicOverflow override the buffer per topic, e.g. "wl-request.created:100".
+	TopicCapacity map[string]int    `env:"TOPIC_CAPACITY" validate:"dive,min=1"`
+	TopicOverflow map[string]string `env:"TOPIC_OVERFLOW" validate:"dive,oneof=drop-oldest drop-newest block error"`
+}
+
+type OutboxConfig struct {
+	PollInterval time.Duration `env:"POLL_INTERVAL" env-default:"1s"  validate:"min=100ms"`
+	BatchSize    int64         `env:"BATCH_SIZE"    env-default:"100" validate:"min=1"`
+	LeaseTTL     time.Duration `env:"LEASE_TTL"     env-default:"30s" validate:"min=1s"`
+	Retention    time.Duration `env:"RETENTION"     env-default:"24h" validate:"min=1m"`
+	// CleanupSchedule is a cron expression for deleting delivered messages.
+	CleanupSchedule string `env:"CLEANUP_SCHEDULE" env-default:"@hourly" validate:"required"`
+}
+
+type SchedulerConfig struct {
+	// Timezone is used to evaluate cron expressions.
+	Timezone   string        `env:"TIMEZONE"    env-default:"UTC" validate:"required"`
+	JobTimeout time.Duration `env:"JOB_TIMEOUT" env-default:"5m"  validate:"min=1s"`
+	Jitter     time.Duration `env:"JITTER"      env-default:"30s" validate:"min=0"`
+}
+
+type WLRequestConfig struct {
+	// MaxPendingAge is how long a request may wait for review before it expires.
+	MaxPendingAge       time.Duration `env:"MAX_PENDING_AGE"       env-default:"168h"         validate:"min=1h"`
+	ExpirationSchedule  string        `env:"EXPIRATION_SCHEDULE"   env-default:"*/15 * * * *" validate:"required"`
+	ExpirationBatchSize int64         `env:"EXPIRATION_BATCH_SIZE" env-default:"100"          validate:"min=1"`
+	// RemindAfter is how long a request waits before on-duty admins are reminded, EscalateAfter before owners are.
+	RemindAfter      time.Duration `env:"REMIND_AFTER"      env-default:"6h"           validate:"min=1m"`
+	EscalateAfter    time.Duration `env:"ESCALATE_AFTER"    env-default:"24h"          validate:"gtfield=RemindAfter"`
+	ReminderSchedule string        `env:"REMINDER_SCHEDULE" env-default:"*/10 * * * *" validate:"required"`
+}
+
+// RateLimitConfig sets token buckets per user: each class allows Burst updates at once and one more every Interval.
+type RateLimitConfig struct {
+	Backend          string        `env:"BACKEND"           env-default:"memory" validate:"oneof=memory nats"`
+	MessageBurst     int           `env:"MESSAGE_BURST"     env-default:"5"      validate:"min=1"`
+	MessageInterval  time.Duration `env:"MESSAGE_INTERVAL"  env-default:"1s"     validate:"min=10ms"`
+	CallbackBurst    int           `env:"CALLBACK_BURST"    env-default:"10"     validate:"min=1"`
+	CallbackInterval time.Duration `env:"CALLBACK_INTERVAL" env-default:"500ms"  validate:"min=10ms"`
+	SubmitBurst      int           `env:"SUBMIT_BURST"      env-default:"3"      validate:"min=1"`
+	SubmitInterval   time.Duration `env:"SUBMIT_INTERVAL"   env-default:"10m"    validate:"min=1s"`
+	// WarningInterval is the minimal gap between two "slow down" replies to the same user.
+	WarningInterval time.Duration `env:"WARNING_INTERVAL" env-default:"10s" validate:"min=1s"`
+}
+
+// SenderConfig sets the budgets and retries of outgoing Telegram requests.
+type SenderConfig struct {
+	// GlobalRate is how many requests per second the bot sends at most.
+	GlobalRate int `env:"GLOBAL_RATE" env-default:"30" validate:"min=1"`
+	// ChatInterval is the minimal gap between two messages to the same chat.
+	ChatInterval time.Duration `env:"CHAT_INTERVAL" env-default:"1s"    validate:"min=10ms"`
+	MaxRetries   int           `env:"MAX_RETRIES"   env-default:"3"     validate:"min=0"`
+	BaseBackoff  time.Duration `env:"BASE_BACKOFF"  env-default:"500ms" validate:"min=10ms"`
+	MaxBackoff   time.Duration `env:"MAX_BACKOFF"   env-default:"30s"   validate:"gtefield=BaseBackoff"`
+	Workers      int           `env:"WORKERS"       env-default:"8"     validate:"min=1"`
+	ParseMode    string        `env:"PARSE_MODE"    env-default:"HTML"  validate:"oneof=HTML MarkdownV2 Markdown"`
+}
+
+type CacheConfig struct {
+	// UserTTL is how long a user read by Telegram ID is cached. Zero disables the cache.
+	// Changes made by other replicas are seen after it.
+	UserTTL time.Duration `env:"USER_TTL" env-default:"0s" validate:"min=0"`
+}
+
+type CallbackConfig struct {
+	// Secret signs inline button data. Empty derives the key from the bot token,
+	// so changing the token invalidates the buttons already sent.
+	Secret string `env:"SECRET"`
+	// TTL is how long inline buttons stay valid. Zero keeps them valid forever. It must not be shorter than
+	// WLRequestConfig.MaxPendingAge, or the buttons of a request stop working while it is still pending.
+	TTL time.Duration `env:"TTL" env-default:"168h" validate:"min=0"`
+}
+
+// Key returns the key that signs callback data.
+func (c CallbackConfig) Key(token TelegramToken) []byte {
+	if c.Secret != "" {
+		return []byte(c.Secret)
+	}
+	key := sha256.Sum256([]byte("callbacks:" + string(token)))
+	return key[:]
+}
+
 func LoadConfig() (Config, error) {
 	var cfg Config
 	err := godotenv.Load()
@@ -65,6 +199,10 @@ func LoadConfig() (Config, error) {
 	if err := validate.Struct(cfg); err != nil {
 		return Config{}, fmt.Errorf("invalid config: %w", err)
 	}
+	if cfg.Callback.TTL > 0 && cfg.Callback.TTL < cfg.WLRequest.MaxPendingAge {
+		return Config{}, fmt.Errorf("invalid config: callback TTL %s is shorter than wl request max pending age %s",
+			cfg.Callback.TTL, cfg.WLRequest.MaxPendingAge)
+	}
 
 	return cfg, nil
 }
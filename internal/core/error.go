@@ -5,11 +5,23 @@ import (
 )
 
 var (
-	ErrUserNotFound     = errors.New("user not found")
-	ErrUnknownCommand   = errors.New("unknown command")
-	ErrInvalidLength    = errors.New("invalid length")
-	ErrInvalidState     = errors.New("invalid state")
-	ErrInvalidUserState = errors.New("invalid user state")
-	ErrFailedToParseID  = errors.New("failed to parse ID")
-	ErrInvalidUpdate    = errors.New("invalid update")
+	ErrUserNotFound       = errors.New("user not found")
+	ErrUnknownCommand     = errors.New("unknown command")
+	ErrInvalidLength      = errors.New("invalid length")
+	ErrInvalidState       = errors.New("invalid state")
+	ErrInvalidUserState   = errors.New("invalid user state")
+	ErrFailedToParseID    = errors.New("failed to parse ID")
+	ErrInvalidUpdate      = errors.New("invalid update")
+	ErrUserBusy           = errors.New("user is busy")
+	ErrDeadLetterNotFound = errors.New("dead letter not found")
+	ErrTooManyRequests    = errors.New("too many requests")
+	ErrHandlerPanicked    = errors.New("handler panicked")
+	ErrPermissionDenied   = errors.New("permission denied")
+	ErrMaintenance        = errors.New("bot is under maintenance")
+	ErrInvalidCallback    = errors.New("invalid callback data")
+	ErrCallbackExpired    = errors.New("callback data expired")
+	ErrWLRequestNotFound  = errors.New("wl request not found")
+	ErrAmbiguousWLRequest = errors.New("ambiguous wl request")
+	// ErrWLRequestStatusChanged means the request left the status it was decided from before the decision was saved.
+	ErrWLRequestStatusChanged = errors.New("wl request status changed")
 )
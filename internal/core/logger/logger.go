@@ -69,6 +69,17 @@ func WithLogValue(ctx context.Context, entryKey string, value any) context.Conte
 	return context.WithValue(ctx, dataKey, &logData{data: map[string]any{entryKey: value}, mu: sync.RWMutex{}})
 }
 
+// LogValue returns the value stored in the log context under entryKey.
+func LogValue(ctx context.Context, entryKey string) (any, bool) {
+	if c, ok := ctx.Value(dataKey).(*logData); ok {
+		c.mu.RLock()
+		defer c.mu.RUnlock()
+		value, ok := c.data[entryKey]
+		return value, ok
+	}
+	return nil, false
+}
+
 func WithLogLevel(ctx context.Context, value slog.Level) context.Context {
 	return context.WithValue(ctx, levelKey, value)
 }
@@ -35,4 +35,13 @@ const (
 	ArbiterIDField       = "arbiter_id"
 	RequesterIDField     = "requester_id"
 	EventIDField         = "event_id"
+	EventTypeField       = "event_type"
+	ProducerField        = "producer"
+	TopicField           = "topic"
+	SubscriberField      = "subscriber"
+	DeliveredField       = "delivered"
+	DeadLetterIDField    = "dead_letter_id"
+	OutboxMessageIDField = "outbox_message_id"
+	JobField             = "job"
+	CallbackActionField  = "callback_action"
 )
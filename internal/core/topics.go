@@ -2,4 +2,6 @@ package core
 
 const (
 	TopicWLRequestCreated = "wl-request.created"
+	TopicWLRequestExpired = "wl-request.expired"
+	TopicDeadLetter       = "dead-letter"
 )
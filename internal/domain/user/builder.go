@@ -14,21 +14,21 @@ var (
 	ErrIDRequired         = errors.New("ID required")
 	ErrTelegramIDRequired = errors.New("telegram ID required")
 	ErrChatIDRequired     = errors.New("chat ID required")
-	ErrUsernameRequired   = errors.New("username required")
 	ErrCreatedAtRequired  = errors.New("createdAt required")
 	ErrUpdatedAtRequired  = errors.New("updatedAt required")
 )
 
 type Builder struct {
-	id         ID
-	telegramID TelegramID
-	chatID     ChatID
-	firstName  FirstName
-	lastName   LastName
-	username   Username
-	errors     []error
-	createdAt  time.Time
-	updatedAt  time.Time
+	id           ID
+	telegramID   TelegramID
+	chatID       ChatID
+	firstName    FirstName
+	lastName     LastName
+	username     Username
+	languageCode LanguageCode
+	errors       []error
+	createdAt    time.Time
+	updatedAt    time.Time
 }
 
 func NewBuilder() Builder {
@@ -113,11 +113,8 @@ func (b Builder) LastNameFromString(lastName string) Builder {
 	return b.LastName(LastName(lastName))
 }
 
+// Username is optional, users may hide it in Telegram.
 func (b Builder) Username(username Username) Builder {
-	if username.IsZero() {
-		b.errors = append(b.errors, ErrUsernameRequired)
-		return b
-	}
 	b.username = username
 	return b
 }
@@ -126,6 +123,18 @@ func (b Builder) UsernameFromString(username string) Builder {
 	return b.Username(Username(username))
 }
 
+func (b Builder) LanguageCode(languageCode LanguageCode) Builder {
+	if len(languageCode) > maxLanguageCodeLength {
+		languageCode = languageCode[:maxLanguageCodeLength]
+	}
+	b.languageCode = languageCode
+	return b
+}
+
+func (b Builder) LanguageCodeFromString(languageCode string) Builder {
+	return b.LanguageCode(LanguageCode(languageCode))
+}
+
 func (b Builder) CreatedAt(createdAt time.Time) Builder {
 	if createdAt.IsZero() {
 		b.errors = append(b.errors, ErrCreatedAtRequired)
@@ -151,9 +160,6 @@ func (b Builder) Build() (User, error) {
 	if b.telegramID.IsZero() {
 		b.errors = append(b.errors, ErrTelegramIDRequired)
 	}
-	if b.username.IsZero() {
-		b.errors = append(b.errors, ErrUsernameRequired)
-	}
 	if b.createdAt.IsZero() {
 		b.errors = append(b.errors, ErrCreatedAtRequired)
 	}
@@ -169,13 +175,14 @@ func (b Builder) Build() (User, error) {
 	}
 
 	return User{
-		id:         b.id,
-		telegramID: b.telegramID,
-		chatID:     b.chatID,
-		firstName:  b.firstName,
-		lastName:   b.lastName,
-		username:   b.username,
-		createdAt:  b.createdAt,
-		updatedAt:  b.updatedAt,
+		id:           b.id,
+		telegramID:   b.telegramID,
+		chatID:       b.chatID,
+		firstName:    b.firstName,
+		lastName:     b.lastName,
+		username:     b.username,
+		languageCode: b.languageCode,
+		createdAt:    b.createdAt,
+		updatedAt:    b.updatedAt,
 	}, nil
 }
@@ -57,6 +57,22 @@ func TestBuilder_Build_Success(t *testing.T) {
 	}
 }
 
+func TestBuilder_Build_WithoutUsername(t *testing.T) {
+	now := time.Now()
+
+	user, err := NewBuilder().
+		ID(ID(utils.NewUniqueID())).
+		TelegramID(1234567890).
+		ChatID(1234567890).
+		FirstName("John").
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+
+	assert.NoError(t, err)
+	assert.True(t, user.Username().IsZero())
+}
+
 func TestBuilder_Build_ValidationError(t *testing.T) {
 	id := ID(utils.NewUniqueID())
 	telegramID := TelegramID(1234567890)
@@ -145,21 +161,6 @@ func TestBuilder_Build_ValidationError(t *testing.T) {
 			},
 			expectedError: ErrChatIDRequired,
 		},
-		{
-			name: "Username required",
-			builder: func() Builder {
-				return NewBuilder().
-					ID(id).
-					TelegramID(telegramID).
-					ChatID(chatID).
-					FirstName(firstName).
-					LastName(lastName).
-					Username(Username("")).
-					CreatedAt(now).
-					UpdatedAt(now)
-			},
-			expectedError: ErrUsernameRequired,
-		},
 		{
 			name: "CreatedAt is zero",
 			builder: func() Builder {
@@ -226,7 +227,6 @@ func TestBuilder_Build_ValidationError(t *testing.T) {
 			expectedError: errors.Join(
 				ErrIDRequired,
 				ErrTelegramIDRequired,
-				ErrUsernameRequired,
 				ErrCreatedAtRequired,
 				ErrUpdatedAtRequired,
 			),
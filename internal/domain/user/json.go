@@ -7,36 +7,39 @@ import (
 
 func (u User) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		ID         ID         `json:"id"`
-		TelegramID TelegramID `json:"telegram_id"`
-		ChatID     ChatID     `json:"chat_id"`
-		FirstName  FirstName  `json:"first_name"`
-		LastName   LastName   `json:"last_name"`
-		Username   Username   `json:"username"`
-		CreatedAt  time.Time  `json:"created_at"`
-		UpdatedAt  time.Time  `json:"updated_at"`
+		ID           ID           `json:"id"`
+		TelegramID   TelegramID   `json:"telegram_id"`
+		ChatID       ChatID       `json:"chat_id"`
+		FirstName    FirstName    `json:"first_name"`
+		LastName     LastName     `json:"last_name"`
+		Username     Username     `json:"username"`
+		LanguageCode LanguageCode `json:"language_code"`
+		CreatedAt    time.Time    `json:"created_at"`
+		UpdatedAt    time.Time    `json:"updated_at"`
 	}{
-		ID:         u.id,
-		TelegramID: u.telegramID,
-		ChatID:     u.chatID,
-		FirstName:  u.firstName,
-		LastName:   u.lastName,
-		Username:   u.username,
-		CreatedAt:  u.createdAt,
-		UpdatedAt:  u.updatedAt,
+		ID:           u.id,
+		TelegramID:   u.telegramID,
+		ChatID:       u.chatID,
+		FirstName:    u.firstName,
+		LastName:     u.lastName,
+		Username:     u.username,
+		LanguageCode: u.languageCode,
+		CreatedAt:    u.createdAt,
+		UpdatedAt:    u.updatedAt,
 	})
 }
 
 func (u *User) UnmarshalJSON(data []byte) error {
 	var aux struct {
-		ID         ID         `json:"id"`
-		TelegramID TelegramID `json:"telegram_id"`
-		ChatID     ChatID     `json:"chat_id"`
-		FirstName  FirstName  `json:"first_name"`
-		LastName   LastName   `json:"last_name"`
-		Username   Username   `json:"username"`
-		CreatedAt  time.Time  `json:"created_at"`
-		UpdatedAt  time.Time  `json:"updated_at"`
+		ID           ID           `json:"id"`
+		TelegramID   TelegramID   `json:"telegram_id"`
+		ChatID       ChatID       `json:"chat_id"`
+		FirstName    FirstName    `json:"first_name"`
+		LastName     LastName     `json:"last_name"`
+		Username     Username     `json:"username"`
+		LanguageCode LanguageCode `json:"language_code"`
+		CreatedAt    time.Time    `json:"created_at"`
+		UpdatedAt    time.Time    `json:"updated_at"`
 	}
 	if err := json.Unmarshal(data, &aux); err != nil {
 		return err
@@ -49,6 +52,7 @@ func (u *User) UnmarshalJSON(data []byte) error {
 		FirstName(aux.FirstName).
 		LastName(aux.LastName).
 		Username(aux.Username).
+		LanguageCode(aux.LanguageCode).
 		CreatedAt(aux.CreatedAt).
 		UpdatedAt(aux.UpdatedAt).
 		Build()
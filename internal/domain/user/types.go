@@ -1,23 +1,27 @@
 package user
 
 import (
+	"database/sql/driver"
+	"fmt"
 	"whitelist-bot/internal/core/utils"
 
 	"github.com/google/uuid"
 )
 
 type (
-	ID         uuid.UUID
-	TelegramID int64
-	ChatID     int64
-	FirstName  string
-	LastName   string
-	Username   string
+	ID           uuid.UUID
+	TelegramID   int64
+	ChatID       int64
+	FirstName    string
+	LastName     string
+	Username     string
+	LanguageCode string
 )
 
 const (
-	maxFirstNameLength = 64
-	maxLastNameLength  = 64
+	maxFirstNameLength    = 64
+	maxLastNameLength     = 64
+	maxLanguageCodeLength = 16
 )
 
 func (t TelegramID) IsZero() bool {
@@ -28,6 +32,29 @@ func (u Username) IsZero() bool {
 	return u == ""
 }
 
+// Value stores a missing username as NULL, Telegram users may have none.
+func (u Username) Value() (driver.Value, error) {
+	if u.IsZero() {
+		return nil, nil
+	}
+	return string(u), nil
+}
+
+// Scan reads NULL as a missing username.
+func (u *Username) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*u = ""
+	case string:
+		*u = Username(v)
+	case []byte:
+		*u = Username(v)
+	default:
+		return fmt.Errorf("cannot scan %T into Username", src)
+	}
+	return nil
+}
+
 func (u ID) String() string {
 	return utils.UUIDString(u)
 }
@@ -39,3 +66,7 @@ func (u ID) IsZero() bool {
 func (u ChatID) IsZero() bool {
 	return u == 0
 }
+
+func (l LanguageCode) IsZero() bool {
+	return l == ""
+}
@@ -5,14 +5,15 @@ import (
 )
 
 type User struct {
-	id         ID         `json:"id"`
-	telegramID TelegramID `json:"telegram_id"`
-	chatID     ChatID     `json:"chat_id"`
-	firstName  FirstName  `json:"first_name"`
-	lastName   LastName   `json:"last_name"`
-	username   Username   `json:"username"`
-	createdAt  time.Time  `json:"created_at"`
-	updatedAt  time.Time  `json:"updated_at"`
+	id           ID           `json:"id"`
+	telegramID   TelegramID   `json:"telegram_id"`
+	chatID       ChatID       `json:"chat_id"`
+	firstName    FirstName    `json:"first_name"`
+	lastName     LastName     `json:"last_name"`
+	username     Username     `json:"username"`
+	languageCode LanguageCode `json:"language_code"`
+	createdAt    time.Time    `json:"created_at"`
+	updatedAt    time.Time    `json:"updated_at"`
 }
 
 func (u User) ID() ID {
@@ -39,6 +40,10 @@ func (u User) Username() Username {
 	return u.username
 }
 
+func (u User) LanguageCode() LanguageCode {
+	return u.languageCode
+}
+
 func (u User) CreatedAt() time.Time {
 	return u.createdAt
 }
@@ -51,3 +56,19 @@ func (u User) UpdateTimestamp() User {
 	u.updatedAt = time.Now()
 	return u
 }
+
+func (u User) ChangeLanguageCode(languageCode LanguageCode) User {
+	u.languageCode = languageCode
+	return u.UpdateTimestamp()
+}
+
+// SyncProfile applies the profile Telegram reports for the user and reports whether it differs from the stored one.
+func (u User) SyncProfile(firstName FirstName, lastName LastName, username Username) (User, bool) {
+	if u.firstName == firstName && u.lastName == lastName && u.username == username {
+		return u, false
+	}
+	u.firstName = firstName
+	u.lastName = lastName
+	u.username = username
+	return u, true
+}
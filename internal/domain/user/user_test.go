@@ -0,0 +1,51 @@
+package user
+
+import (
+	"testing"
+	"time"
+	"whitelist-bot/internal/core/utils"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUser_SyncProfile(t *testing.T) {
+	now := time.Now()
+	user, err := NewBuilder().
+		ID(ID(utils.NewUniqueID())).
+		TelegramID(1).
+		ChatID(1).
+		FirstName("John").
+		LastName("Doe").
+		Username("john").
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name         string
+		firstName    FirstName
+		lastName     LastName
+		username     Username
+		wantChanged  bool
+		wantUsername Username
+	}{
+		{name: "same profile", firstName: "John", lastName: "Doe", username: "john", wantUsername: "john"},
+		{name: "username changed", firstName: "John", lastName: "Doe", username: "johnny", wantChanged: true, wantUsername: "johnny"},
+		{name: "name changed", firstName: "Jack", lastName: "Doe", username: "john", wantChanged: true, wantUsername: "john"},
+		{name: "username hidden", firstName: "John", lastName: "Doe", wantChanged: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			synced, changed := user.SyncProfile(tt.firstName, tt.lastName, tt.username)
+
+			assert.Equal(t, tt.wantChanged, changed)
+			assert.Equal(t, tt.firstName, synced.FirstName())
+			assert.Equal(t, tt.lastName, synced.LastName())
+			assert.Equal(t, tt.wantUsername, synced.Username())
+			assert.Equal(t, user.ID(), synced.ID())
+		})
+	}
+}
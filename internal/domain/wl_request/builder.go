@@ -143,7 +143,9 @@ func (b Builder) Status(status Status) Builder {
 	}
 	if status != StatusPending &&
 		status != StatusApproved &&
-		status != StatusDeclined {
+		status != StatusDeclined &&
+		status != StatusExpired &&
+		status != StatusRevoked {
 		b.errors = append(b.errors, fmt.Errorf("%w: %s", ErrInvalidStatus, status))
 		return b
 	}
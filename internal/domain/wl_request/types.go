@@ -34,8 +34,15 @@ const (
 	StatusPending  Status = "pending"
 	StatusApproved Status = "approved"
 	StatusDeclined Status = "declined"
+	// StatusExpired is set by the system when a request stays pending for too long.
+	StatusExpired Status = "expired"
+	// StatusRevoked is set when an admin takes back an approval.
+	StatusRevoked Status = "revoked"
 )
 
+// SystemArbiterID marks requests resolved by the bot itself rather than by an admin.
+var SystemArbiterID = ArbiterID(uuid.Max)
+
 type (
 	ID            uuid.UUID
 	RequesterID   uuid.UUID
@@ -82,6 +89,10 @@ func (u ArbiterID) IsZero() bool {
 	return utils.UUIDIsZero(u)
 }
 
+func (u ArbiterID) IsSystem() bool {
+	return u == SystemArbiterID
+}
+
 func (u Nickname) IsZero() bool {
 	return u == ""
 }
@@ -9,6 +9,8 @@ import (
 var (
 	ErrCantApproveNonPendingWLRequest = errors.New("cant approve wl request that is not pending")
 	ErrCantDeclineNonPendingWLRequest = errors.New("cant decline wl request that is not pending")
+	ErrCantExpireNonPendingWLRequest  = errors.New("cant expire wl request that is not pending")
+	ErrCantRevokeNonApprovedWLRequest = errors.New("cant revoke wl request that is not approved")
 )
 
 type WLRequest struct {
@@ -102,3 +104,44 @@ func (w WLRequest) Decline(arbiterID ArbiterID, declineReason DeclineReason) (WL
 	}
 	return newWLRequest, nil
 }
+
+// Expire closes a request that nobody reviewed in time. It is recorded with the system arbiter.
+func (w WLRequest) Expire() (WLRequest, error) {
+	if !w.IsPending() {
+		return WLRequest{}, ErrCantExpireNonPendingWLRequest
+	}
+	newWLRequest, err := NewBuilder().
+		ID(w.ID()).
+		RequesterID(w.RequesterID()).
+		Nickname(w.Nickname()).
+		Status(StatusExpired).
+		ArbiterID(SystemArbiterID).
+		CreatedAt(w.CreatedAt()).
+		UpdatedAt(w.UpdatedAt()).
+		Build()
+	if err != nil {
+		return WLRequest{}, fmt.Errorf("failed to expire wl request: %w", err)
+	}
+	return newWLRequest, nil
+}
+
+// Revoke takes back an approval, e.g. when the player was whitelisted by mistake.
+func (w WLRequest) Revoke(arbiterID ArbiterID) (WLRequest, error) {
+	if w.status != StatusApproved {
+		return WLRequest{}, ErrCantRevokeNonApprovedWLRequest
+	}
+	newWLRequest, err := NewBuilder().
+		ID(w.ID()).
+		RequesterID(w.RequesterID()).
+		Nickname(w.Nickname()).
+		Status(StatusRevoked).
+		DeclineReason(w.DeclineReason()).
+		ArbiterID(arbiterID).
+		CreatedAt(w.CreatedAt()).
+		UpdatedAt(w.UpdatedAt()).
+		Build()
+	if err != nil {
+		return WLRequest{}, fmt.Errorf("failed to revoke wl request: %w", err)
+	}
+	return newWLRequest, nil
+}
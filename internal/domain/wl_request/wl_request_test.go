@@ -0,0 +1,72 @@
+package wl_request
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWLRequest_Expire(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	pending, err := NewBuilder().
+		NewID().
+		RequesterID(NewRequesterID()).
+		Nickname("PlayerNick").
+		Status(StatusPending).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+
+	expired, err := pending.Expire()
+	require.NoError(t, err)
+	assert.Equal(t, StatusExpired, expired.Status())
+	assert.True(t, expired.ArbiterID().IsSystem())
+	assert.Equal(t, pending.ID(), expired.ID())
+	assert.Equal(t, pending.Nickname(), expired.Nickname())
+	assert.Equal(t, pending.CreatedAt(), expired.CreatedAt())
+
+	_, err = expired.Expire()
+	require.ErrorIs(t, err, ErrCantExpireNonPendingWLRequest)
+
+	approved, err := pending.Approve(NewArbiterID())
+	require.NoError(t, err)
+	_, err = approved.Expire()
+	require.ErrorIs(t, err, ErrCantExpireNonPendingWLRequest)
+}
+
+func TestWLRequest_Revoke(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	pending, err := NewBuilder().
+		NewID().
+		RequesterID(NewRequesterID()).
+		Nickname("PlayerNick").
+		Status(StatusPending).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+
+	_, err = pending.Revoke(NewArbiterID())
+	require.ErrorIs(t, err, ErrCantRevokeNonApprovedWLRequest)
+
+	approved, err := pending.Approve(NewArbiterID())
+	require.NoError(t, err)
+
+	arbiterID := NewArbiterID()
+	revoked, err := approved.Revoke(arbiterID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusRevoked, revoked.Status())
+	assert.Equal(t, arbiterID, revoked.ArbiterID())
+	assert.Equal(t, approved.ID(), revoked.ID())
+	assert.Equal(t, approved.Nickname(), revoked.Nickname())
+
+	_, err = revoked.Revoke(arbiterID)
+	require.ErrorIs(t, err, ErrCantRevokeNonApprovedWLRequest)
+}
@@ -4,14 +4,26 @@ import (
 	"context"
 	"log/slog"
 	"sync"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
 	"whitelist-bot/internal/wp"
 )
 
+const (
+	defaultInProgressInterval = 10 * time.Second
+)
+
 type ConsumerUnitHandler func(ctx context.Context, data []byte) error
 
 type ConsumerUnit struct {
-	Topic   string // TODO: add topic type with validation
-	Handler ConsumerUnitHandler
+	Topic string // TODO: add topic type with validation
+	// Subscriber names the subscription, so several units can react to the same topic independently.
+	Subscriber string
+	Handler    ConsumerUnitHandler
+	// Retry is the redelivery policy for failed events. Zero fields fall back to DefaultRetryPolicy.
+	Retry RetryPolicy
 }
 
 type ConsumerPool struct {
@@ -20,19 +32,23 @@ type ConsumerPool struct {
 	sem         wp.ISemaphore
 	wgConsumers sync.WaitGroup
 	wgHandlers  sync.WaitGroup
+
+	inProgressInterval time.Duration
 }
 
 func NewConsumerPool(eBus EventBus, units []ConsumerUnit, sem wp.ISemaphore) *ConsumerPool {
 	return &ConsumerPool{
-		eBus:  eBus,
-		units: units,
-		sem:   sem,
+		eBus:               eBus,
+		units:              units,
+		sem:                sem,
+		inProgressInterval: defaultInProgressInterval,
 	}
 }
 
 func (p *ConsumerPool) Start(ctx context.Context) error {
 	for _, unit := range p.units {
-		consumer, err := p.eBus.NewConsumer(unit.Topic)
+		unit.Retry = unit.Retry.orDefault()
+		consumer, err := p.eBus.NewConsumer(ctx, unit.Topic, unit.Subscriber)
 		if err != nil {
 			slog.Error("Failed to get consumer", "error", err.Error())
 			return err
@@ -42,31 +58,123 @@ func (p *ConsumerPool) Start(ctx context.Context) error {
 		go func(u ConsumerUnit) {
 			defer p.wgConsumers.Done()
 			for {
-				data, closed := consumer.Consume(ctx)
-				if !closed {
+				msg, ok := consumer.Consume(ctx)
+				if !ok {
 					slog.DebugContext(ctx, "Event bus consumer closed")
 					return
 				}
 				if err := p.sem.Acquire(ctx); err != nil {
 					slog.DebugContext(ctx, "Semaphore closed", "error", err.Error())
+					if err := msg.Nack(context.WithoutCancel(ctx), 0); err != nil {
+						slog.WarnContext(ctx, "Failed to nack event", logger.ErrorField, err.Error())
+					}
 					return
 				}
 
 				p.wgHandlers.Add(1)
-				go func(d []byte) {
+				go func(m IEventMessage) {
 					defer p.wgHandlers.Done()
 					defer p.sem.Release()
-					if err := u.Handler(ctx, d); err != nil {
-						slog.ErrorContext(ctx, "Failed to handle event", "error", err.Error())
-						return
-					}
-				}(data)
+					p.handle(ctx, u, m)
+				}(msg)
 			}
 		}(unit)
 	}
 	return nil
 }
 
+// handle runs the unit handler and acks the message only after the handler succeeds.
+// Failed messages are redelivered with backoff until the retry policy is exhausted,
+// then they are moved to the dead-letter topic.
+func (p *ConsumerPool) handle(ctx context.Context, u ConsumerUnit, msg IEventMessage) {
+	if envelope, err := DecodeEnvelope(msg.Data()); err == nil {
+		ctx = envelope.WithLogContext(ctx)
+	}
+	ctx = logger.WithLogValue(ctx, logger.TopicField, u.Topic)
+	ctx = logger.WithLogValue(ctx, logger.SubscriberField, u.Subscriber)
+	ctx = logger.WithLogValue(ctx, logger.DeliveredField, msg.Delivered())
+	// Acks must reach the bus even if the pool is being stopped.
+	ackCtx := context.WithoutCancel(ctx)
+
+	stopInProgress := p.keepInProgress(ctx, msg)
+	err := u.Handler(ctx, msg.Data())
+	stopInProgress()
+
+	if err == nil {
+		if err := msg.Ack(ackCtx); err != nil {
+			slog.ErrorContext(ctx, "Failed to ack event", logger.ErrorField, err.Error())
+		}
+		return
+	}
+
+	if msg.Delivered() < u.Retry.MaxAttempts {
+		backoff := u.Retry.Backoff(msg.Delivered())
+		slog.WarnContext(ctx, "Failed to handle event, will be retried",
+			logger.ErrorField, err.Error(),
+			logger.DurationField, backoff.String(),
+		)
+		if err := msg.Nack(ackCtx, backoff); err != nil {
+			slog.ErrorContext(ctx, "Failed to nack event", logger.ErrorField, err.Error())
+		}
+		return
+	}
+
+	slog.ErrorContext(ctx, "Failed to handle event, retries exhausted", logger.ErrorField, err.Error())
+	if u.Topic == core.TopicDeadLetter {
+		if err := msg.Ack(ackCtx); err != nil {
+			slog.ErrorContext(ctx, "Failed to ack event", logger.ErrorField, err.Error())
+		}
+		return
+	}
+
+	deadLetter := DeadLetter{
+		ID:       utils.NewUniqueID(),
+		Topic:    u.Topic,
+		Data:     msg.Data(),
+		Error:    err.Error(),
+		Attempts: msg.Delivered(),
+		FailedAt: time.Now(),
+	}
+	if err := Publish(ackCtx, p.eBus, DeadLetterTopic, deadLetter); err != nil {
+		slog.ErrorContext(ctx, "Failed to publish dead letter, will be retried", logger.ErrorField, err.Error())
+		if err := msg.Nack(ackCtx, u.Retry.MaxBackoff); err != nil {
+			slog.ErrorContext(ctx, "Failed to nack event", logger.ErrorField, err.Error())
+		}
+		return
+	}
+	slog.WarnContext(ctx, "Event moved to dead-letter topic", logger.DeadLetterIDField, deadLetter.ID.String())
+	if err := msg.Ack(ackCtx); err != nil {
+		slog.ErrorContext(ctx, "Failed to ack event", logger.ErrorField, err.Error())
+	}
+}
+
+// keepInProgress periodically marks the message as in progress until the returned func is called.
+func (p *ConsumerPool) keepInProgress(ctx context.Context, msg IEventMessage) func() {
+	done := make(chan struct{})
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+
+		ticker := time.NewTicker(p.inProgressInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-done:
+				return
+			case <-ticker.C:
+				if err := msg.InProgress(ctx); err != nil {
+					slog.WarnContext(ctx, "Failed to mark event in progress", logger.ErrorField, err.Error())
+				}
+			}
+		}
+	}()
+	return func() {
+		close(done)
+		<-stopped
+	}
+}
+
 func (p *ConsumerPool) Wait() {
 	p.wgConsumers.Wait()
 	p.wgHandlers.Wait()
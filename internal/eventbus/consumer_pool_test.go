@@ -0,0 +1,200 @@
+package eventbus
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/wp"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeMessage struct {
+	data      []byte
+	delivered int
+
+	mu     sync.Mutex
+	acked  bool
+	nacked bool
+	done   chan struct{}
+}
+
+func newFakeMessage(data string, delivered int) *fakeMessage {
+	return &fakeMessage{data: []byte(data), delivered: delivered, done: make(chan struct{})}
+}
+
+func (m *fakeMessage) Data() []byte                       { return m.data }
+func (m *fakeMessage) Delivered() int                     { return m.delivered }
+func (m *fakeMessage) InProgress(_ context.Context) error { return nil }
+
+func (m *fakeMessage) Ack(_ context.Context) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.acked = true
+	close(m.done)
+	return nil
+}
+
+func (m *fakeMessage) Nack(_ context.Context, _ time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.nacked = true
+	close(m.done)
+	return nil
+}
+
+type fakeConsumer struct {
+	messages chan IEventMessage
+}
+
+func (c *fakeConsumer) Consume(ctx context.Context) (IEventMessage, bool) {
+	select {
+	case <-ctx.Done():
+		return nil, false
+	case msg := <-c.messages:
+		return msg, true
+	}
+}
+
+type fakeBus struct {
+	consumer   *fakeConsumer
+	publishErr error
+
+	mu        sync.Mutex
+	published map[string][]any
+}
+
+func (b *fakeBus) Publish(_ context.Context, topic string, data any) error {
+	if b.publishErr != nil {
+		return b.publishErr
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.published == nil {
+		b.published = make(map[string][]any)
+	}
+	b.published[topic] = append(b.published[topic], data)
+	return nil
+}
+
+func (b *fakeBus) Close() error { return nil }
+func (b *fakeBus) NewConsumer(_ context.Context, _, _ string) (IEventConsumer, error) {
+	return b.consumer, nil
+}
+
+func TestConsumerPool_AckNack(t *testing.T) {
+	tests := []struct {
+		name           string
+		topic          string
+		delivered      int
+		handlerErr     error
+		publishErr     error
+		wantAcked      bool
+		wantNacked     bool
+		wantDeadLetter bool
+	}{
+		{name: "success_acks", delivered: 1, wantAcked: true},
+		{name: "failure_nacks", delivered: 1, handlerErr: errors.New("telegram is down"), wantNacked: true},
+		{
+			name:           "retries_exhausted_moves_to_dead_letter",
+			delivered:      DefaultRetryPolicy.MaxAttempts,
+			handlerErr:     errors.New("telegram is down"),
+			wantAcked:      true,
+			wantDeadLetter: true,
+		},
+		{
+			name:       "dead_letter_publish_failure_nacks",
+			delivered:  DefaultRetryPolicy.MaxAttempts,
+			handlerErr: errors.New("telegram is down"),
+			publishErr: errors.New("bus is down"),
+			wantNacked: true,
+		},
+		{
+			name:       "dead_letter_topic_is_not_dead_lettered",
+			topic:      core.TopicDeadLetter,
+			delivered:  DefaultRetryPolicy.MaxAttempts,
+			handlerErr: errors.New("db is down"),
+			wantAcked:  true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			bus := &fakeBus{
+				consumer:   &fakeConsumer{messages: make(chan IEventMessage, 1)},
+				publishErr: tt.publishErr,
+			}
+			topic := tt.topic
+			if topic == "" {
+				topic = "test"
+			}
+			sem, err := wp.NewSemaphore(1)
+			require.NoError(t, err)
+
+			var handled []byte
+			pool := NewConsumerPool(bus, []ConsumerUnit{{
+				Topic: topic,
+				Handler: func(ctx context.Context, data []byte) error {
+					handled = data
+					return tt.handlerErr
+				},
+			}}, sem)
+			require.NoError(t, pool.Start(ctx))
+
+			msg := newFakeMessage("event", tt.delivered)
+			bus.consumer.messages <- msg
+
+			select {
+			case <-msg.done:
+			case <-time.After(time.Second):
+				t.Fatal("message was neither acked nor nacked")
+			}
+			cancel()
+			pool.Wait()
+
+			assert.Equal(t, []byte("event"), handled)
+			assert.Equal(t, tt.wantAcked, msg.acked)
+			assert.Equal(t, tt.wantNacked, msg.nacked)
+			if !tt.wantDeadLetter {
+				assert.Empty(t, bus.published[core.TopicDeadLetter])
+				return
+			}
+			require.Len(t, bus.published[core.TopicDeadLetter], 1)
+			envelope, ok := bus.published[core.TopicDeadLetter][0].(Envelope)
+			require.True(t, ok)
+			deadLetter, err := DeadLetterTopic.Decode(envelope)
+			require.NoError(t, err)
+			assert.Equal(t, topic, deadLetter.Topic)
+			assert.Equal(t, []byte("event"), deadLetter.Data)
+			assert.Equal(t, "telegram is down", deadLetter.Error)
+			assert.Equal(t, tt.delivered, deadLetter.Attempts)
+		})
+	}
+}
+
+func TestRetryPolicy_Backoff(t *testing.T) {
+	policy := RetryPolicy{
+		MaxAttempts:    5,
+		InitialBackoff: time.Second,
+		MaxBackoff:     10 * time.Second,
+		Multiplier:     2,
+	}
+
+	assert.Equal(t, time.Second, policy.Backoff(1))
+	assert.Equal(t, 2*time.Second, policy.Backoff(2))
+	assert.Equal(t, 4*time.Second, policy.Backoff(3))
+	assert.Equal(t, 10*time.Second, policy.Backoff(5))
+
+	policy.Jitter = 0.5
+	for range 100 {
+		backoff := policy.Backoff(2)
+		assert.GreaterOrEqual(t, backoff, time.Second)
+		assert.LessOrEqual(t, backoff, 3*time.Second)
+	}
+}
@@ -0,0 +1,20 @@
+package eventbus
+
+import (
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/utils"
+)
+
+var DeadLetterTopic = NewTopic[DeadLetter](core.TopicDeadLetter, 1)
+
+// DeadLetter is an event that failed all retry attempts. It is published to DeadLetterTopic.
+// Data keeps the original event envelope, so a replay publishes it unchanged.
+type DeadLetter struct {
+	ID       utils.UniqueID `json:"id"`
+	Topic    string         `json:"topic"`
+	Data     []byte         `json:"data"`
+	Error    string         `json:"error"`
+	Attempts int            `json:"attempts"`
+	FailedAt time.Time      `json:"failed_at"`
+}
@@ -0,0 +1,171 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+)
+
+var ErrUnsupportedSchemaVersion = errors.New("unsupported event schema version")
+
+// Producer identifies this process in published envelopes.
+var Producer = defaultProducer()
+
+func defaultProducer() string {
+	hostname, err := os.Hostname()
+	if err != nil || hostname == "" {
+		return "whitelist-bot"
+	}
+	return "whitelist-bot@" + hostname
+}
+
+// Envelope is the standard wrapper of every published event.
+type Envelope struct {
+	ID            string          `json:"id"`
+	Type          string          `json:"type"`
+	SchemaVersion int             `json:"schema_version"`
+	Timestamp     time.Time       `json:"timestamp"`
+	Producer      string          `json:"producer"`
+	CorrelationID string          `json:"correlation_id,omitempty"`
+	Payload       json.RawMessage `json:"payload"`
+}
+
+// DecodeEnvelope parses an envelope. Events published before envelopes were introduced are returned
+// as an envelope with schema version 0 and the whole data as payload.
+func DecodeEnvelope(data []byte) (Envelope, error) {
+	var probe struct {
+		Type    string          `json:"type"`
+		Payload json.RawMessage `json:"payload"`
+	}
+	if err := json.Unmarshal(data, &probe); err != nil {
+		return Envelope{}, fmt.Errorf("failed to unmarshal event envelope: %w", err)
+	}
+	if probe.Type == "" || probe.Payload == nil {
+		return Envelope{Payload: data}, nil
+	}
+
+	var envelope Envelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return Envelope{}, fmt.Errorf("failed to unmarshal event envelope: %w", err)
+	}
+	return envelope, nil
+}
+
+// WithLogContext restores the log context of the producer, so one trace spans the update
+// and the handlers of its events.
+func (e Envelope) WithLogContext(ctx context.Context) context.Context {
+	if e.CorrelationID != "" {
+		ctx = logger.WithLogValue(ctx, logger.CorrelationIDField, e.CorrelationID)
+	}
+	if e.ID != "" {
+		ctx = logger.WithLogValue(ctx, logger.EventIDField, e.ID)
+	}
+	if e.Type != "" {
+		ctx = logger.WithLogValue(ctx, logger.EventTypeField, e.Type)
+	}
+	if e.Producer != "" {
+		ctx = logger.WithLogValue(ctx, logger.ProducerField, e.Producer)
+	}
+	return ctx
+}
+
+// Topic binds a topic name to its payload type and current schema version.
+type Topic[T any] struct {
+	Name          string
+	SchemaVersion int
+}
+
+func NewTopic[T any](name string, schemaVersion int) Topic[T] {
+	return Topic[T]{Name: name, SchemaVersion: schemaVersion}
+}
+
+// Envelope wraps the payload, taking the correlation ID from the log context.
+func (t Topic[T]) Envelope(ctx context.Context, payload T) (Envelope, error) {
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		return Envelope{}, fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+
+	correlationID, _ := logger.LogValue(ctx, logger.CorrelationIDField)
+	correlationIDString, _ := correlationID.(string)
+
+	return Envelope{
+		ID:            utils.NewUniqueID().String(),
+		Type:          t.Name,
+		SchemaVersion: t.SchemaVersion,
+		Timestamp:     time.Now(),
+		Producer:      Producer,
+		CorrelationID: correlationIDString,
+		Payload:       raw,
+	}, nil
+}
+
+// Decode returns the payload of the envelope. Envelopes of another type or a newer schema version are rejected.
+func (t Topic[T]) Decode(envelope Envelope) (T, error) {
+	var payload T
+	if envelope.Type != "" && envelope.Type != t.Name {
+		return payload, fmt.Errorf("unexpected event type %q, want %q", envelope.Type, t.Name)
+	}
+	if envelope.SchemaVersion > t.SchemaVersion {
+		return payload, fmt.Errorf("%w: %s v%d, supported up to v%d",
+			ErrUnsupportedSchemaVersion, t.Name, envelope.SchemaVersion, t.SchemaVersion)
+	}
+	if err := json.Unmarshal(envelope.Payload, &payload); err != nil {
+		return payload, fmt.Errorf("failed to unmarshal %s payload: %w", t.Name, err)
+	}
+	return payload, nil
+}
+
+// Publish wraps the payload into an envelope and publishes it to the topic.
+func Publish[T any](ctx context.Context, publisher IEventPublisher, topic Topic[T], payload T) error {
+	envelope, err := topic.Envelope(ctx, payload)
+	if err != nil {
+		return err
+	}
+	return publisher.Publish(ctx, topic.Name, envelope)
+}
+
+// EventHandler handles a decoded event payload.
+type EventHandler[T any] func(ctx context.Context, payload T) error
+
+// Handle decodes envelopes of the topic and passes their payloads to the handler.
+// The envelope itself is available through EnvelopeFromContext.
+func Handle[T any](topic Topic[T], handler EventHandler[T]) ConsumerUnitHandler {
+	return func(ctx context.Context, data []byte) error {
+		envelope, err := DecodeEnvelope(data)
+		if err != nil {
+			return err
+		}
+		payload, err := topic.Decode(envelope)
+		if err != nil {
+			return err
+		}
+		return handler(withEnvelope(envelope.WithLogContext(ctx), envelope), payload)
+	}
+}
+
+// Subscribe returns a consumer unit that handles typed events of the topic for the subscriber.
+func Subscribe[T any](topic Topic[T], subscriber string, handler EventHandler[T]) ConsumerUnit {
+	return ConsumerUnit{
+		Topic:      topic.Name,
+		Subscriber: subscriber,
+		Handler:    Handle(topic, handler),
+	}
+}
+
+type envelopeKey struct{}
+
+func withEnvelope(ctx context.Context, envelope Envelope) context.Context {
+	return context.WithValue(ctx, envelopeKey{}, envelope)
+}
+
+// EnvelopeFromContext returns the envelope of the event being handled.
+func EnvelopeFromContext(ctx context.Context) (Envelope, bool) {
+	envelope, ok := ctx.Value(envelopeKey{}).(Envelope)
+	return envelope, ok
+}
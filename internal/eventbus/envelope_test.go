@@ -0,0 +1,110 @@
+package eventbus
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"whitelist-bot/internal/core/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testEvent struct {
+	Name string `json:"name"`
+}
+
+var testTopic = NewTopic[testEvent]("test.created", 2)
+
+func TestPublishHandle_RoundTrip(t *testing.T) {
+	t.Parallel()
+
+	producerCtx := logger.WithLogValue(context.Background(), logger.CorrelationIDField, "corr-1")
+	bus := &fakeBus{}
+	require.NoError(t, Publish(producerCtx, bus, testTopic, testEvent{Name: "steve"}))
+	require.Len(t, bus.published[testTopic.Name], 1)
+
+	data, err := json.Marshal(bus.published[testTopic.Name][0])
+	require.NoError(t, err)
+
+	var (
+		got         testEvent
+		gotEnvelope Envelope
+		gotCorrID   any
+	)
+	handler := Handle(testTopic, func(ctx context.Context, payload testEvent) error {
+		got = payload
+		gotEnvelope, _ = EnvelopeFromContext(ctx)
+		gotCorrID, _ = logger.LogValue(ctx, logger.CorrelationIDField)
+		return nil
+	})
+	require.NoError(t, handler(context.Background(), data))
+
+	assert.Equal(t, testEvent{Name: "steve"}, got)
+	assert.Equal(t, "corr-1", gotCorrID)
+	assert.Equal(t, "corr-1", gotEnvelope.CorrelationID)
+	assert.Equal(t, testTopic.Name, gotEnvelope.Type)
+	assert.Equal(t, 2, gotEnvelope.SchemaVersion)
+	assert.Equal(t, Producer, gotEnvelope.Producer)
+	assert.NotEmpty(t, gotEnvelope.ID)
+	assert.False(t, gotEnvelope.Timestamp.IsZero())
+}
+
+func TestHandle_Decoding(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		data    string
+		want    testEvent
+		wantErr error
+		anyErr  bool
+	}{
+		{
+			name: "legacy_event_without_envelope",
+			data: `{"name":"alex"}`,
+			want: testEvent{Name: "alex"},
+		},
+		{
+			name: "older_schema_version",
+			data: `{"id":"1","type":"test.created","schema_version":1,"payload":{"name":"alex"}}`,
+			want: testEvent{Name: "alex"},
+		},
+		{
+			name:    "newer_schema_version",
+			data:    `{"id":"1","type":"test.created","schema_version":3,"payload":{"name":"alex"}}`,
+			wantErr: ErrUnsupportedSchemaVersion,
+		},
+		{
+			name:   "other_event_type",
+			data:   `{"id":"1","type":"other","schema_version":1,"payload":{"name":"alex"}}`,
+			anyErr: true,
+		},
+		{
+			name:   "invalid_json",
+			data:   `not json`,
+			anyErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			var got testEvent
+			err := Handle(testTopic, func(_ context.Context, payload testEvent) error {
+				got = payload
+				return nil
+			})(context.Background(), []byte(tt.data))
+
+			switch {
+			case tt.wantErr != nil:
+				require.ErrorIs(t, err, tt.wantErr)
+			case tt.anyErr:
+				require.Error(t, err)
+			default:
+				require.NoError(t, err)
+				assert.Equal(t, tt.want, got)
+			}
+		})
+	}
+}
@@ -3,16 +3,21 @@ package eventbus
 import (
 	"context"
 	"errors"
+	"time"
 )
 
 var (
-	ErrBusClosed     = errors.New("event bus is closed")
-	ErrTopicNotFound = errors.New("topic not found")
+	ErrBusClosed            = errors.New("event bus is closed")
+	ErrTopicNotFound        = errors.New("topic not found")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+	ErrBufferFull           = errors.New("event buffer is full")
 )
 
 type EventBus interface {
 	IEventPublisher
-	NewConsumer(topic string) (IEventConsumer, error)
+	// NewConsumer subscribes to the topic. Every subscriber receives its own copy of each event,
+	// while consumers created with the same subscriber name share the events between them.
+	NewConsumer(ctx context.Context, topic, subscriber string) (IEventConsumer, error)
 
 	Close() error
 }
@@ -22,5 +27,18 @@ type IEventPublisher interface {
 }
 
 type IEventConsumer interface {
-	Consume(ctx context.Context) ([]byte, bool)
+	// Consume waits for the next message. The second return value is false when the consumer is closed.
+	Consume(ctx context.Context) (IEventMessage, bool)
+}
+
+// IEventMessage is a delivered event. It must be acknowledged with Ack after successful handling,
+// or returned with Nack to be redelivered later.
+type IEventMessage interface {
+	Data() []byte
+	// Delivered returns how many times the message was delivered, starting at 1.
+	Delivered() int
+	Ack(ctx context.Context) error
+	Nack(ctx context.Context, delay time.Duration) error
+	// InProgress tells the bus the message is still being handled, so it is not redelivered meanwhile.
+	InProgress(ctx context.Context) error
 }
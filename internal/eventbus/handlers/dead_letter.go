@@ -0,0 +1,27 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"whitelist-bot/internal/core/logger"
+
+	eBus "whitelist-bot/internal/eventbus"
+)
+
+type iDeadLetterCreator interface {
+	CreateDeadLetter(ctx context.Context, deadLetter eBus.DeadLetter) error
+}
+
+// HandleDeadLetterEvent stores dead letters, so admins can inspect, replay or purge them.
+func HandleDeadLetterEvent(store iDeadLetterCreator) eBus.EventHandler[eBus.DeadLetter] {
+	return func(ctx context.Context, deadLetter eBus.DeadLetter) error {
+		ctx = logger.WithLogValue(ctx, logger.DeadLetterIDField, deadLetter.ID.String())
+		slog.WarnContext(ctx, "Storing dead letter", "dead_letter_topic", deadLetter.Topic)
+
+		if err := store.CreateDeadLetter(ctx, deadLetter); err != nil {
+			return fmt.Errorf("failed to store dead letter: %w", err)
+		}
+		return nil
+	}
+}
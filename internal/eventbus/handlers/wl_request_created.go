@@ -2,15 +2,16 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
 	"time"
+	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/core/logger"
 	"whitelist-bot/internal/core/utils"
 	domainUser "whitelist-bot/internal/domain/user"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/metastore"
 	"whitelist-bot/internal/msgs"
 
@@ -26,8 +27,13 @@ const (
 	ttlWLRequestAdminNotified = 24 * time.Hour
 )
 
+type iUserGetter interface {
+	UserByTelegramID(ctx context.Context, telegramID int64) (domainUser.User, error)
+}
+
+var TopicWLRequestCreated = eBus.NewTopic[WLRequestCreatedEvent](core.TopicWLRequestCreated, 1)
+
 type WLRequestCreatedEvent struct {
-	ID        utils.UniqueID            `json:"id"`
 	WLRequest domainWLRequest.WLRequest `json:"wl_request"`
 	Requester domainUser.User           `json:"requester"`
 }
@@ -36,17 +42,10 @@ func HandleWLRequestCreatedEvent(
 	mg metastore.IMetastoreGetter,
 	ms metastore.IMetastoreSetter,
 	sender utils.IMessageSender,
+	userGetter iUserGetter,
 	adminChatIDs []int64,
-) eBus.ConsumerUnitHandler {
-	return func(ctx context.Context, data []byte) error {
-		var event WLRequestCreatedEvent
-
-		err := json.Unmarshal(data, &event)
-		if err != nil {
-			return fmt.Errorf("failed to unmarshal wl request created event: %w", err)
-		}
-
-		ctx = logger.WithLogValue(ctx, logger.EventIDField, event.ID.String())
+) eBus.EventHandler[WLRequestCreatedEvent] {
+	return func(ctx context.Context, event WLRequestCreatedEvent) error {
 		ctx = logger.WithLogValue(ctx, logger.WLRequestIDField, event.WLRequest.ID().String())
 		ctx = logger.WithLogValue(ctx, logger.RequesterIDField, event.Requester.ID().String())
 		slog.InfoContext(ctx, "Handling wl request created event")
@@ -67,7 +66,7 @@ func HandleWLRequestCreatedEvent(
 		for i, chatID := range adminChatIDs {
 			_, err = sender.SendMessage(ctx, &bot.SendMessageParams{
 				ChatID:    chatID,
-				Text:      msgs.WLRequestAdminNotification(),
+				Text:      msgs.WLRequestAdminNotification(adminLang(ctx, userGetter, chatID)),
 				ParseMode: models.ParseModeHTML,
 			})
 			if err != nil {
@@ -98,3 +97,13 @@ func isRawTimeExpired(ctx context.Context, rawTime string) bool {
 	}
 	return parsedTime.IsZero() || time.Since(parsedTime) > ttlWLRequestAdminNotified
 }
+
+// adminLang returns the stored language of an admin, falling back to the default one.
+func adminLang(ctx context.Context, userGetter iUserGetter, telegramID int64) i18n.Lang {
+	admin, err := userGetter.UserByTelegramID(ctx, telegramID)
+	if err != nil {
+		slog.DebugContext(ctx, "Failed to get admin language", logger.ErrorField, err.Error())
+		return i18n.DefaultLang
+	}
+	return i18n.ParseLang(string(admin.LanguageCode()))
+}
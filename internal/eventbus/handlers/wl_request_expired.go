@@ -0,0 +1,59 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+
+	eBus "whitelist-bot/internal/eventbus"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+type iRequesterGetter interface {
+	UserByID(ctx context.Context, id domainUser.ID) (domainUser.User, error)
+}
+
+var TopicWLRequestExpired = eBus.NewTopic[WLRequestExpiredEvent](core.TopicWLRequestExpired, 1)
+
+type WLRequestExpiredEvent struct {
+	WLRequest     domainWLRequest.WLRequest `json:"wl_request"`
+	MaxPendingAge time.Duration             `json:"max_pending_age"`
+}
+
+// HandleWLRequestExpiredEvent notifies the requester that the request expired and invites them to reapply.
+func HandleWLRequestExpiredEvent(
+	sender utils.IMessageSender,
+	requesterGetter iRequesterGetter,
+) eBus.EventHandler[WLRequestExpiredEvent] {
+	return func(ctx context.Context, event WLRequestExpiredEvent) error {
+		ctx = logger.WithLogValue(ctx, logger.WLRequestIDField, event.WLRequest.ID().String())
+		ctx = logger.WithLogValue(ctx, logger.RequesterIDField, event.WLRequest.RequesterID().String())
+		slog.InfoContext(ctx, "Handling wl request expired event")
+
+		requester, err := requesterGetter.UserByID(ctx, domainUser.ID(event.WLRequest.RequesterID()))
+		if err != nil {
+			return fmt.Errorf("failed to get requester: %w", err)
+		}
+
+		lang := i18n.ParseLang(string(requester.LanguageCode()))
+		_, err = sender.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    int64(requester.ChatID()),
+			Text:      msgs.WLRequestExpired(lang, event.WLRequest, event.MaxPendingAge),
+			ParseMode: models.ParseModeHTML,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to send wl request expired message: %w", err)
+		}
+		return nil
+	}
+}
@@ -1,71 +1,227 @@
 package memory
 
 import (
+	"context"
 	"errors"
+	"fmt"
 	"sync"
+	"whitelist-bot/internal/eventbus"
 )
 
-// TODO: add generics for data type
+// OverflowPolicy decides what happens to a push into a full buffer.
+type OverflowPolicy string
+
+const (
+	// OverflowDropOldest overwrites the oldest item.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+	// OverflowDropNewest discards the pushed item.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+	// OverflowBlock waits until a consumer frees space or the context is done.
+	OverflowBlock OverflowPolicy = "block"
+	// OverflowError rejects the pushed item with eventbus.ErrBufferFull.
+	OverflowError OverflowPolicy = "error"
+)
+
+func ParseOverflowPolicy(s string) (OverflowPolicy, error) {
+	switch policy := OverflowPolicy(s); policy {
+	case OverflowDropOldest, OverflowDropNewest, OverflowBlock, OverflowError:
+		return policy, nil
+	case "":
+		return OverflowDropOldest, nil
+	default:
+		return "", fmt.Errorf("unknown overflow policy: %q", s)
+	}
+}
+
+// Item is a buffered event with its delivery counter.
+type Item struct {
+	Data      []byte
+	Delivered int
+}
+
 // TODO: add sync.Cond for waiting for data
 type Buffer struct {
 	mu       sync.RWMutex
-	buffer   [][]byte
+	buffer   []Item
 	capacity int
+	overflow OverflowPolicy
 	head     int
 	size     int
+	// reserved are the free slots held for pushes that must not fail, see reserve.
+	reserved int
 	dropped  int
 	notifier chan struct{}
-	closed   bool
+	// space wakes up publishers blocked on a full buffer.
+	space  chan struct{}
+	done   chan struct{}
+	closed bool
 }
 
-func NewBuffer(capacity int) (*Buffer, error) {
+func NewBuffer(capacity int, overflow OverflowPolicy) (*Buffer, error) {
 	if capacity <= 0 {
 		return nil, errors.New("capacity must be greater than 0")
 	}
+	if overflow == "" {
+		overflow = OverflowDropOldest
+	}
 	return &Buffer{
 		capacity: capacity,
-		buffer:   make([][]byte, capacity),
+		overflow: overflow,
+		buffer:   make([]Item, capacity),
 		notifier: make(chan struct{}, 1),
+		space:    make(chan struct{}, 1),
+		done:     make(chan struct{}),
 	}, nil
 }
 
-func (b *Buffer) Push(data []byte) {
+// Push appends data to the buffer, applying the overflow policy when it is full.
+// It reports whether an item was dropped to make room or instead of data.
+func (b *Buffer) Push(ctx context.Context, data []byte) (bool, error) {
+	return b.PushItem(ctx, Item{Data: data})
+}
+
+// PushItem pushes an item keeping its delivery counter, used for redeliveries.
+func (b *Buffer) PushItem(ctx context.Context, item Item) (bool, error) {
+	for {
+		dropped, pushed, err := b.tryPush(item, false)
+		if pushed || err != nil {
+			return dropped, err
+		}
+		if err := b.waitSpace(ctx); err != nil {
+			return false, err
+		}
+	}
+}
+
+// reserve holds a free slot, applying the overflow policy when there is none, so the following
+// pushReserved can't be rejected. A publisher reserves in every buffer before pushing to any.
+// Buffers that drop items never reject a push and hold nothing; it reports whether a slot was held.
+func (b *Buffer) reserve(ctx context.Context) (bool, error) {
+	if b.overflow != OverflowBlock && b.overflow != OverflowError {
+		return false, nil
+	}
+	for {
+		reserved, err := b.tryReserve()
+		if reserved || err != nil {
+			return reserved, err
+		}
+		if err := b.waitSpace(ctx); err != nil {
+			return false, err
+		}
+	}
+}
+
+func (b *Buffer) tryReserve() (bool, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return false, eventbus.ErrBusClosed
+	}
+	if b.size+b.reserved == b.capacity {
+		if b.overflow == OverflowError {
+			return false, eventbus.ErrBufferFull
+		}
+		return false, nil
+	}
+	b.reserved++
+	return true, nil
+}
+
+// release gives back a slot held by reserve that won't be pushed into.
+func (b *Buffer) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.reserved--
+	b.signalSpace()
+}
+
+// pushReserved pushes the item into the slot held by reserve, or like Push if reserved is false.
+func (b *Buffer) pushReserved(item Item, reserved bool) (bool, error) {
+	dropped, _, err := b.tryPush(item, reserved)
+	return dropped, err
+}
+
+func (b *Buffer) waitSpace(ctx context.Context) error {
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("%w: %w", eventbus.ErrBufferFull, ctx.Err())
+	case <-b.done:
+		return eventbus.ErrBusClosed
+	case <-b.space:
+		return nil
+	}
+}
+
+// tryPush pushes the item unless the buffer is full and the policy is to block.
+// A reserved push takes the slot held for it, so it always fits.
+func (b *Buffer) tryPush(item Item, reserved bool) (dropped, pushed bool, err error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if b.closed {
-		return
+		return false, false, eventbus.ErrBusClosed
+	}
+	if reserved {
+		b.reserved--
 	}
 
-	b.buffer[b.head] = data
+	if b.size+b.reserved == b.capacity {
+		switch b.overflow {
+		case OverflowBlock:
+			return false, false, nil
+		case OverflowError:
+			return false, false, eventbus.ErrBufferFull
+		case OverflowDropNewest:
+			b.dropped++
+			return true, true, nil
+		}
+	}
+
+	b.buffer[b.head] = item
 	b.head = (b.head + 1) % b.capacity
 
 	if b.size < b.capacity {
 		b.size++
 	} else {
 		b.dropped++
+		dropped = true
 	}
 
 	select {
 	case b.notifier <- struct{}{}:
 	default:
 	}
+	// Pass the wake-up on to the next blocked publisher if there is still room.
+	if b.size+b.reserved < b.capacity {
+		b.signalSpace()
+	}
+	return dropped, true, nil
 }
 
-func (b *Buffer) Pop() ([]byte, bool) {
+func (b *Buffer) Pop() (Item, bool) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if b.size == 0 {
-		return nil, false
+		return Item{}, false
 	}
 
 	tailIndex := (b.head - b.size + b.capacity) % b.capacity
-	data := b.buffer[tailIndex]
-	b.buffer[tailIndex] = nil
+	item := b.buffer[tailIndex]
+	b.buffer[tailIndex] = Item{}
 	b.size--
+	b.signalSpace()
 
-	return data, true
+	return item, true
+}
+
+func (b *Buffer) signalSpace() {
+	select {
+	case b.space <- struct{}{}:
+	default:
+	}
 }
 
 func (b *Buffer) Close() {
@@ -75,6 +231,7 @@ func (b *Buffer) Close() {
 	if !b.closed {
 		b.closed = true
 		close(b.notifier)
+		close(b.done)
 	}
 
 }
@@ -90,6 +247,13 @@ func (b *Buffer) Notifier() <-chan struct{} {
 	return b.notifier
 }
 
+func (b *Buffer) Len() int {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	return b.size
+}
+
 func (b *Buffer) Dropped() int {
 	b.mu.RLock()
 	defer b.mu.RUnlock()
@@ -3,50 +3,158 @@ package memory
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"log/slog"
 	"sync"
+	"whitelist-bot/internal/core/logger"
 	"whitelist-bot/internal/eventbus"
 )
 
+// unclaimedSubscriber names the topic buffer in logs and errors while the topic has no subscribers.
+const unclaimedSubscriber = "unclaimed"
+
+// TopicConfig is the size and overflow policy of the subscriber buffers of a topic.
+type TopicConfig struct {
+	Capacity int
+	Overflow OverflowPolicy
+}
+
+// NewTopicConfigs merges per-topic capacities and overflow policies. Missing values fall back
+// to the bus defaults.
+func NewTopicConfigs(capacities map[string]int, overflows map[string]string) (map[string]TopicConfig, error) {
+	topicConfigs := make(map[string]TopicConfig, len(capacities)+len(overflows))
+	for topic, capacity := range capacities {
+		cfg := topicConfigs[topic]
+		cfg.Capacity = capacity
+		topicConfigs[topic] = cfg
+	}
+	for topic, overflow := range overflows {
+		policy, err := ParseOverflowPolicy(overflow)
+		if err != nil {
+			return nil, fmt.Errorf("invalid overflow policy for topic %q: %w", topic, err)
+		}
+		cfg := topicConfigs[topic]
+		cfg.Overflow = policy
+		topicConfigs[topic] = cfg
+	}
+	return topicConfigs, nil
+}
+
+// Bus is an in-memory event bus. Every subscriber of a topic has its own buffer, so each of them
+// receives every event published after it subscribed, and a slow subscriber only drops its own events.
+// Events published to a topic without subscribers wait in a topic buffer, which becomes the buffer
+// of the first subscriber, so events relayed before the consumers start are not lost.
 type Bus struct {
-	mu       sync.RWMutex
-	topics   map[string]*Buffer
-	capacity int
-	closed   bool
+	mu sync.RWMutex
+	// topics maps a topic to the buffers of its subscribers.
+	topics map[string]map[string]*Buffer
+	// unclaimed holds the events of topics nobody has subscribed to yet.
+	unclaimed    map[string]*Buffer
+	defaults     TopicConfig
+	topicConfigs map[string]TopicConfig
+	closed       bool
 }
 
-// Not support multiple readers from 1 topic.
-// Use different implementations if you need multiple readers from 1 topic.
-func New(bufferCapacity int) *Bus {
+// New creates a bus with the defaults for every topic, overridden per topic by topicConfigs.
+func New(defaults TopicConfig, topicConfigs map[string]TopicConfig) *Bus {
 	return &Bus{
-		topics:   make(map[string]*Buffer),
-		capacity: bufferCapacity,
+		topics:       make(map[string]map[string]*Buffer),
+		unclaimed:    make(map[string]*Buffer),
+		defaults:     defaults,
+		topicConfigs: topicConfigs,
 	}
 }
 
+// Publish pushes the event to the buffer of every subscriber. With the block policy it waits
+// for space until ctx is done, with the error policy it fails fast; both return eventbus.ErrBufferFull.
+// The space is reserved in every buffer first, so a rejected event is pushed to none of them
+// and publishing it again delivers no duplicates.
 func (b *Bus) Publish(ctx context.Context, topic string, data any) error {
 	dataBytes, err := json.Marshal(data)
 	if err != nil {
 		return fmt.Errorf("failed to json marshal data: %w", err)
 	}
 
+	subscribers, err := b.subscribers(topic)
+	if err != nil {
+		return err
+	}
+
+	// The lock is not held while pushing, so a blocked publisher does not block Close.
+	reserved := make(map[string]bool, len(subscribers))
+	for subscriber, buffer := range subscribers {
+		held, err := buffer.reserve(ctx)
+		if err != nil {
+			for subscriber, held := range reserved {
+				if held {
+					subscribers[subscriber].release()
+				}
+			}
+			return fmt.Errorf("failed to push event for subscriber %q: %w", subscriber, err)
+		}
+		reserved[subscriber] = held
+	}
+
+	var errs []error
+	for subscriber, buffer := range subscribers {
+		dropped, err := buffer.pushReserved(Item{Data: dataBytes}, reserved[subscriber])
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to push event for subscriber %q: %w", subscriber, err))
+			continue
+		}
+		if dropped {
+			slog.WarnContext(ctx, "Subscriber buffer is full, event dropped",
+				logger.TopicField, topic,
+				logger.SubscriberField, subscriber,
+				"dropped", buffer.Dropped(),
+			)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// subscribers returns a snapshot of the subscriber buffers of the topic.
+// A topic without subscribers gets its unclaimed buffer instead, created on the first event.
+func (b *Bus) subscribers(topic string) (map[string]*Buffer, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
 	if b.closed {
-		return eventbus.ErrBusClosed
+		return nil, eventbus.ErrBusClosed
 	}
-
-	_, exists := b.topics[topic]
-	if !exists {
-		buffer, err := NewBuffer(b.capacity)
-		if err != nil {
-			return fmt.Errorf("failed to create buffer: %w", err)
+	if len(b.topics[topic]) == 0 {
+		buffer, exists := b.unclaimed[topic]
+		if !exists {
+			var err error
+			cfg := b.topicConfig(topic)
+			buffer, err = NewBuffer(cfg.Capacity, cfg.Overflow)
+			if err != nil {
+				return nil, fmt.Errorf("failed to create buffer: %w", err)
+			}
+			b.unclaimed[topic] = buffer
 		}
-		b.topics[topic] = buffer
+		return map[string]*Buffer{unclaimedSubscriber: buffer}, nil
 	}
-	b.topics[topic].Push(dataBytes)
-	return nil
+	subscribers := make(map[string]*Buffer, len(b.topics[topic]))
+	for subscriber, buffer := range b.topics[topic] {
+		subscribers[subscriber] = buffer
+	}
+	return subscribers, nil
+}
+
+func (b *Bus) topicConfig(topic string) TopicConfig {
+	cfg, ok := b.topicConfigs[topic]
+	if !ok {
+		return b.defaults
+	}
+	if cfg.Capacity <= 0 {
+		cfg.Capacity = b.defaults.Capacity
+	}
+	if cfg.Overflow == "" {
+		cfg.Overflow = b.defaults.Overflow
+	}
+	return cfg
 }
 
 func (b *Bus) Close() error {
@@ -54,14 +162,22 @@ func (b *Bus) Close() error {
 	defer b.mu.Unlock()
 
 	b.closed = true
-	for _, buffer := range b.topics {
+	for _, subscribers := range b.topics {
+		for _, buffer := range subscribers {
+			buffer.Close()
+		}
+	}
+	for _, buffer := range b.unclaimed {
 		buffer.Close()
 	}
 	b.topics = nil
+	b.unclaimed = nil
 	return nil
 }
 
-func (b *Bus) NewConsumer(topic string) (eventbus.IEventConsumer, error) {
+// NewConsumer subscribes to the topic. Consumers with the same subscriber name read from one buffer
+// and share its events. The first subscriber of a topic takes over the events published before it.
+func (b *Bus) NewConsumer(_ context.Context, topic, subscriber string) (eventbus.IEventConsumer, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
@@ -69,15 +185,59 @@ func (b *Bus) NewConsumer(topic string) (eventbus.IEventConsumer, error) {
 		return nil, eventbus.ErrBusClosed
 	}
 
-	_, exists := b.topics[topic]
+	subscribers, exists := b.topics[topic]
+	if !exists {
+		subscribers = make(map[string]*Buffer)
+		b.topics[topic] = subscribers
+	}
+	buffer, exists := subscribers[subscriber]
+	if !exists && len(subscribers) == 0 && b.unclaimed[topic] != nil {
+		buffer, exists = b.unclaimed[topic], true
+		delete(b.unclaimed, topic)
+		subscribers[subscriber] = buffer
+	}
 	if !exists {
-		buffer, err := NewBuffer(b.capacity)
+		var err error
+		cfg := b.topicConfig(topic)
+		buffer, err = NewBuffer(cfg.Capacity, cfg.Overflow)
 		if err != nil {
 			return nil, fmt.Errorf("failed to create buffer: %w", err)
 		}
-		b.topics[topic] = buffer
+		subscribers[subscriber] = buffer
 	}
 	return &Consumer{
-		buffer: b.topics[topic],
+		buffer: buffer,
 	}, nil
 }
+
+// Dropped returns how many events the subscriber lost because its buffer was full.
+func (b *Bus) Dropped(topic, subscriber string) (int, error) {
+	buffer, err := b.buffer(topic, subscriber)
+	if err != nil {
+		return 0, err
+	}
+	return buffer.Dropped(), nil
+}
+
+// Pending returns how many events wait in the subscriber buffer.
+func (b *Bus) Pending(topic, subscriber string) (int, error) {
+	buffer, err := b.buffer(topic, subscriber)
+	if err != nil {
+		return 0, err
+	}
+	return buffer.Len(), nil
+}
+
+func (b *Bus) buffer(topic, subscriber string) (*Buffer, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	if b.closed {
+		return nil, eventbus.ErrBusClosed
+	}
+	buffer, exists := b.topics[topic][subscriber]
+	if !exists {
+		return nil, eventbus.ErrSubscriptionNotFound
+	}
+	return buffer, nil
+}
@@ -0,0 +1,325 @@
+package memory
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+	"whitelist-bot/internal/eventbus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func consumeData(t *testing.T, consumer eventbus.IEventConsumer) string {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	msg, ok := consumer.Consume(ctx)
+	require.True(t, ok, "expected an event")
+	require.NoError(t, msg.Ack(ctx))
+	return string(msg.Data())
+}
+
+func assertEmpty(t *testing.T, consumer eventbus.IEventConsumer) {
+	t.Helper()
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	msg, ok := consumer.Consume(ctx)
+	assert.False(t, ok, "unexpected event: %v", msg)
+}
+
+func TestBus_FanOut(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 10}, nil)
+	t.Cleanup(func() { _ = bus.Close() })
+
+	notifications, err := bus.NewConsumer(ctx, "topic", "notifications")
+	require.NoError(t, err)
+	webhooks, err := bus.NewConsumer(ctx, "topic", "webhooks")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(ctx, "topic", 1))
+	require.NoError(t, bus.Publish(ctx, "topic", 2))
+
+	assert.Equal(t, "1", consumeData(t, notifications))
+	assert.Equal(t, "2", consumeData(t, notifications))
+	assert.Equal(t, "1", consumeData(t, webhooks))
+	assert.Equal(t, "2", consumeData(t, webhooks))
+}
+
+func TestBus_SameSubscriberSharesEvents(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 10}, nil)
+	t.Cleanup(func() { _ = bus.Close() })
+
+	first, err := bus.NewConsumer(ctx, "topic", "export")
+	require.NoError(t, err)
+	second, err := bus.NewConsumer(ctx, "topic", "export")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(ctx, "topic", 1))
+	require.NoError(t, bus.Publish(ctx, "topic", 2))
+
+	assert.Equal(t, "1", consumeData(t, first))
+	assert.Equal(t, "2", consumeData(t, second))
+	assertEmpty(t, first)
+	assertEmpty(t, second)
+}
+
+func TestBus_DropsArePerSubscriber(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 2}, nil)
+	t.Cleanup(func() { _ = bus.Close() })
+
+	slow, err := bus.NewConsumer(ctx, "topic", "slow")
+	require.NoError(t, err)
+	fast, err := bus.NewConsumer(ctx, "topic", "fast")
+	require.NoError(t, err)
+
+	for i := 1; i <= 3; i++ {
+		require.NoError(t, bus.Publish(ctx, "topic", i))
+		assert.Equal(t, strconv.Itoa(i), consumeData(t, fast))
+	}
+
+	dropped, err := bus.Dropped("topic", "slow")
+	require.NoError(t, err)
+	assert.Equal(t, 1, dropped)
+	dropped, err = bus.Dropped("topic", "fast")
+	require.NoError(t, err)
+	assert.Equal(t, 0, dropped)
+
+	pending, err := bus.Pending("topic", "slow")
+	require.NoError(t, err)
+	assert.Equal(t, 2, pending)
+	assert.Equal(t, "2", consumeData(t, slow))
+	assert.Equal(t, "3", consumeData(t, slow))
+
+	_, err = bus.Dropped("topic", "unknown")
+	require.ErrorIs(t, err, eventbus.ErrSubscriptionNotFound)
+}
+
+func TestBus_NackRedeliversToSameSubscriber(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 10}, nil)
+	t.Cleanup(func() { _ = bus.Close() })
+
+	failing, err := bus.NewConsumer(ctx, "topic", "failing")
+	require.NoError(t, err)
+	other, err := bus.NewConsumer(ctx, "topic", "other")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(ctx, "topic", 1))
+	assert.Equal(t, "1", consumeData(t, other))
+
+	msg, ok := failing.Consume(ctx)
+	require.True(t, ok)
+	require.NoError(t, msg.Nack(ctx, 0))
+
+	redelivered, ok := failing.Consume(ctx)
+	require.True(t, ok)
+	assert.Equal(t, "1", string(redelivered.Data()))
+	assert.Equal(t, 2, redelivered.Delivered())
+	assertEmpty(t, other)
+}
+
+func TestBus_PublishWithoutSubscribers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 10}, nil)
+
+	require.NoError(t, bus.Publish(ctx, "topic", 1))
+
+	// The first subscriber takes over the events published before it, later ones get new events only.
+	consumer, err := bus.NewConsumer(ctx, "topic", "late")
+	require.NoError(t, err)
+	other, err := bus.NewConsumer(ctx, "topic", "other")
+	require.NoError(t, err)
+	assert.Equal(t, "1", consumeData(t, consumer))
+	assertEmpty(t, other)
+
+	require.NoError(t, bus.Close())
+	require.ErrorIs(t, bus.Publish(ctx, "topic", 2), eventbus.ErrBusClosed)
+	_, err = bus.NewConsumer(ctx, "topic", "late")
+	require.ErrorIs(t, err, eventbus.ErrBusClosed)
+}
+
+func TestBus_OverflowPolicies(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name        string
+		overflow    OverflowPolicy
+		wantErr     error
+		wantDropped int
+		wantEvents  []string
+	}{
+		{name: "drop_oldest", overflow: OverflowDropOldest, wantDropped: 1, wantEvents: []string{"2", "3"}},
+		{name: "drop_newest", overflow: OverflowDropNewest, wantDropped: 1, wantEvents: []string{"1", "2"}},
+		{name: "error", overflow: OverflowError, wantErr: eventbus.ErrBufferFull, wantEvents: []string{"1", "2"}},
+		{name: "block_until_deadline", overflow: OverflowBlock, wantErr: context.DeadlineExceeded, wantEvents: []string{"1", "2"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			bus := New(TopicConfig{Capacity: 2, Overflow: tt.overflow}, nil)
+			t.Cleanup(func() { _ = bus.Close() })
+
+			consumer, err := bus.NewConsumer(ctx, "topic", "subscriber")
+			require.NoError(t, err)
+
+			require.NoError(t, bus.Publish(ctx, "topic", 1))
+			require.NoError(t, bus.Publish(ctx, "topic", 2))
+
+			publishCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			err = bus.Publish(publishCtx, "topic", 3)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				require.ErrorIs(t, err, eventbus.ErrBufferFull)
+			} else {
+				require.NoError(t, err)
+			}
+
+			dropped, err := bus.Dropped("topic", "subscriber")
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantDropped, dropped)
+			for _, want := range tt.wantEvents {
+				assert.Equal(t, want, consumeData(t, consumer))
+			}
+			assertEmpty(t, consumer)
+		})
+	}
+}
+
+func TestBus_RejectedEventIsPushedToNone(t *testing.T) {
+	t.Parallel()
+
+	for _, overflow := range []OverflowPolicy{OverflowError, OverflowBlock} {
+		t.Run(string(overflow), func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			bus := New(TopicConfig{Capacity: 1, Overflow: overflow}, nil)
+			t.Cleanup(func() { _ = bus.Close() })
+
+			fast, err := bus.NewConsumer(ctx, "topic", "fast")
+			require.NoError(t, err)
+			slow, err := bus.NewConsumer(ctx, "topic", "slow")
+			require.NoError(t, err)
+
+			require.NoError(t, bus.Publish(ctx, "topic", 1))
+			assert.Equal(t, "1", consumeData(t, fast))
+
+			// The slow subscriber is full, so the fast one must not get the event either:
+			// the outbox publishes it again and would deliver it twice.
+			publishCtx, cancel := context.WithTimeout(ctx, 20*time.Millisecond)
+			defer cancel()
+			require.ErrorIs(t, bus.Publish(publishCtx, "topic", 2), eventbus.ErrBufferFull)
+			assertEmpty(t, fast)
+
+			assert.Equal(t, "1", consumeData(t, slow))
+			require.NoError(t, bus.Publish(ctx, "topic", 2))
+			assert.Equal(t, "2", consumeData(t, fast))
+			assert.Equal(t, "2", consumeData(t, slow))
+		})
+	}
+}
+
+func TestBus_BlockWaitsForConsumer(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 1, Overflow: OverflowBlock}, nil)
+	t.Cleanup(func() { _ = bus.Close() })
+
+	consumer, err := bus.NewConsumer(ctx, "topic", "subscriber")
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, "topic", 1))
+
+	published := make(chan error, 1)
+	go func() { published <- bus.Publish(ctx, "topic", 2) }()
+
+	select {
+	case err := <-published:
+		t.Fatalf("publish returned before space was freed: %v", err)
+	case <-time.After(20 * time.Millisecond):
+	}
+
+	assert.Equal(t, "1", consumeData(t, consumer))
+	select {
+	case err := <-published:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("publish is still blocked after space was freed")
+	}
+	assert.Equal(t, "2", consumeData(t, consumer))
+}
+
+func TestBus_CloseUnblocksPublishers(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 1, Overflow: OverflowBlock}, nil)
+
+	_, err := bus.NewConsumer(ctx, "topic", "subscriber")
+	require.NoError(t, err)
+	require.NoError(t, bus.Publish(ctx, "topic", 1))
+
+	published := make(chan error, 1)
+	go func() { published <- bus.Publish(ctx, "topic", 2) }()
+	time.Sleep(10 * time.Millisecond)
+	require.NoError(t, bus.Close())
+
+	select {
+	case err := <-published:
+		require.ErrorIs(t, err, eventbus.ErrBusClosed)
+	case <-time.After(time.Second):
+		t.Fatal("publish is still blocked after the bus was closed")
+	}
+}
+
+func TestBus_TopicConfigs(t *testing.T) {
+	t.Parallel()
+
+	topicConfigs, err := NewTopicConfigs(
+		map[string]int{"approvals": 1},
+		map[string]string{"approvals": "error", "audit": "drop-newest"},
+	)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]TopicConfig{
+		"approvals": {Capacity: 1, Overflow: OverflowError},
+		"audit":     {Overflow: OverflowDropNewest},
+	}, topicConfigs)
+
+	ctx := context.Background()
+	bus := New(TopicConfig{Capacity: 5, Overflow: OverflowDropOldest}, topicConfigs)
+	t.Cleanup(func() { _ = bus.Close() })
+
+	_, err = bus.NewConsumer(ctx, "approvals", "subscriber")
+	require.NoError(t, err)
+	_, err = bus.NewConsumer(ctx, "other", "subscriber")
+	require.NoError(t, err)
+
+	require.NoError(t, bus.Publish(ctx, "approvals", 1))
+	require.ErrorIs(t, bus.Publish(ctx, "approvals", 2), eventbus.ErrBufferFull)
+	for i := range 5 {
+		require.NoError(t, bus.Publish(ctx, "other", i))
+	}
+
+	_, err = NewTopicConfigs(nil, map[string]string{"approvals": "sometimes"})
+	require.Error(t, err)
+}
@@ -2,32 +2,95 @@ package memory
 
 import (
 	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/eventbus"
 )
 
 type Consumer struct {
 	buffer *Buffer
 }
 
-func (c *Consumer) Consume(ctx context.Context) ([]byte, bool) {
+func (c *Consumer) Consume(ctx context.Context) (eventbus.IEventMessage, bool) {
 	for {
 
-		if data, ok := c.buffer.Pop(); ok {
-			return data, true
+		if item, ok := c.buffer.Pop(); ok {
+			return c.newMessage(item), true
 		}
 
 		select {
 		case <-ctx.Done():
-			if data, ok := c.buffer.Pop(); ok {
-				return data, true
+			if item, ok := c.buffer.Pop(); ok {
+				return c.newMessage(item), true
 			}
 			return nil, false
 		case _, ok := <-c.buffer.Notifier():
 			if !ok {
-				if data, ok := c.buffer.Pop(); ok {
-					return data, true
+				if item, ok := c.buffer.Pop(); ok {
+					return c.newMessage(item), true
 				}
 				return nil, false
 			}
 		}
 	}
 }
+
+func (c *Consumer) newMessage(item Item) *Message {
+	item.Delivered++
+	return &Message{
+		buffer: c.buffer,
+		item:   item,
+	}
+}
+
+// Message is an in-memory delivery. Ack and InProgress are no-ops,
+// Nack pushes the event back to the buffer after the delay.
+type Message struct {
+	buffer *Buffer
+	item   Item
+	once   sync.Once
+}
+
+func (m *Message) Data() []byte {
+	return m.item.Data
+}
+
+func (m *Message) Delivered() int {
+	return m.item.Delivered
+}
+
+func (m *Message) Ack(_ context.Context) error {
+	return nil
+}
+
+func (m *Message) Nack(_ context.Context, delay time.Duration) error {
+	m.once.Do(func() {
+		// Redeliver in the background: with the block policy the push may wait for the consumer.
+		if delay <= 0 {
+			go m.redeliver()
+			return
+		}
+		time.AfterFunc(delay, m.redeliver)
+	})
+	return nil
+}
+
+// redeliver pushes the message back under the buffer overflow policy, waiting for space
+// until the bus is closed if the policy is to block.
+func (m *Message) redeliver() {
+	dropped, err := m.buffer.PushItem(context.Background(), m.item)
+	switch {
+	case errors.Is(err, eventbus.ErrBusClosed):
+	case err != nil:
+		slog.Warn("Failed to redeliver event", logger.ErrorField, err.Error())
+	case dropped:
+		slog.Warn("Subscriber buffer is full, event dropped on redelivery")
+	}
+}
+
+func (m *Message) InProgress(_ context.Context) error {
+	return nil
+}
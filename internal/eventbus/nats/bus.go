@@ -0,0 +1,193 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/eventbus"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	defaultMaxAge     = 7 * 24 * time.Hour
+	defaultMaxBytes   = 1024 * 1024 * 100 // 100MB
+	defaultAckWait    = 30 * time.Second
+	defaultRetryDelay = time.Second
+)
+
+var durableNameReplacer = strings.NewReplacer(".", "_", "*", "_", ">", "_", " ", "_")
+
+// Bus is an event bus on a JetStream stream. Every topic is a subject "<stream>.<topic>",
+// and every consumer is durable, so events survive restarts and are redelivered until acked.
+type Bus struct {
+	js         jetstream.JetStream
+	stream     jetstream.Stream
+	streamName string
+
+	mu        sync.Mutex
+	consumers []jetstream.MessagesContext
+	closed    bool
+}
+
+func New(ctx context.Context, conn *nats.Conn, streamName string, replicas int) (*Bus, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream: %w", err)
+	}
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:     streamName,
+		Subjects: []string{streamName + ".>"},
+		Storage:  jetstream.FileStorage,
+		Replicas: replicas,
+		MaxAge:   defaultMaxAge,
+		MaxBytes: defaultMaxBytes,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update stream: %w", err)
+	}
+	return &Bus{
+		js:         js,
+		stream:     stream,
+		streamName: streamName,
+	}, nil
+}
+
+func (b *Bus) Publish(ctx context.Context, topic string, data any) error {
+	if b.isClosed() {
+		return eventbus.ErrBusClosed
+	}
+
+	dataBytes, err := json.Marshal(data)
+	if err != nil {
+		return fmt.Errorf("failed to json marshal data: %w", err)
+	}
+
+	if _, err := b.js.Publish(ctx, b.subject(topic), dataBytes); err != nil {
+		return fmt.Errorf("failed to publish event: %w", err)
+	}
+	return nil
+}
+
+// NewConsumer creates or resumes the durable consumer of the subscriber. Consumers with the same
+// subscriber share one durable consumer, so JetStream spreads the events between them.
+func (b *Bus) NewConsumer(ctx context.Context, topic, subscriber string) (eventbus.IEventConsumer, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return nil, eventbus.ErrBusClosed
+	}
+
+	consumer, err := b.stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       durableName(topic, subscriber),
+		FilterSubject: b.subject(topic),
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       defaultAckWait,
+		DeliverPolicy: jetstream.DeliverAllPolicy,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update consumer: %w", err)
+	}
+
+	messages, err := consumer.Messages()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start consuming messages: %w", err)
+	}
+	b.consumers = append(b.consumers, messages)
+
+	return &Consumer{messages: messages}, nil
+}
+
+func (b *Bus) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, messages := range b.consumers {
+		messages.Stop()
+	}
+	b.consumers = nil
+	return nil
+}
+
+func (b *Bus) isClosed() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	return b.closed
+}
+
+// durableName keeps the topic-only name for the unnamed subscriber, so existing durable consumers
+// are resumed instead of replaying the stream.
+func durableName(topic, subscriber string) string {
+	if subscriber == "" {
+		return durableNameReplacer.Replace(topic)
+	}
+	return durableNameReplacer.Replace(topic + "__" + subscriber)
+}
+
+func (b *Bus) subject(topic string) string {
+	return fmt.Sprintf("%s.%s", b.streamName, topic)
+}
+
+type Consumer struct {
+	messages jetstream.MessagesContext
+}
+
+func (c *Consumer) Consume(ctx context.Context) (eventbus.IEventMessage, bool) {
+	for {
+		msg, err := c.messages.Next(jetstream.NextContext(ctx))
+		if err == nil {
+			return &Message{msg: msg}, true
+		}
+		if errors.Is(err, jetstream.ErrMsgIteratorClosed) || ctx.Err() != nil {
+			return nil, false
+		}
+
+		slog.WarnContext(ctx, "Failed to get next event, retrying", logger.ErrorField, err.Error())
+		select {
+		case <-ctx.Done():
+			return nil, false
+		case <-time.After(defaultRetryDelay):
+		}
+	}
+}
+
+type Message struct {
+	msg jetstream.Msg
+}
+
+func (m *Message) Data() []byte {
+	return m.msg.Data()
+}
+
+func (m *Message) Delivered() int {
+	metadata, err := m.msg.Metadata()
+	if err != nil {
+		return 1
+	}
+	return int(metadata.NumDelivered)
+}
+
+func (m *Message) Ack(ctx context.Context) error {
+	return m.msg.DoubleAck(ctx)
+}
+
+func (m *Message) Nack(_ context.Context, delay time.Duration) error {
+	if delay <= 0 {
+		return m.msg.Nak()
+	}
+	return m.msg.NakWithDelay(delay)
+}
+
+func (m *Message) InProgress(_ context.Context) error {
+	return m.msg.InProgress()
+}
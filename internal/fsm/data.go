@@ -0,0 +1,106 @@
+package fsm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+var (
+	ErrDataBagNotFound = errors.New("fsm data bag not found in context")
+)
+
+// Data is a JSON-serialised payload stored alongside the user state.
+// It is cleared when the user returns to StateIdle.
+type Data []byte
+
+// DataBag holds the user's state payload while an update is being handled.
+// Handlers read and write it with LoadData and StoreData, the router persists it together with the next state.
+type DataBag struct {
+	mu    sync.Mutex
+	data  Data
+	dirty bool
+}
+
+func NewDataBag(data Data) *DataBag {
+	return &DataBag{data: data}
+}
+
+func (b *DataBag) Data() Data {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.data
+}
+
+// Dirty reports whether the payload was changed since the bag was created.
+func (b *DataBag) Dirty() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.dirty
+}
+
+func (b *DataBag) set(data Data) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.data = data
+	b.dirty = true
+}
+
+type dataBagKey struct{}
+
+func WithDataBag(ctx context.Context, bag *DataBag) context.Context {
+	return context.WithValue(ctx, dataBagKey{}, bag)
+}
+
+func DataBagFromContext(ctx context.Context) (*DataBag, bool) {
+	bag, ok := ctx.Value(dataBagKey{}).(*DataBag)
+	return bag, ok
+}
+
+// LoadData decodes the user's state payload into T.
+// The second return value is false if there is no payload.
+func LoadData[T any](ctx context.Context) (T, bool, error) {
+	var zero T
+	bag, ok := DataBagFromContext(ctx)
+	if !ok {
+		return zero, false, ErrDataBagNotFound
+	}
+
+	data := bag.Data()
+	if len(data) == 0 {
+		return zero, false, nil
+	}
+
+	var typedData T
+	if err := json.Unmarshal(data, &typedData); err != nil {
+		return zero, false, fmt.Errorf("failed to json unmarshal fsm data: %w", err)
+	}
+	return typedData, true, nil
+}
+
+// StoreData replaces the user's state payload with value.
+func StoreData[T any](ctx context.Context, value T) error {
+	bag, ok := DataBagFromContext(ctx)
+	if !ok {
+		return ErrDataBagNotFound
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to json marshal fsm data: %w", err)
+	}
+	bag.set(data)
+	return nil
+}
+
+// ClearData removes the user's state payload.
+func ClearData(ctx context.Context) error {
+	bag, ok := DataBagFromContext(ctx)
+	if !ok {
+		return ErrDataBagNotFound
+	}
+	bag.set(nil)
+	return nil
+}
@@ -0,0 +1,65 @@
+package fsm
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testPayload struct {
+	RequestID string   `json:"request_id"`
+	Answers   []string `json:"answers"`
+}
+
+func TestData_StoreAndLoad(t *testing.T) {
+	bag := NewDataBag(nil)
+	ctx := WithDataBag(context.Background(), bag)
+
+	_, ok, err := LoadData[testPayload](ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+	assert.False(t, bag.Dirty())
+
+	payload := testPayload{RequestID: "42", Answers: []string{"Steve"}}
+	require.NoError(t, StoreData(ctx, payload))
+	assert.True(t, bag.Dirty())
+
+	loaded, ok, err := LoadData[testPayload](ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, payload, loaded)
+
+	require.NoError(t, ClearData(ctx))
+	assert.Empty(t, bag.Data())
+	_, ok, err = LoadData[testPayload](ctx)
+	require.NoError(t, err)
+	assert.False(t, ok)
+}
+
+func TestData_LoadExisting(t *testing.T) {
+	ctx := WithDataBag(context.Background(), NewDataBag(Data(`{"request_id":"7"}`)))
+
+	loaded, ok, err := LoadData[testPayload](ctx)
+	require.NoError(t, err)
+	assert.True(t, ok)
+	assert.Equal(t, "7", loaded.RequestID)
+}
+
+func TestData_NoBag(t *testing.T) {
+	ctx := context.Background()
+
+	_, _, err := LoadData[testPayload](ctx)
+	require.ErrorIs(t, err, ErrDataBagNotFound)
+	require.ErrorIs(t, StoreData(ctx, testPayload{}), ErrDataBagNotFound)
+	require.ErrorIs(t, ClearData(ctx), ErrDataBagNotFound)
+}
+
+func TestData_InvalidPayload(t *testing.T) {
+	ctx := WithDataBag(context.Background(), NewDataBag(Data(`not json`)))
+
+	_, ok, err := LoadData[testPayload](ctx)
+	require.Error(t, err)
+	assert.False(t, ok)
+}
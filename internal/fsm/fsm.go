@@ -1,6 +1,10 @@
 package fsm
 
-import domainUser "whitelist-bot/internal/domain/user"
+import (
+	"context"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+)
 
 type State string
 
@@ -13,6 +17,19 @@ const (
 )
 
 type IFSM interface {
-	GetState(userID domainUser.ID) (State, error)
-	SetState(userID domainUser.ID, state State) error
+	GetState(ctx context.Context, userID domainUser.ID) (State, Data, error)
+	SetState(ctx context.Context, userID domainUser.ID, state State, data Data) error
+}
+
+// StateTTL defines how long a state stays valid after it was set.
+// Expired states fall back to StateIdle. States without TTL never expire.
+type StateTTL map[State]time.Duration
+
+// ExpiresAt returns the expiration time of the state set at now, or zero time if the state never expires.
+func (t StateTTL) ExpiresAt(state State, now time.Time) time.Time {
+	ttl, ok := t[state]
+	if !ok || ttl <= 0 {
+		return time.Time{}
+	}
+	return now.Add(ttl)
 }
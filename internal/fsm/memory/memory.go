@@ -1,40 +1,67 @@
 package memory
 
 import (
+	"context"
 	"sync"
+	"time"
 	domainUser "whitelist-bot/internal/domain/user"
 	"whitelist-bot/internal/fsm"
 )
 
+type entry struct {
+	state     fsm.State
+	data      fsm.Data
+	expiresAt time.Time
+}
+
 type FSM struct {
-	states map[domainUser.ID]fsm.State
+	states map[domainUser.ID]entry
+	ttl    fsm.StateTTL
 	mu     sync.RWMutex
 }
 
-// WARNING: This FSM can potentially cause memory leaks with large number of users.
-// User different FSM implementations with TTL.
-func New() *FSM {
+// WARNING: This FSM loses all states on restart.
+// Use persistent FSM implementations (nats, postgres) in production.
+func New(ttl fsm.StateTTL) *FSM {
 	return &FSM{
-		states: make(map[domainUser.ID]fsm.State),
+		states: make(map[domainUser.ID]entry),
+		ttl:    ttl,
 	}
 }
 
-func (f *FSM) GetState(userID domainUser.ID) (fsm.State, error) {
+func (f *FSM) GetState(_ context.Context, userID domainUser.ID) (fsm.State, fsm.Data, error) {
 	f.mu.RLock()
-	defer f.mu.RUnlock()
+	e, ok := f.states[userID]
+	f.mu.RUnlock()
 
-	state, ok := f.states[userID]
 	if !ok {
-		return fsm.StateIdle, nil
+		return fsm.StateIdle, nil, nil
+	}
+	if !e.expiresAt.IsZero() && time.Now().After(e.expiresAt) {
+		f.mu.Lock()
+		if current, ok := f.states[userID]; ok && current.expiresAt.Equal(e.expiresAt) {
+			delete(f.states, userID)
+		}
+		f.mu.Unlock()
+		return fsm.StateIdle, nil, nil
 	}
 
-	return state, nil
+	return e.state, e.data, nil
 }
 
-func (f *FSM) SetState(userID domainUser.ID, state fsm.State) error {
+func (f *FSM) SetState(_ context.Context, userID domainUser.ID, state fsm.State, data fsm.Data) error {
 	f.mu.Lock()
 	defer f.mu.Unlock()
 
-	f.states[userID] = state
+	if state == fsm.StateIdle {
+		delete(f.states, userID)
+		return nil
+	}
+
+	f.states[userID] = entry{
+		state:     state,
+		data:      data,
+		expiresAt: f.ttl.ExpiresAt(state, time.Now()),
+	}
 	return nil
 }
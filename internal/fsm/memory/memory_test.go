@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/fsm"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSM_GetState_DefaultIdle(t *testing.T) {
+	f := New(nil)
+
+	state, _, err := f.GetState(context.Background(), domainUser.ID(uuid.New()))
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateIdle, state)
+}
+
+func TestFSM_SetState(t *testing.T) {
+	ctx := context.Background()
+	f := New(nil)
+	userID := domainUser.ID(uuid.New())
+
+	require.NoError(t, f.SetState(ctx, userID, fsm.StateWaitingWLNickname, fsm.Data(`{"step":1}`)))
+	state, data, err := f.GetState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateWaitingWLNickname, state)
+	assert.JSONEq(t, `{"step":1}`, string(data))
+
+	require.NoError(t, f.SetState(ctx, userID, fsm.StateIdle, fsm.Data(`{"step":2}`)))
+	state, data, err = f.GetState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateIdle, state)
+	assert.Empty(t, data)
+	assert.Empty(t, f.states)
+}
+
+func TestFSM_StateTTL(t *testing.T) {
+	ctx := context.Background()
+	f := New(fsm.StateTTL{fsm.StateWaitingWLNickname: 20 * time.Millisecond})
+	userID := domainUser.ID(uuid.New())
+
+	require.NoError(t, f.SetState(ctx, userID, fsm.StateWaitingWLNickname, nil))
+	state, _, err := f.GetState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateWaitingWLNickname, state)
+
+	time.Sleep(30 * time.Millisecond)
+
+	state, _, err = f.GetState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateIdle, state)
+	assert.Empty(t, f.states)
+}
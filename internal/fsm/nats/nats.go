@@ -0,0 +1,88 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/metastore"
+)
+
+const stateKey = "fsm_state"
+
+type iStore interface {
+	Get(ctx context.Context, uniqueID string, key string) ([]byte, error)
+	SetWithTTL(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error
+	Delete(ctx context.Context, uniqueID string, key string) error
+}
+
+type record struct {
+	State     fsm.State       `json:"state"`
+	Data      json.RawMessage `json:"data,omitempty"`
+	ExpiresAt time.Time       `json:"expires_at"`
+}
+
+// FSM keeps user states in the NATS KV metastore bucket.
+// Idle states are not stored at all, so the bucket only holds active conversations.
+type FSM struct {
+	store iStore
+	ttl   fsm.StateTTL
+}
+
+func New(store iStore, ttl fsm.StateTTL) *FSM {
+	return &FSM{
+		store: store,
+		ttl:   ttl,
+	}
+}
+
+func (f *FSM) GetState(ctx context.Context, userID domainUser.ID) (fsm.State, fsm.Data, error) {
+	data, err := f.store.Get(ctx, userID.String(), stateKey)
+	if errors.Is(err, metastore.ErrKeyNotFound) {
+		return fsm.StateIdle, nil, nil
+	}
+	if err != nil {
+		return fsm.StateIdle, nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	var r record
+	if err := json.Unmarshal(data, &r); err != nil {
+		return fsm.StateIdle, nil, fmt.Errorf("failed to json unmarshal state: %w", err)
+	}
+
+	if !r.ExpiresAt.IsZero() && time.Now().After(r.ExpiresAt) {
+		if err := f.store.Delete(ctx, userID.String(), stateKey); err != nil {
+			return fsm.StateIdle, nil, fmt.Errorf("failed to delete expired state: %w", err)
+		}
+		return fsm.StateIdle, nil, nil
+	}
+
+	return r.State, fsm.Data(r.Data), nil
+}
+
+func (f *FSM) SetState(ctx context.Context, userID domainUser.ID, state fsm.State, data fsm.Data) error {
+	if state == fsm.StateIdle {
+		if err := f.store.Delete(ctx, userID.String(), stateKey); err != nil {
+			return fmt.Errorf("failed to delete state: %w", err)
+		}
+		return nil
+	}
+
+	now := time.Now()
+	r := record{
+		State:     state,
+		Data:      json.RawMessage(data),
+		ExpiresAt: f.ttl.ExpiresAt(state, now),
+	}
+	var ttl time.Duration
+	if !r.ExpiresAt.IsZero() {
+		ttl = r.ExpiresAt.Sub(now)
+	}
+	if err := f.store.SetWithTTL(ctx, userID.String(), stateKey, r, ttl); err != nil {
+		return fmt.Errorf("failed to set state: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,54 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/fsm"
+	memoryMetastore "whitelist-bot/internal/metastore/memory"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFSM_SetState_GetState(t *testing.T) {
+	ctx := context.Background()
+	store := memoryMetastore.New("test")
+	f := New(store, nil)
+	userID := domainUser.ID(uuid.New())
+
+	state, _, err := f.GetState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateIdle, state)
+
+	require.NoError(t, f.SetState(ctx, userID, fsm.StateWaitingWLNickname, fsm.Data(`{"step":1}`)))
+	state, data, err := f.GetState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateWaitingWLNickname, state)
+	assert.JSONEq(t, `{"step":1}`, string(data))
+
+	require.NoError(t, f.SetState(ctx, userID, fsm.StateIdle, nil))
+	exists, err := store.Exists(ctx, userID.String(), stateKey)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
+
+func TestFSM_StateTTL(t *testing.T) {
+	ctx := context.Background()
+	store := memoryMetastore.New("test")
+	f := New(store, fsm.StateTTL{fsm.StateWaitingWLNickname: 20 * time.Millisecond})
+	userID := domainUser.ID(uuid.New())
+
+	require.NoError(t, f.SetState(ctx, userID, fsm.StateWaitingWLNickname, nil))
+	time.Sleep(30 * time.Millisecond)
+
+	state, _, err := f.GetState(ctx, userID)
+	require.NoError(t, err)
+	assert.Equal(t, fsm.StateIdle, state)
+
+	exists, err := store.Exists(ctx, userID.String(), stateKey)
+	require.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,88 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/fsm"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type iQueryable interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+}
+
+// FSM keeps user states in the fsm_states table.
+// Idle states are not stored at all, so the table only holds active conversations.
+type FSM struct {
+	db  iQueryable
+	ttl fsm.StateTTL
+}
+
+func NewFSM(db iQueryable, ttl fsm.StateTTL) *FSM {
+	return &FSM{
+		db:  db,
+		ttl: ttl,
+	}
+}
+
+func (f *FSM) GetState(ctx context.Context, userID domainUser.ID) (fsm.State, fsm.Data, error) {
+	q := New(f.db)
+
+	dbState, err := q.FSMStateByUserID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fsm.StateIdle, nil, nil
+		}
+		return fsm.StateIdle, nil, fmt.Errorf("failed to get state: %w", err)
+	}
+
+	if dbState.ExpiresAt != nil && time.Now().After(*dbState.ExpiresAt) {
+		if err := q.DeleteFSMState(ctx, userID); err != nil {
+			return fsm.StateIdle, nil, fmt.Errorf("failed to delete expired state: %w", err)
+		}
+		return fsm.StateIdle, nil, nil
+	}
+
+	return dbState.State, fsm.Data(dbState.Data), nil
+}
+
+func (f *FSM) SetState(ctx context.Context, userID domainUser.ID, state fsm.State, data fsm.Data) error {
+	q := New(f.db)
+
+	if state == fsm.StateIdle {
+		if err := q.DeleteFSMState(ctx, userID); err != nil {
+			return fmt.Errorf("failed to delete state: %w", err)
+		}
+		return nil
+	}
+
+	if len(data) == 0 {
+		data = nil
+	}
+
+	now := time.Now()
+	var expiresAt *time.Time
+	if t := f.ttl.ExpiresAt(state, now); !t.IsZero() {
+		expiresAt = &t
+	}
+
+	err := q.UpsertFSMState(ctx, UpsertFSMStateParams{
+		UserID:    userID,
+		State:     state,
+		Data:      data,
+		ExpiresAt: expiresAt,
+		UpdatedAt: now,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to upsert state: %w", err)
+	}
+	return nil
+}
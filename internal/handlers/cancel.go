@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/msgs"
 	"whitelist-bot/internal/router"
 
@@ -14,7 +15,7 @@ func Cancel() router.HandlerFunc {
 	return func(ctx context.Context, b *bot.Bot, update *models.Update, _ fsm.State) (fsm.State, router.Response, error) {
 		response := router.NewMessageResponse(
 			&bot.SendMessageParams{
-				Text: msgs.Cancel(),
+				Text: msgs.Cancel(i18n.LangFromContext(ctx)),
 			},
 		)
 		return fsm.StateIdle, response, nil
@@ -0,0 +1,125 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/router"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const maxDeadLettersList = 10
+
+// DeadLetters lets admins manage events that failed all retries:
+// /dead_letters [show <id> | replay <id> | purge [id]].
+func DeadLetters(deadLetterRepo iDeadLetterRepository, ep iEventPublisher) router.HandlerFunc {
+	return func(ctx context.Context, _ *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+		lang := i18n.LangFromContext(ctx)
+		cmd, _ := router.CommandFromContext(ctx)
+		args := cmd.Args
+
+		var text string
+		var err error
+		switch {
+		case len(args) == 0:
+			text, err = listDeadLetters(ctx, lang, deadLetterRepo)
+		case len(args) == 2 && args[0] == "show":
+			text, err = showDeadLetter(ctx, lang, deadLetterRepo, args[1])
+		case len(args) == 2 && args[0] == "replay":
+			text, err = replayDeadLetter(ctx, lang, deadLetterRepo, ep, args[1])
+		case len(args) == 1 && args[0] == "purge":
+			text, err = purgeAllDeadLetters(ctx, lang, deadLetterRepo)
+		case len(args) == 2 && args[0] == "purge":
+			text, err = purgeDeadLetter(ctx, lang, deadLetterRepo, args[1])
+		default:
+			text = msgs.DeadLettersUsage(lang)
+		}
+		if err != nil {
+			return state, nil, err
+		}
+
+		response := router.NewMessageResponse(&bot.SendMessageParams{
+			Text: text,
+		})
+		return state, response, nil
+	}
+}
+
+func listDeadLetters(ctx context.Context, lang i18n.Lang, repo iDeadLetterRepository) (string, error) {
+	deadLetters, err := repo.DeadLetters(ctx, maxDeadLettersList)
+	if err != nil {
+		return "", fmt.Errorf("failed to get dead letters: %w", err)
+	}
+	return msgs.DeadLetters(lang, deadLetters), nil
+}
+
+func showDeadLetter(ctx context.Context, lang i18n.Lang, repo iDeadLetterRepository, rawID string) (string, error) {
+	id, err := parseDeadLetterID(rawID)
+	if err != nil {
+		return "", err
+	}
+	deadLetter, err := repo.DeadLetterByID(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	return msgs.DeadLetterDetails(lang, deadLetter), nil
+}
+
+func replayDeadLetter(
+	ctx context.Context,
+	lang i18n.Lang,
+	repo iDeadLetterRepository,
+	ep iEventPublisher,
+	rawID string,
+) (string, error) {
+	id, err := parseDeadLetterID(rawID)
+	if err != nil {
+		return "", err
+	}
+	deadLetter, err := repo.DeadLetterByID(ctx, id)
+	if err != nil {
+		return "", fmt.Errorf("failed to get dead letter: %w", err)
+	}
+	// Data is already JSON, publish it as is.
+	if err := ep.Publish(ctx, deadLetter.Topic, json.RawMessage(deadLetter.Data)); err != nil {
+		return "", fmt.Errorf("failed to replay dead letter: %w", err)
+	}
+	if err := repo.DeleteDeadLetter(ctx, id); err != nil {
+		return "", fmt.Errorf("failed to delete replayed dead letter: %w", err)
+	}
+	return msgs.DeadLetterReplayed(lang, deadLetter), nil
+}
+
+func purgeDeadLetter(ctx context.Context, lang i18n.Lang, repo iDeadLetterRepository, rawID string) (string, error) {
+	id, err := parseDeadLetterID(rawID)
+	if err != nil {
+		return "", err
+	}
+	if err := repo.DeleteDeadLetter(ctx, id); err != nil {
+		return "", fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+	return msgs.DeadLettersPurged(lang, 1), nil
+}
+
+func purgeAllDeadLetters(ctx context.Context, lang i18n.Lang, repo iDeadLetterRepository) (string, error) {
+	deleted, err := repo.DeleteAllDeadLetters(ctx)
+	if err != nil {
+		return "", fmt.Errorf("failed to delete dead letters: %w", err)
+	}
+	return msgs.DeadLettersPurged(lang, deleted), nil
+}
+
+func parseDeadLetterID(rawID string) (utils.UniqueID, error) {
+	id, err := utils.UUIDFromString[utils.UniqueID](rawID)
+	if err != nil {
+		return utils.UniqueID{}, fmt.Errorf("%w: %w", core.ErrFailedToParseID, err)
+	}
+	return id, nil
+}
@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/eventbus"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/router"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeadLetters(t *testing.T) {
+	t.Parallel()
+
+	deadLetter := eventbus.DeadLetter{
+		ID:       utils.NewUniqueID(),
+		Topic:    core.TopicWLRequestCreated,
+		Data:     []byte(`{"id":"<event>"}`),
+		Error:    "telegram is down",
+		Attempts: 5,
+		FailedAt: time.Now(),
+	}
+
+	tests := []struct {
+		name          string
+		text          string
+		setupMock     func(*mockiDeadLetterRepository, *mockiEventPublisher)
+		expectedError error
+		expectedText  string
+	}{
+		{
+			name: "list",
+			text: "/dead_letters",
+			setupMock: func(r *mockiDeadLetterRepository, _ *mockiEventPublisher) {
+				r.EXPECT().DeadLetters(mock.Anything, int64(maxDeadLettersList)).Return([]eventbus.DeadLetter{deadLetter}, nil).Once()
+			},
+			expectedText: deadLetter.ID.String(),
+		},
+		{
+			name: "list_empty",
+			text: "/dead_letters",
+			setupMock: func(r *mockiDeadLetterRepository, _ *mockiEventPublisher) {
+				r.EXPECT().DeadLetters(mock.Anything, int64(maxDeadLettersList)).Return(nil, nil).Once()
+			},
+			expectedText: "Недоставленных событий нет",
+		},
+		{
+			name: "show",
+			text: "/dead_letters show " + deadLetter.ID.String(),
+			setupMock: func(r *mockiDeadLetterRepository, _ *mockiEventPublisher) {
+				r.EXPECT().DeadLetterByID(mock.Anything, deadLetter.ID).Return(deadLetter, nil).Once()
+			},
+			expectedText: "&lt;event&gt;",
+		},
+		{
+			name:          "show_invalid_id",
+			text:          "/dead_letters show 42",
+			setupMock:     func(_ *mockiDeadLetterRepository, _ *mockiEventPublisher) {},
+			expectedError: core.ErrFailedToParseID,
+		},
+		{
+			name: "show_not_found",
+			text: "/dead_letters show " + deadLetter.ID.String(),
+			setupMock: func(r *mockiDeadLetterRepository, _ *mockiEventPublisher) {
+				r.EXPECT().DeadLetterByID(mock.Anything, deadLetter.ID).Return(eventbus.DeadLetter{}, core.ErrDeadLetterNotFound).Once()
+			},
+			expectedError: core.ErrDeadLetterNotFound,
+		},
+		{
+			name: "replay",
+			text: "/dead_letters replay " + deadLetter.ID.String(),
+			setupMock: func(r *mockiDeadLetterRepository, p *mockiEventPublisher) {
+				r.EXPECT().DeadLetterByID(mock.Anything, deadLetter.ID).Return(deadLetter, nil).Once()
+				p.EXPECT().
+					Publish(mock.Anything, deadLetter.Topic, mock.MatchedBy(func(data any) bool {
+						raw, ok := data.(json.RawMessage)
+						return ok && string(raw) == string(deadLetter.Data)
+					})).
+					Return(nil).
+					Once()
+				r.EXPECT().DeleteDeadLetter(mock.Anything, deadLetter.ID).Return(nil).Once()
+			},
+			expectedText: core.TopicWLRequestCreated,
+		},
+		{
+			name: "replay_publish_error",
+			text: "/dead_letters replay " + deadLetter.ID.String(),
+			setupMock: func(r *mockiDeadLetterRepository, p *mockiEventPublisher) {
+				r.EXPECT().DeadLetterByID(mock.Anything, deadLetter.ID).Return(deadLetter, nil).Once()
+				p.EXPECT().Publish(mock.Anything, deadLetter.Topic, mock.Anything).Return(errors.New("bus is down")).Once()
+			},
+			expectedError: errors.New("failed to replay dead letter: bus is down"),
+		},
+		{
+			name: "purge_one",
+			text: "/dead_letters purge " + deadLetter.ID.String(),
+			setupMock: func(r *mockiDeadLetterRepository, _ *mockiEventPublisher) {
+				r.EXPECT().DeleteDeadLetter(mock.Anything, deadLetter.ID).Return(nil).Once()
+			},
+			expectedText: "1",
+		},
+		{
+			name: "purge_all",
+			text: "/dead_letters purge",
+			setupMock: func(r *mockiDeadLetterRepository, _ *mockiEventPublisher) {
+				r.EXPECT().DeleteAllDeadLetters(mock.Anything).Return(int64(3), nil).Once()
+			},
+			expectedText: "3",
+		},
+		{
+			name:         "usage",
+			text:         "/dead_letters replay",
+			setupMock:    func(_ *mockiDeadLetterRepository, _ *mockiEventPublisher) {},
+			expectedText: "/dead_letters purge [id]",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := newMockiDeadLetterRepository(t)
+			mockPublisher := newMockiEventPublisher(t)
+			tt.setupMock(mockRepo, mockPublisher)
+
+			handler := DeadLetters(mockRepo, mockPublisher)
+
+			update := &models.Update{
+				Message: &models.Message{
+					Text: tt.text,
+					From: &models.User{ID: 1},
+				},
+			}
+
+			ctx := withCommand(t, context.Background(), router.UpdateContext{State: fsm.StateIdle}, tt.text)
+			state, response, err := handler(ctx, nil, update, fsm.StateIdle)
+
+			assert.Equal(t, fsm.StateIdle, state)
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.expectedError.Error())
+				assert.Nil(t, response)
+				return
+			}
+			require.NoError(t, err)
+			msgResponse, ok := response.(*router.MessageResponse)
+			require.True(t, ok)
+			require.Len(t, msgResponse.Params, 1)
+			assert.Contains(t, msgResponse.Params[0].Text, tt.expectedText)
+		})
+	}
+}
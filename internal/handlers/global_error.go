@@ -6,28 +6,42 @@ import (
 	"log/slog"
 	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/eventbus"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/router"
+
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
-
-	domainUser "whitelist-bot/internal/domain/user"
 )
 
-var (
-	ErrUnknownCommandMessage   = "Неизвестная команда"
-	ErrInternalErrorMessage    = "Произошла ошибка при обработке команды"
-	ErrInvalidUserStateMessage = "Неверное состояние пользователя"
-	ErrUsernameHiddenMessage   = "Имя пользователя скрыто. Бота нельзя использовать со скрытым username."
-)
+var errorStatusMap = map[error]i18n.Key{
+	core.ErrUnknownCommand:     i18n.ErrTextUnknownCommand,
+	core.ErrInvalidUserState:   i18n.ErrTextInvalidUserState,
+	core.ErrUserBusy:           i18n.ErrTextUserBusy,
+	core.ErrTooManyRequests:    i18n.ErrTextTooManyRequests,
+	core.ErrPermissionDenied:   i18n.ErrTextPermissionDenied,
+	core.ErrMaintenance:        i18n.ErrTextMaintenance,
+	core.ErrDeadLetterNotFound: i18n.ErrTextDeadLetterNotFound,
+	core.ErrFailedToParseID:    i18n.ErrTextInvalidID,
+	core.ErrInvalidCallback:    i18n.ErrTextInvalidCallbackData,
+	core.ErrCallbackExpired:    i18n.ErrTextCallbackExpired,
+	core.ErrWLRequestNotFound:  i18n.ErrTextWLRequestNotFound,
+	core.ErrInvalidLength:      i18n.ErrTextInvalidLength,
 
-var errorStatusMap = map[error]string{
-	core.ErrUnknownCommand:         ErrUnknownCommandMessage,
-	core.ErrInvalidUserState:       ErrInvalidUserStateMessage,
-	domainUser.ErrUsernameRequired: ErrUsernameHiddenMessage,
+	// Only direct publishers, like the dead letter replay, see it. Submissions go through the outbox,
+	// whose relay publishes the rejected events again after the claim expires.
+	eventbus.ErrBufferFull: i18n.ErrTextOverloaded,
+
+	domainWLRequest.ErrCantApproveNonPendingWLRequest: i18n.ErrTextWLRequestResolved,
+	domainWLRequest.ErrCantDeclineNonPendingWLRequest: i18n.ErrTextWLRequestResolved,
+	domainWLRequest.ErrCantRevokeNonApprovedWLRequest: i18n.ErrTextWLRequestUnapproved,
 }
 
-func GlobalErrorHandler() func(ctx context.Context, b *bot.Bot, update *models.Update, err error) {
-	getCustomErrorMessage := func(target error) string {
+func GlobalErrorHandler() router.ErrorHandlerFunc {
+	getCustomErrorMessage := func(target error) i18n.Key {
 		for err, message := range errorStatusMap {
 			if errors.Is(target, err) {
 				return message
@@ -36,19 +50,25 @@ func GlobalErrorHandler() func(ctx context.Context, b *bot.Bot, update *models.U
 		return ""
 	}
 
-	return func(ctx context.Context, b *bot.Bot, update *models.Update, err error) {
+	return func(ctx context.Context, sender utils.IMessageSender, update *models.Update, err error) {
 		slog.ErrorContext(ctx, "Failed to handle update", logger.ErrorField, err.Error())
+		lang := i18n.LangFromContext(ctx)
 		customMsg := getCustomErrorMessage(err)
 		switch {
 		case customMsg != "" && update.Message != nil:
-			b.SendMessage(ctx, &bot.SendMessageParams{
+			sender.SendMessage(ctx, &bot.SendMessageParams{
 				ChatID: update.Message.Chat.ID,
-				Text:   customMsg,
+				Text:   i18n.T(lang, customMsg),
+			})
+		case customMsg != "" && update.CallbackQuery != nil:
+			sender.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+				CallbackQueryID: update.CallbackQuery.ID,
+				Text:            i18n.T(lang, customMsg),
 			})
 		case update.Message != nil:
-			b.SendMessage(ctx, &bot.SendMessageParams{
+			sender.SendMessage(ctx, &bot.SendMessageParams{
 				ChatID: update.Message.Chat.ID,
-				Text:   ErrInternalErrorMessage,
+				Text:   i18n.T(lang, i18n.ErrTextInternalError),
 			})
 		default:
 			return
@@ -3,21 +3,21 @@ package handlers
 import (
 	"context"
 
+	"whitelist-bot/internal/callbacks"
 	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/utils"
 	domainUser "whitelist-bot/internal/domain/user"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/eventbus"
 	"whitelist-bot/internal/metastore"
 	repository "whitelist-bot/internal/repository/wl_request"
+	"whitelist-bot/internal/scheduler"
 )
 
-type iUserGetter interface {
-	UserByTelegramID(ctx context.Context, telegramID int64) (domainUser.User, error)
-	UserByID(ctx context.Context, id domainUser.ID) (domainUser.User, error)
-}
-
 type iUserRepository interface {
-	UserByTelegramID(ctx context.Context, telegramID int64) (domainUser.User, error)
 	UserByID(ctx context.Context, id domainUser.ID) (domainUser.User, error)
+	UpdateUser(ctx context.Context, user domainUser.User) (domainUser.User, error)
+	PreviousUsernames(ctx context.Context, user domainUser.User, limit int64) ([]domainUser.Username, error)
 }
 
 type iWLRequestRepository interface {
@@ -25,11 +25,37 @@ type iWLRequestRepository interface {
 		ctx context.Context,
 		requesterID domainWLRequest.RequesterID,
 		nickname domainWLRequest.Nickname,
+		events ...repository.WLRequestEvent,
 	) (domainWLRequest.WLRequest, error)
 	PendingWLRequests(ctx context.Context, limit int64) ([]domainWLRequest.WLRequest, error)
 	PendingWLRequestsWithRequester(ctx context.Context, limit int64) ([]repository.PendingWLRequestWithRequester, error)
 	WLRequestByID(ctx context.Context, id domainWLRequest.ID) (domainWLRequest.WLRequest, error)
-	UpdateWLRequest(ctx context.Context, wlRequest domainWLRequest.WLRequest) (domainWLRequest.WLRequest, error)
+	WLRequestsByNickname(
+		ctx context.Context,
+		nickname domainWLRequest.Nickname,
+		status domainWLRequest.Status,
+		limit int64,
+	) ([]domainWLRequest.WLRequest, error)
+	UpdateWLRequest(ctx context.Context, wlRequest domainWLRequest.WLRequest, from domainWLRequest.Status) (domainWLRequest.WLRequest, error)
+}
+
+type iDeadLetterRepository interface {
+	DeadLetters(ctx context.Context, limit int64) ([]eventbus.DeadLetter, error)
+	DeadLetterByID(ctx context.Context, id utils.UniqueID) (eventbus.DeadLetter, error)
+	DeleteDeadLetter(ctx context.Context, id utils.UniqueID) error
+	DeleteAllDeadLetters(ctx context.Context) (int64, error)
+}
+
+type iJobStatusProvider interface {
+	Statuses() []scheduler.Status
+}
+
+type iCallbackEncoder interface {
+	Encode(action callbacks.Action, payload []byte) (string, error)
+}
+
+type iEventPublisher interface {
+	Publish(ctx context.Context, topic string, data any) error
 }
 
 type Handlers struct {
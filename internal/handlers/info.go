@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/msgs"
 	"whitelist-bot/internal/router"
 
@@ -12,10 +13,7 @@ import (
 	"github.com/go-telegram/bot/models"
 )
 
-func Info(
-	userGetter iUserGetter,
-) router.HandlerFunc {
-
+func Info() router.HandlerFunc {
 	return func(
 		ctx context.Context,
 		b *bot.Bot,
@@ -26,14 +24,14 @@ func Info(
 			return currentState, nil, core.ErrInvalidUserState
 		}
 
-		user, err := userGetter.UserByTelegramID(ctx, update.Message.From.ID)
+		user, err := router.UserFromContext(ctx)
 		if err != nil {
 			return fsm.StateIdle, nil, fmt.Errorf("failed to get user: %w", err)
 		}
 
 		response := router.NewMessageResponse(
 			&bot.SendMessageParams{
-				Text: msgs.UserInfo(user),
+				Text: msgs.UserInfo(i18n.LangFromContext(ctx), user),
 			},
 		)
 		return fsm.StateIdle, response, nil
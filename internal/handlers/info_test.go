@@ -2,7 +2,7 @@ package handlers
 
 import (
 	"context"
-	"errors"
+	"strings"
 	"testing"
 	"time"
 	"whitelist-bot/internal/core"
@@ -19,26 +19,21 @@ import (
 func TestInfo(t *testing.T) {
 	t.Parallel()
 
-	ctx := context.Background()
 	testUser := createTestUser(t)
+	userCtx := router.WithUpdateContext(context.Background(), &router.UpdateContext{User: testUser, State: fsm.StateIdle})
 
 	tests := []struct {
 		name          string
+		ctx           context.Context
 		currentState  fsm.State
-		setupMock     func(*mockiUserGetter)
 		expectedState fsm.State
 		expectedError error
 		validateMsg   func(*testing.T, router.Response)
 	}{
 		{
-			name:         "success",
-			currentState: fsm.StateIdle,
-			setupMock: func(m *mockiUserGetter) {
-				m.EXPECT().
-					UserByTelegramID(ctx, int64(testUser.TelegramID())).
-					Return(testUser, nil).
-					Once()
-			},
+			name:          "success",
+			ctx:           userCtx,
+			currentState:  fsm.StateIdle,
 			expectedState: fsm.StateIdle,
 			expectedError: nil,
 			validateMsg: func(t *testing.T, response router.Response) {
@@ -52,8 +47,8 @@ func TestInfo(t *testing.T) {
 		},
 		{
 			name:          "invalid_state",
+			ctx:           userCtx,
 			currentState:  fsm.StateWaitingWLNickname,
-			setupMock:     func(m *mockiUserGetter) {},
 			expectedState: fsm.StateWaitingWLNickname,
 			expectedError: core.ErrInvalidUserState,
 			validateMsg: func(t *testing.T, response router.Response) {
@@ -61,31 +56,11 @@ func TestInfo(t *testing.T) {
 			},
 		},
 		{
-			name:         "repository_error",
-			currentState: fsm.StateIdle,
-			setupMock: func(m *mockiUserGetter) {
-				m.EXPECT().
-					UserByTelegramID(ctx, int64(testUser.TelegramID())).
-					Return(user.User{}, errors.New("db error")).
-					Once()
-			},
-			expectedState: fsm.StateIdle,
-			expectedError: errors.New("failed to get user: db error"),
-			validateMsg: func(t *testing.T, response router.Response) {
-				assert.Nil(t, response)
-			},
-		},
-		{
-			name:         "user_not_found",
-			currentState: fsm.StateIdle,
-			setupMock: func(m *mockiUserGetter) {
-				m.EXPECT().
-					UserByTelegramID(ctx, int64(testUser.TelegramID())).
-					Return(user.User{}, core.ErrUserNotFound).
-					Once()
-			},
+			name:          "no_update_context",
+			ctx:           context.Background(),
+			currentState:  fsm.StateIdle,
 			expectedState: fsm.StateIdle,
-			expectedError: core.ErrUserNotFound,
+			expectedError: router.ErrNoUpdateContext,
 			validateMsg: func(t *testing.T, response router.Response) {
 				assert.Nil(t, response)
 			},
@@ -96,10 +71,7 @@ func TestInfo(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			t.Parallel()
 
-			mockGetter := newMockiUserGetter(t)
-			tt.setupMock(mockGetter)
-
-			handler := Info(mockGetter)
+			handler := Info()
 
 			update := &models.Update{
 				Message: &models.Message{
@@ -112,13 +84,12 @@ func TestInfo(t *testing.T) {
 				},
 			}
 
-			state, response, err := handler(ctx, nil, update, tt.currentState)
+			state, response, err := handler(tt.ctx, nil, update, tt.currentState)
 
 			assert.Equal(t, tt.expectedState, state)
 
 			if tt.expectedError != nil {
-				require.Error(t, err)
-				assert.ErrorContains(t, err, tt.expectedError.Error())
+				require.ErrorIs(t, err, tt.expectedError)
 			} else {
 				require.NoError(t, err)
 			}
@@ -145,3 +116,19 @@ func createTestUser(t *testing.T) user.User {
 
 	return u
 }
+
+// withCommand puts the command the text starts with into the update context, as the router does.
+func withCommand(t *testing.T, ctx context.Context, updateCtx router.UpdateContext, text string) context.Context {
+	t.Helper()
+
+	name, _, _ := strings.Cut(text, " ")
+	msg := &models.Message{
+		Text:     text,
+		Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Length: len(name)}},
+	}
+	cmd, ok := router.ParseCommand(msg)
+	require.True(t, ok)
+	updateCtx.Command = &cmd
+
+	return router.WithUpdateContext(ctx, &updateCtx)
+}
@@ -0,0 +1,22 @@
+package handlers
+
+import (
+	"context"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/router"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Jobs shows admins the last run status of every scheduled job.
+func Jobs(jobs iJobStatusProvider) router.HandlerFunc {
+	return func(ctx context.Context, _ *bot.Bot, _ *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+		response := router.NewMessageResponse(&bot.SendMessageParams{
+			Text: msgs.Jobs(i18n.LangFromContext(ctx), jobs.Statuses()),
+		})
+		return state, response, nil
+	}
+}
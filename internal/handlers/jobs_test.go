@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/router"
+	"whitelist-bot/internal/scheduler"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		statuses      []scheduler.Status
+		expectedTexts []string
+	}{
+		{
+			name:          "no_jobs",
+			statuses:      nil,
+			expectedTexts: []string{"Фоновых задач нет"},
+		},
+		{
+			name: "never_run",
+			statuses: []scheduler.Status{
+				{Name: "outbox-cleanup", Schedule: "@hourly", NextRun: time.Now().Add(time.Hour)},
+			},
+			expectedTexts: []string{"outbox-cleanup", "@hourly", "Ещё не запускалась", "Следующий запуск"},
+		},
+		{
+			name: "failed",
+			statuses: []scheduler.Status{
+				{
+					Name:         "outbox-cleanup",
+					Schedule:     "@hourly",
+					Runs:         3,
+					Failures:     1,
+					LastRun:      time.Now(),
+					LastDuration: time.Second,
+					LastError:    "db <down>",
+				},
+			},
+			expectedTexts: []string{"запусков: 3, ошибок: 1", "db &lt;down&gt;"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockJobs := newMockiJobStatusProvider(t)
+			mockJobs.EXPECT().Statuses().Return(tt.statuses).Once()
+
+			update := &models.Update{
+				Message: &models.Message{
+					Text: "/jobs",
+					From: &models.User{ID: 1},
+				},
+			}
+
+			state, response, err := Jobs(mockJobs)(context.Background(), nil, update, fsm.StateIdle)
+
+			require.NoError(t, err)
+			assert.Equal(t, fsm.StateIdle, state)
+			msgResponse, ok := response.(*router.MessageResponse)
+			require.True(t, ok)
+			require.Len(t, msgResponse.Params, 1)
+			for _, text := range tt.expectedTexts {
+				assert.Contains(t, msgResponse.Params[0].Text, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/router"
+
+	domainUser "whitelist-bot/internal/domain/user"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Language shows available languages or switches the user's language: /language <code>.
+func Language(userRepo iUserRepository) router.HandlerFunc {
+	return func(ctx context.Context, _ *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+		lang := i18n.LangFromContext(ctx)
+
+		cmd, _ := router.CommandFromContext(ctx)
+		if len(cmd.Args) == 0 {
+			response := router.NewMessageResponse(&bot.SendMessageParams{
+				Text: msgs.LanguageUsage(lang),
+			})
+			return state, response, nil
+		}
+
+		newLang := i18n.Lang(strings.ToLower(cmd.Args[0]))
+		if !newLang.IsSupported() {
+			response := router.NewMessageResponse(&bot.SendMessageParams{
+				Text: msgs.LanguageUnsupported(lang, cmd.Args[0]),
+			})
+			return state, response, nil
+		}
+
+		user, err := router.UserFromContext(ctx)
+		if err != nil {
+			return state, nil, fmt.Errorf("failed to get user: %w", err)
+		}
+
+		_, err = userRepo.UpdateUser(ctx, user.ChangeLanguageCode(domainUser.LanguageCode(newLang)))
+		if err != nil {
+			return state, nil, fmt.Errorf("failed to update user language: %w", err)
+		}
+
+		response := router.NewMessageResponse(&bot.SendMessageParams{
+			Text: msgs.LanguageChanged(newLang),
+		})
+		response.Lang = newLang
+		return state, response, nil
+	}
+}
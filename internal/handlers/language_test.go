@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/router"
+
+	domainUser "whitelist-bot/internal/domain/user"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLanguage(t *testing.T) {
+	t.Parallel()
+
+	testUser := createTestUser(t)
+
+	tests := []struct {
+		name          string
+		text          string
+		setupMock     func(*mockiUserRepository)
+		expectedError error
+		validateMsg   func(*testing.T, router.Response)
+	}{
+		{
+			name:      "usage",
+			text:      "/language",
+			setupMock: func(m *mockiUserRepository) {},
+			validateMsg: func(t *testing.T, response router.Response) {
+				msgResponse, ok := response.(*router.MessageResponse)
+				require.True(t, ok)
+				require.Len(t, msgResponse.Params, 1)
+				assert.Contains(t, msgResponse.Params[0].Text, "English")
+				assert.Contains(t, msgResponse.Params[0].Text, "Русский")
+			},
+		},
+		{
+			name:      "unsupported",
+			text:      "/language <de>",
+			setupMock: func(m *mockiUserRepository) {},
+			validateMsg: func(t *testing.T, response router.Response) {
+				msgResponse, ok := response.(*router.MessageResponse)
+				require.True(t, ok)
+				require.Len(t, msgResponse.Params, 1)
+				assert.Contains(t, msgResponse.Params[0].Text, "&lt;de&gt;")
+			},
+		},
+		{
+			name: "success",
+			text: "/language EN",
+			setupMock: func(m *mockiUserRepository) {
+				m.EXPECT().
+					UpdateUser(mock.Anything, mock.MatchedBy(func(u domainUser.User) bool {
+						return u.LanguageCode() == domainUser.LanguageCode(i18n.LangEN)
+					})).
+					Return(testUser, nil).
+					Once()
+			},
+			validateMsg: func(t *testing.T, response router.Response) {
+				msgResponse, ok := response.(*router.MessageResponse)
+				require.True(t, ok)
+				require.Len(t, msgResponse.Params, 1)
+				assert.Equal(t, i18n.LangEN, msgResponse.Lang)
+				assert.Contains(t, msgResponse.Params[0].Text, "Language changed")
+			},
+		},
+		{
+			name: "update_error",
+			text: "/language en",
+			setupMock: func(m *mockiUserRepository) {
+				m.EXPECT().
+					UpdateUser(mock.Anything, mock.Anything).
+					Return(domainUser.User{}, errors.New("db error")).
+					Once()
+			},
+			expectedError: errors.New("failed to update user language: db error"),
+			validateMsg: func(t *testing.T, response router.Response) {
+				assert.Nil(t, response)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockRepo := newMockiUserRepository(t)
+			tt.setupMock(mockRepo)
+
+			handler := Language(mockRepo)
+
+			update := &models.Update{
+				Message: &models.Message{
+					Text: tt.text,
+					From: &models.User{
+						ID: int64(testUser.TelegramID()),
+					},
+				},
+			}
+
+			ctx := withCommand(t, context.Background(), router.UpdateContext{User: testUser, State: fsm.StateIdle}, tt.text)
+			state, response, err := handler(ctx, nil, update, fsm.StateIdle)
+
+			assert.Equal(t, fsm.StateIdle, state)
+			if tt.expectedError != nil {
+				require.Error(t, err)
+				assert.ErrorContains(t, err, tt.expectedError.Error())
+			} else {
+				require.NoError(t, err)
+			}
+			tt.validateMsg(t, response)
+		})
+	}
+}
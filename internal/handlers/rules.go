@@ -0,0 +1,23 @@
+package handlers
+
+import (
+	"context"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/router"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+func Rules() router.HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+		response := router.NewMessageResponse(
+			&bot.SendMessageParams{
+				Text: msgs.Rules(i18n.LangFromContext(ctx)),
+			},
+		)
+		return state, response, nil
+	}
+}
@@ -2,13 +2,14 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"testing"
 	"time"
 	"whitelist-bot/internal/callbacks"
 	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
 	"whitelist-bot/internal/router"
 
 	domainUser "whitelist-bot/internal/domain/user"
@@ -60,14 +61,11 @@ func TestApproveWLRequest_Success(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestApprove)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 			Message: models.MaybeInaccessibleMessage{
 				Message: &models.Message{
@@ -86,10 +84,7 @@ func TestApproveWLRequest_Success(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
 	mockUserRepo.EXPECT().
 		UserByID(mock.Anything, requesterID).
@@ -100,7 +95,7 @@ func TestApproveWLRequest_Success(t *testing.T) {
 		UpdateWLRequest(mock.Anything, mock.MatchedBy(func(req domainWLRequest.WLRequest) bool {
 			return req.Status() == domainWLRequest.StatusApproved &&
 				req.ArbiterID() == domainWLRequest.ArbiterID(arbiter.ID())
-		})).
+		}), domainWLRequest.StatusPending).
 		Return(approvedRequest, nil).
 		Once()
 
@@ -118,7 +113,7 @@ func TestApproveWLRequest_Success(t *testing.T) {
 	assert.NotNil(t, callbackResponse.EditParams)
 }
 
-func TestApproveWLRequest_InvalidCallbackData(t *testing.T) {
+func TestApproveWLRequest_NoCallbackData(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
@@ -127,7 +122,6 @@ func TestApproveWLRequest_InvalidCallbackData(t *testing.T) {
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: "invalid json data",
 			From: models.User{ID: 789012},
 		},
 	}
@@ -135,17 +129,12 @@ func TestApproveWLRequest_InvalidCallbackData(t *testing.T) {
 	handler := ApproveWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to unmarshal callback data")
+	require.ErrorIs(t, err, callbacks.ErrNoData)
 	assert.Equal(t, fsm.StateIdle, state)
-	require.NotNil(t, response)
-
-	callbackResponse, ok := response.(*router.CallbackResponse)
-	require.True(t, ok)
-	assert.NotNil(t, callbackResponse.CallbackParams)
+	assert.Nil(t, response)
 }
 
-func TestApproveWLRequest_InvalidAction(t *testing.T) {
+func TestApproveWLRequest_WLRequestNotFound(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
@@ -171,24 +160,28 @@ func TestApproveWLRequest_InvalidAction(t *testing.T) {
 		UpdatedAt(now).
 		Build()
 	require.NoError(t, err)
+	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequest.ID(), core.ActionWLRequestDecline)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: 789012},
 		},
 	}
 
+	expectedErr := errors.New("wl request not found")
+	mockWLRepo.EXPECT().
+		WLRequestByID(mock.Anything, wlRequestID).
+		Return(domainWLRequest.WLRequest{}, expectedErr).
+		Once()
+
 	handler := ApproveWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "invalid action")
+	assert.Contains(t, err.Error(), "failed to get wl request")
 	assert.Equal(t, fsm.StateIdle, state)
 	require.NotNil(t, response)
 
@@ -197,7 +190,7 @@ func TestApproveWLRequest_InvalidAction(t *testing.T) {
 	assert.NotNil(t, callbackResponse.CallbackParams)
 }
 
-func TestApproveWLRequest_WLRequestNotFound(t *testing.T) {
+func TestApproveWLRequest_ArbiterNotFound(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
@@ -225,29 +218,25 @@ func TestApproveWLRequest_WLRequestNotFound(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestApprove)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: 789012},
 		},
 	}
 
-	expectedErr := errors.New("wl request not found")
 	mockWLRepo.EXPECT().
 		WLRequestByID(mock.Anything, wlRequestID).
-		Return(domainWLRequest.WLRequest{}, expectedErr).
+		Return(wlRequest, nil).
 		Once()
 
 	handler := ApproveWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to get wl request")
+	assert.Contains(t, err.Error(), "failed to get arbiter")
 	assert.Equal(t, fsm.StateIdle, state)
 	require.NotNil(t, response)
 
@@ -256,13 +245,14 @@ func TestApproveWLRequest_WLRequestNotFound(t *testing.T) {
 	assert.NotNil(t, callbackResponse.CallbackParams)
 }
 
-func TestApproveWLRequest_ArbiterNotFound(t *testing.T) {
+func TestApproveWLRequest_RequesterNotFound(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
 	mockWLRepo := newMockiWLRequestRepository(t)
 
 	now := time.Now()
+
 	requester, err := domainUser.NewBuilder().
 		NewID().
 		TelegramIDFromInt(123456).
@@ -272,10 +262,21 @@ func TestApproveWLRequest_ArbiterNotFound(t *testing.T) {
 		UpdatedAt(now).
 		Build()
 	require.NoError(t, err)
+	requesterID := requester.ID()
+
+	arbiter, err := domainUser.NewBuilder().
+		NewID().
+		TelegramIDFromInt(789012).
+		ChatIDFromInt(1234567890).
+		UsernameFromString("arbiter").
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
 
 	wlRequest, err := domainWLRequest.NewBuilder().
 		NewID().
-		RequesterIDFromUserID(requester.ID()).
+		RequesterIDFromUserID(requesterID).
 		NicknameFromString("testnick").
 		StatusFromString(string(domainWLRequest.StatusPending)).
 		CreatedAt(now).
@@ -284,15 +285,12 @@ func TestApproveWLRequest_ArbiterNotFound(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestApprove)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
-			From: models.User{ID: 789012},
+			From: models.User{ID: int64(arbiter.TelegramID())},
 		},
 	}
 
@@ -301,9 +299,11 @@ func TestApproveWLRequest_ArbiterNotFound(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	expectedErr := errors.New("arbiter not found")
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
+
+	expectedErr := errors.New("requester not found")
 	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(789012)).
+		UserByID(mock.Anything, requesterID).
 		Return(domainUser.User{}, expectedErr).
 		Once()
 
@@ -311,7 +311,7 @@ func TestApproveWLRequest_ArbiterNotFound(t *testing.T) {
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to get arbiter")
+	assert.Contains(t, err.Error(), "failed to get requester")
 	assert.Equal(t, fsm.StateIdle, state)
 	require.NotNil(t, response)
 
@@ -320,7 +320,7 @@ func TestApproveWLRequest_ArbiterNotFound(t *testing.T) {
 	assert.NotNil(t, callbackResponse.CallbackParams)
 }
 
-func TestApproveWLRequest_RequesterNotFound(t *testing.T) {
+func TestApproveWLRequest_ApproveError(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
@@ -353,21 +353,19 @@ func TestApproveWLRequest_RequesterNotFound(t *testing.T) {
 		NewID().
 		RequesterIDFromUserID(requesterID).
 		NicknameFromString("testnick").
-		StatusFromString(string(domainWLRequest.StatusPending)).
+		StatusFromString(string(domainWLRequest.StatusApproved)).
+		ArbiterIDFromUserID(arbiter.ID()).
 		CreatedAt(now).
 		UpdatedAt(now).
 		Build()
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestApprove)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 		},
 	}
@@ -377,22 +375,18 @@ func TestApproveWLRequest_RequesterNotFound(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
-	expectedErr := errors.New("requester not found")
 	mockUserRepo.EXPECT().
 		UserByID(mock.Anything, requesterID).
-		Return(domainUser.User{}, expectedErr).
+		Return(requester, nil).
 		Once()
 
 	handler := ApproveWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to get requester")
+	assert.Contains(t, err.Error(), "failed to approve wl request")
 	assert.Equal(t, fsm.StateIdle, state)
 	require.NotNil(t, response)
 
@@ -401,7 +395,7 @@ func TestApproveWLRequest_RequesterNotFound(t *testing.T) {
 	assert.NotNil(t, callbackResponse.CallbackParams)
 }
 
-func TestApproveWLRequest_ApproveError(t *testing.T) {
+func TestApproveWLRequest_UpdateWLRequestError(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
@@ -434,22 +428,18 @@ func TestApproveWLRequest_ApproveError(t *testing.T) {
 		NewID().
 		RequesterIDFromUserID(requesterID).
 		NicknameFromString("testnick").
-		StatusFromString(string(domainWLRequest.StatusApproved)).
-		ArbiterIDFromUserID(arbiter.ID()).
+		StatusFromString(string(domainWLRequest.StatusPending)).
 		CreatedAt(now).
 		UpdatedAt(now).
 		Build()
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestApprove)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 		},
 	}
@@ -459,21 +449,24 @@ func TestApproveWLRequest_ApproveError(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
 	mockUserRepo.EXPECT().
 		UserByID(mock.Anything, requesterID).
 		Return(requester, nil).
 		Once()
 
+	expectedErr := errors.New("database error")
+	mockWLRepo.EXPECT().
+		UpdateWLRequest(mock.Anything, mock.AnythingOfType("wl_request.WLRequest"), domainWLRequest.StatusPending).
+		Return(domainWLRequest.WLRequest{}, expectedErr).
+		Once()
+
 	handler := ApproveWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to build updated request")
+	assert.Contains(t, err.Error(), "failed to update wl request")
 	assert.Equal(t, fsm.StateIdle, state)
 	require.NotNil(t, response)
 
@@ -482,7 +475,7 @@ func TestApproveWLRequest_ApproveError(t *testing.T) {
 	assert.NotNil(t, callbackResponse.CallbackParams)
 }
 
-func TestApproveWLRequest_UpdateWLRequestError(t *testing.T) {
+func TestApproveWLRequest_StatusChanged(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
@@ -522,14 +515,11 @@ func TestApproveWLRequest_UpdateWLRequestError(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestApprove)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 		},
 	}
@@ -539,31 +529,28 @@ func TestApproveWLRequest_UpdateWLRequestError(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
 	mockUserRepo.EXPECT().
 		UserByID(mock.Anything, requesterID).
 		Return(requester, nil).
 		Once()
 
-	expectedErr := errors.New("database error")
+	// The expiration job got to the request between loading it and saving the approval.
 	mockWLRepo.EXPECT().
-		UpdateWLRequest(mock.Anything, mock.AnythingOfType("wl_request.WLRequest")).
-		Return(domainWLRequest.WLRequest{}, expectedErr).
+		UpdateWLRequest(mock.Anything, mock.AnythingOfType("wl_request.WLRequest"), domainWLRequest.StatusPending).
+		Return(domainWLRequest.WLRequest{}, core.ErrWLRequestStatusChanged).
 		Once()
 
 	handler := ApproveWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to update wl request")
+	require.ErrorIs(t, err, domainWLRequest.ErrCantApproveNonPendingWLRequest)
 	assert.Equal(t, fsm.StateIdle, state)
 	require.NotNil(t, response)
 
 	callbackResponse, ok := response.(*router.CallbackResponse)
 	require.True(t, ok)
-	assert.NotNil(t, callbackResponse.CallbackParams)
+	require.NotNil(t, callbackResponse.CallbackParams)
+	assert.Equal(t, msgs.CallbackError(i18n.LangFromContext(ctx), i18n.ErrTextWLRequestResolved), callbackResponse.CallbackParams.Text)
 }
@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/router"
+
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// renderDecided renders the card of a request after an admin's decision.
+type renderDecided func(
+	lang i18n.Lang,
+	wlRequest domainWLRequest.WLRequest,
+	arbiter domainUser.User,
+	requester domainUser.User,
+) string
+
+// ApproveWLRequestCommand approves a pending request from the keyboard: /approve <id|nickname>.
+func ApproveWLRequestCommand(userRepo iUserRepository, wlRequestRepo iWLRequestRepository) router.HandlerFunc {
+	return decideWLRequestCommand(userRepo, wlRequestRepo, domainWLRequest.StatusPending,
+		func(rest string) (wlRequestDecision, bool) {
+			return approveDecision, rest == ""
+		},
+		msgs.ApprovedWLRequest,
+	)
+}
+
+// DeclineWLRequestCommand declines a pending request with a reason: /decline <id|nickname> <reason>.
+func DeclineWLRequestCommand(userRepo iUserRepository, wlRequestRepo iWLRequestRepository) router.HandlerFunc {
+	return decideWLRequestCommand(userRepo, wlRequestRepo, domainWLRequest.StatusPending,
+		func(rest string) (wlRequestDecision, bool) {
+			return declineDecision(domainWLRequest.DeclineReason(rest)), rest != ""
+		},
+		msgs.DeclinedWLRequest,
+	)
+}
+
+// RevokeWLRequestCommand takes back an approval: /revoke <id|nickname>.
+func RevokeWLRequestCommand(userRepo iUserRepository, wlRequestRepo iWLRequestRepository) router.HandlerFunc {
+	return decideWLRequestCommand(userRepo, wlRequestRepo, domainWLRequest.StatusApproved,
+		func(rest string) (wlRequestDecision, bool) {
+			return revokeDecision, rest == ""
+		},
+		msgs.RevokedWLRequest,
+	)
+}
+
+// decideWLRequestCommand handles the commands that decide a request. The first argument is the target,
+// a nickname is looked up among requests in the status. decision builds the decision from the raw text after
+// the target, so a reason is kept as the admin typed it, and reports whether it is valid.
+func decideWLRequestCommand(
+	userRepo iUserRepository,
+	wlRequestRepo iWLRequestRepository,
+	status domainWLRequest.Status,
+	decision func(rest string) (wlRequestDecision, bool),
+	render renderDecided,
+) router.HandlerFunc {
+	return func(ctx context.Context, _ *bot.Bot, _ *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+		lang := i18n.LangFromContext(ctx)
+		cmd, _ := router.CommandFromContext(ctx)
+		target, rest, found := router.CutArg(cmd.RawArgs)
+		if !found {
+			return state, router.NewMessageResponse(&bot.SendMessageParams{Text: msgs.WLRequestCommandsUsage(lang)}), nil
+		}
+		decide, ok := decision(rest)
+		if !ok {
+			return state, router.NewMessageResponse(&bot.SendMessageParams{Text: msgs.WLRequestCommandsUsage(lang)}), nil
+		}
+
+		wlRequest, err := resolveWLRequest(ctx, wlRequestRepo, target, status)
+		var ambiguous *ambiguousWLRequestError
+		if errors.As(err, &ambiguous) {
+			response := router.NewMessageResponse(&bot.SendMessageParams{
+				Text: msgs.AmbiguousWLRequest(lang, ambiguous.nickname, ambiguous.candidates, MAX_AMBIGUOUS_WL_REQUESTS),
+			})
+			return state, response, nil
+		}
+		if err != nil {
+			return state, nil, err
+		}
+
+		decided, err := decideWLRequest(ctx, userRepo, wlRequestRepo, wlRequest, decide)
+		if err != nil {
+			return state, nil, err
+		}
+
+		response := router.NewMessageResponse(&bot.SendMessageParams{
+			Text: render(lang, decided.wlRequest, decided.arbiter, decided.requester),
+		})
+		return state, response, nil
+	}
+}
+
+// ShowWLRequest shows a request in any status: /show <id>.
+func ShowWLRequest(userRepo iUserRepository, wlRequestRepo iWLRequestRepository) router.HandlerFunc {
+	return func(ctx context.Context, _ *bot.Bot, _ *models.Update, state fsm.State) (fsm.State, router.Response, error) {
+		lang := i18n.LangFromContext(ctx)
+		cmd, _ := router.CommandFromContext(ctx)
+		if len(cmd.Args) != 1 {
+			return state, router.NewMessageResponse(&bot.SendMessageParams{Text: msgs.WLRequestCommandsUsage(lang)}), nil
+		}
+
+		id, err := utils.UUIDFromString[domainWLRequest.ID](cmd.Args[0])
+		if err != nil {
+			return state, nil, fmt.Errorf("%w: %w", core.ErrFailedToParseID, err)
+		}
+		wlRequest, err := wlRequestRepo.WLRequestByID(ctx, id)
+		if err != nil {
+			return state, nil, fmt.Errorf("failed to get wl request: %w", err)
+		}
+
+		requester, err := userRepo.UserByID(ctx, domainUser.ID(wlRequest.RequesterID()))
+		if err != nil {
+			return state, nil, fmt.Errorf("failed to get requester: %w", err)
+		}
+		var arbiter *domainUser.User
+		if !wlRequest.ArbiterID().IsZero() && !wlRequest.ArbiterID().IsSystem() {
+			user, err := userRepo.UserByID(ctx, domainUser.ID(wlRequest.ArbiterID()))
+			if err != nil {
+				return state, nil, fmt.Errorf("failed to get arbiter: %w", err)
+			}
+			arbiter = &user
+		}
+
+		response := router.NewMessageResponse(&bot.SendMessageParams{
+			Text: msgs.WLRequestDetails(lang, wlRequest, requester, arbiter),
+		})
+		return state, response, nil
+	}
+}
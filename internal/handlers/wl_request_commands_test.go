@@ -0,0 +1,313 @@
+package handlers
+
+import (
+	"context"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/router"
+
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWLRequestCommands(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	arbiter := createTestUser(t)
+	requester, err := domainUser.NewBuilder().
+		NewID().
+		TelegramIDFromInt(123456).
+		ChatIDFromInt(123456).
+		UsernameFromString("requester").
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+
+	pending, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterIDFromUserID(requester.ID()).
+		NicknameFromString("Steve").
+		Status(domainWLRequest.StatusPending).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	otherPending, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterIDFromUserID(requester.ID()).
+		NicknameFromString("steve").
+		Status(domainWLRequest.StatusPending).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	approved, err := pending.Approve(domainWLRequest.ArbiterID(arbiter.ID()))
+	require.NoError(t, err)
+
+	savedWith := func(status domainWLRequest.Status, reason domainWLRequest.DeclineReason) any {
+		return mock.MatchedBy(func(wlRequest domainWLRequest.WLRequest) bool {
+			return wlRequest.Status() == status &&
+				wlRequest.DeclineReason() == reason &&
+				wlRequest.ArbiterID() == domainWLRequest.ArbiterID(arbiter.ID())
+		})
+	}
+
+	tests := []struct {
+		name          string
+		handler       func(iUserRepository, iWLRequestRepository) router.HandlerFunc
+		text          string
+		setupMock     func(*mockiUserRepository, *mockiWLRequestRepository)
+		expectedError error
+		expectedText  string
+	}{
+		{
+			name:         "usage",
+			handler:      ApproveWLRequestCommand,
+			text:         "/approve",
+			setupMock:    func(*mockiUserRepository, *mockiWLRequestRepository) {},
+			expectedText: "/decline &lt;id|ник&gt; &lt;причина&gt;",
+		},
+		{
+			name:         "decline without reason",
+			handler:      DeclineWLRequestCommand,
+			text:         "/decline Steve",
+			setupMock:    func(*mockiUserRepository, *mockiWLRequestRepository) {},
+			expectedText: "/decline &lt;id|ник&gt; &lt;причина&gt;",
+		},
+		{
+			name:    "approve by nickname",
+			handler: ApproveWLRequestCommand,
+			text:    "/approve Steve",
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().
+					WLRequestsByNickname(mock.Anything, domainWLRequest.Nickname("Steve"), domainWLRequest.StatusPending, int64(MAX_AMBIGUOUS_WL_REQUESTS+1)).
+					Return([]domainWLRequest.WLRequest{pending}, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+				w.EXPECT().UpdateWLRequest(mock.Anything, savedWith(domainWLRequest.StatusApproved, ""), domainWLRequest.StatusPending).Return(approved, nil).Once()
+			},
+			expectedText: "Заявка подтверждена",
+		},
+		{
+			name:    "approve by id",
+			handler: ApproveWLRequestCommand,
+			text:    "/approve " + pending.ID().String(),
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().WLRequestByID(mock.Anything, pending.ID()).Return(pending, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+				w.EXPECT().UpdateWLRequest(mock.Anything, savedWith(domainWLRequest.StatusApproved, ""), domainWLRequest.StatusPending).Return(approved, nil).Once()
+			},
+			expectedText: "Заявка подтверждена",
+		},
+		{
+			name:    "decline with reason",
+			handler: DeclineWLRequestCommand,
+			text:    `/decline Steve  "no such player"   online \o/`,
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().
+					WLRequestsByNickname(mock.Anything, domainWLRequest.Nickname("Steve"), domainWLRequest.StatusPending, mock.Anything).
+					Return([]domainWLRequest.WLRequest{pending}, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+				w.EXPECT().
+					UpdateWLRequest(mock.Anything, savedWith(domainWLRequest.StatusDeclined, `"no such player"   online \o/`), domainWLRequest.StatusPending).
+					Return(domainWLRequest.WLRequest{}, nil).Once()
+			},
+			expectedText: "no such player",
+		},
+		{
+			name:    "decline expired meanwhile",
+			handler: DeclineWLRequestCommand,
+			text:    "/decline " + pending.ID().String() + " spam",
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().WLRequestByID(mock.Anything, pending.ID()).Return(pending, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+				w.EXPECT().
+					UpdateWLRequest(mock.Anything, savedWith(domainWLRequest.StatusDeclined, "spam"), domainWLRequest.StatusPending).
+					Return(domainWLRequest.WLRequest{}, core.ErrWLRequestStatusChanged).Once()
+			},
+			expectedError: domainWLRequest.ErrCantDeclineNonPendingWLRequest,
+		},
+		{
+			name:    "revoke by nickname",
+			handler: RevokeWLRequestCommand,
+			text:    "/revoke Steve",
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().
+					WLRequestsByNickname(mock.Anything, domainWLRequest.Nickname("Steve"), domainWLRequest.StatusApproved, mock.Anything).
+					Return([]domainWLRequest.WLRequest{approved}, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+				w.EXPECT().UpdateWLRequest(mock.Anything, savedWith(domainWLRequest.StatusRevoked, ""), domainWLRequest.StatusApproved).Return(domainWLRequest.WLRequest{}, nil).Once()
+			},
+			expectedText: "Одобрение отозвано",
+		},
+		{
+			name:    "ambiguous nickname",
+			handler: ApproveWLRequestCommand,
+			text:    "/approve steve",
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().
+					WLRequestsByNickname(mock.Anything, domainWLRequest.Nickname("steve"), domainWLRequest.StatusPending, mock.Anything).
+					Return([]domainWLRequest.WLRequest{pending, otherPending}, nil).Once()
+			},
+			expectedText: otherPending.ID().String(),
+		},
+		{
+			name:    "unknown nickname",
+			handler: ApproveWLRequestCommand,
+			text:    "/approve Alex",
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().
+					WLRequestsByNickname(mock.Anything, domainWLRequest.Nickname("Alex"), domainWLRequest.StatusPending, mock.Anything).
+					Return(nil, nil).Once()
+			},
+			expectedError: core.ErrWLRequestNotFound,
+		},
+		{
+			name:    "revoke not approved",
+			handler: RevokeWLRequestCommand,
+			text:    "/revoke " + pending.ID().String(),
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().WLRequestByID(mock.Anything, pending.ID()).Return(pending, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+			},
+			expectedError: domainWLRequest.ErrCantRevokeNonApprovedWLRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUserRepo := newMockiUserRepository(t)
+			mockWLRepo := newMockiWLRequestRepository(t)
+			tt.setupMock(mockUserRepo, mockWLRepo)
+
+			handler := tt.handler(mockUserRepo, mockWLRepo)
+			update := &models.Update{Message: &models.Message{Text: tt.text}}
+
+			ctx := withCommand(t, context.Background(), router.UpdateContext{User: arbiter, State: fsm.StateIdle}, tt.text)
+			state, response, err := handler(ctx, nil, update, fsm.StateIdle)
+
+			assert.Equal(t, fsm.StateIdle, state)
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, response)
+				return
+			}
+			require.NoError(t, err)
+			msgResponse, ok := response.(*router.MessageResponse)
+			require.True(t, ok)
+			require.Len(t, msgResponse.Params, 1)
+			assert.Contains(t, msgResponse.Params[0].Text, tt.expectedText)
+		})
+	}
+}
+
+func TestShowWLRequest(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	arbiter := createTestUser(t)
+	requester, err := domainUser.NewBuilder().
+		NewID().
+		TelegramIDFromInt(123456).
+		ChatIDFromInt(123456).
+		UsernameFromString("requester").
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	pending, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterIDFromUserID(requester.ID()).
+		NicknameFromString("Steve").
+		Status(domainWLRequest.StatusPending).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	declined, err := pending.Decline(domainWLRequest.ArbiterID(arbiter.ID()), "griefing")
+	require.NoError(t, err)
+	expired, err := pending.Expire()
+	require.NoError(t, err)
+
+	tests := []struct {
+		name          string
+		text          string
+		setupMock     func(*mockiUserRepository, *mockiWLRequestRepository)
+		expectedError error
+		expectedTexts []string
+	}{
+		{
+			name:          "invalid id",
+			text:          "/show Steve",
+			setupMock:     func(*mockiUserRepository, *mockiWLRequestRepository) {},
+			expectedError: core.ErrFailedToParseID,
+		},
+		{
+			name: "not found",
+			text: "/show " + pending.ID().String(),
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().WLRequestByID(mock.Anything, pending.ID()).Return(domainWLRequest.WLRequest{}, core.ErrWLRequestNotFound).Once()
+			},
+			expectedError: core.ErrWLRequestNotFound,
+		},
+		{
+			name: "declined",
+			text: "/show " + declined.ID().String(),
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().WLRequestByID(mock.Anything, declined.ID()).Return(declined, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, arbiter.ID()).Return(arbiter, nil).Once()
+			},
+			expectedTexts: []string{"отклонена", "griefing", "Арбитр", "@testuser"},
+		},
+		{
+			name: "expired by the bot",
+			text: "/show " + expired.ID().String(),
+			setupMock: func(u *mockiUserRepository, w *mockiWLRequestRepository) {
+				w.EXPECT().WLRequestByID(mock.Anything, expired.ID()).Return(expired, nil).Once()
+				u.EXPECT().UserByID(mock.Anything, requester.ID()).Return(requester, nil).Once()
+			},
+			expectedTexts: []string{"просрочена", "@requester"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			mockUserRepo := newMockiUserRepository(t)
+			mockWLRepo := newMockiWLRequestRepository(t)
+			tt.setupMock(mockUserRepo, mockWLRepo)
+
+			handler := ShowWLRequest(mockUserRepo, mockWLRepo)
+			update := &models.Update{Message: &models.Message{Text: tt.text}}
+
+			ctx := withCommand(t, context.Background(), router.UpdateContext{User: arbiter, State: fsm.StateIdle}, tt.text)
+			state, response, err := handler(ctx, nil, update, fsm.StateIdle)
+
+			assert.Equal(t, fsm.StateIdle, state)
+			if tt.expectedError != nil {
+				require.ErrorIs(t, err, tt.expectedError)
+				assert.Nil(t, response)
+				return
+			}
+			require.NoError(t, err)
+			msgResponse, ok := response.(*router.MessageResponse)
+			require.True(t, ok)
+			require.Len(t, msgResponse.Params, 1)
+			for _, text := range tt.expectedTexts {
+				assert.Contains(t, msgResponse.Params[0].Text, text)
+			}
+		})
+	}
+}
@@ -0,0 +1,193 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/router"
+
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+
+	"github.com/go-telegram/bot"
+)
+
+// MAX_AMBIGUOUS_WL_REQUESTS is how many matching requests are listed when a nickname is ambiguous.
+const MAX_AMBIGUOUS_WL_REQUESTS = 5
+
+// wlRequestDecision moves a wl request to its next status on behalf of the arbiter.
+type wlRequestDecision struct {
+	action string
+	apply  func(wlRequest domainWLRequest.WLRequest, arbiterID domainWLRequest.ArbiterID) (domainWLRequest.WLRequest, error)
+	// conflict is the error of apply for a request in another status, it is returned
+	// when the status changed between loading the request and saving the decision.
+	conflict error
+}
+
+var (
+	approveDecision = wlRequestDecision{
+		action:   "approve",
+		apply:    domainWLRequest.WLRequest.Approve,
+		conflict: domainWLRequest.ErrCantApproveNonPendingWLRequest,
+	}
+	revokeDecision = wlRequestDecision{
+		action:   "revoke",
+		apply:    domainWLRequest.WLRequest.Revoke,
+		conflict: domainWLRequest.ErrCantRevokeNonApprovedWLRequest,
+	}
+)
+
+func declineDecision(reason domainWLRequest.DeclineReason) wlRequestDecision {
+	return wlRequestDecision{
+		action:   "decline",
+		conflict: domainWLRequest.ErrCantDeclineNonPendingWLRequest,
+		apply: func(wlRequest domainWLRequest.WLRequest, arbiterID domainWLRequest.ArbiterID) (domainWLRequest.WLRequest, error) {
+			return wlRequest.Decline(arbiterID, reason)
+		},
+	}
+}
+
+// decidedWLRequest is a saved decision with the people shown on the request card.
+type decidedWLRequest struct {
+	wlRequest domainWLRequest.WLRequest
+	arbiter   domainUser.User
+	requester domainUser.User
+}
+
+// wlRequestError keeps the text shown on the button along with the cause.
+type wlRequestError struct {
+	text i18n.Key
+	err  error
+}
+
+func (e *wlRequestError) Error() string {
+	return e.err.Error()
+}
+
+func (e *wlRequestError) Unwrap() error {
+	return e.err
+}
+
+// ambiguousWLRequestError lists the requests a nickname matches, so the admin can pick one by ID.
+type ambiguousWLRequestError struct {
+	nickname   string
+	candidates []domainWLRequest.WLRequest
+}
+
+func (e *ambiguousWLRequestError) Error() string {
+	return fmt.Sprintf("%s: %d requests match %q", core.ErrAmbiguousWLRequest, len(e.candidates), e.nickname)
+}
+
+func (e *ambiguousWLRequestError) Unwrap() error {
+	return core.ErrAmbiguousWLRequest
+}
+
+// decideWLRequest applies the decision of the admin handling the update and saves it.
+// The inline buttons and the admin commands both go through it.
+func decideWLRequest(
+	ctx context.Context,
+	userRepo iUserRepository,
+	wlRequestRepo iWLRequestRepository,
+	wlRequest domainWLRequest.WLRequest,
+	decision wlRequestDecision,
+) (decidedWLRequest, error) {
+	ctx = logger.WithLogValue(ctx, logger.WLRequestIDField, wlRequest.ID().String())
+
+	arbiter, err := router.UserFromContext(ctx)
+	if err != nil {
+		return decidedWLRequest{}, &wlRequestError{
+			text: i18n.ErrTextArbiterNotFound,
+			err:  fmt.Errorf("failed to get arbiter: %w", err),
+		}
+	}
+	ctx = logger.WithLogValue(ctx, logger.ArbiterIDField, arbiter.ID().String())
+	slog.DebugContext(ctx, "Arbiter resolved")
+
+	requester, err := userRepo.UserByID(ctx, domainUser.ID(wlRequest.RequesterID()))
+	if err != nil {
+		return decidedWLRequest{}, &wlRequestError{
+			text: i18n.ErrTextRequesterNotFound,
+			err:  fmt.Errorf("failed to get requester: %w", err),
+		}
+	}
+	ctx = logger.WithLogValue(ctx, logger.RequesterIDField, requester.ID().String())
+	slog.DebugContext(ctx, "Requester fetched from database")
+
+	decided, err := decision.apply(wlRequest, domainWLRequest.ArbiterID(arbiter.ID()))
+	if err != nil {
+		return decidedWLRequest{}, &wlRequestError{
+			text: i18n.ErrTextWLRequestUpdate,
+			err:  fmt.Errorf("failed to %s wl request: %w", decision.action, err),
+		}
+	}
+
+	_, err = wlRequestRepo.UpdateWLRequest(ctx, decided, wlRequest.Status())
+	if errors.Is(err, core.ErrWLRequestStatusChanged) {
+		return decidedWLRequest{}, &wlRequestError{
+			text: errorStatusMap[decision.conflict],
+			err:  fmt.Errorf("failed to %s wl request: %w: %w", decision.action, decision.conflict, err),
+		}
+	}
+	if err != nil {
+		return decidedWLRequest{}, &wlRequestError{
+			text: i18n.ErrTextWLRequestSave,
+			err:  fmt.Errorf("failed to update wl request: %w", err),
+		}
+	}
+	slog.DebugContext(ctx, "WL request updated", "status", decided.Status())
+
+	return decidedWLRequest{wlRequest: decided, arbiter: arbiter, requester: requester}, nil
+}
+
+// resolveWLRequest finds the request an admin command targets: by its ID, or by the nickname
+// of the request in the status. A nickname matching several requests gives an ambiguousWLRequestError.
+func resolveWLRequest(
+	ctx context.Context,
+	wlRequestRepo iWLRequestRepository,
+	target string,
+	status domainWLRequest.Status,
+) (domainWLRequest.WLRequest, error) {
+	if id, err := utils.UUIDFromString[domainWLRequest.ID](target); err == nil {
+		wlRequest, err := wlRequestRepo.WLRequestByID(ctx, id)
+		if err != nil {
+			return domainWLRequest.WLRequest{}, fmt.Errorf("failed to get wl request: %w", err)
+		}
+		return wlRequest, nil
+	}
+
+	wlRequests, err := wlRequestRepo.WLRequestsByNickname(
+		ctx,
+		domainWLRequest.Nickname(target),
+		status,
+		MAX_AMBIGUOUS_WL_REQUESTS+1,
+	)
+	if err != nil {
+		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to get wl requests by nickname: %w", err)
+	}
+	switch len(wlRequests) {
+	case 0:
+		return domainWLRequest.WLRequest{}, fmt.Errorf("%w: no %s request for %q", core.ErrWLRequestNotFound, status, target)
+	case 1:
+		return wlRequests[0], nil
+	default:
+		return domainWLRequest.WLRequest{}, &ambiguousWLRequestError{nickname: target, candidates: wlRequests}
+	}
+}
+
+// callbackErrorResponse answers the button with the text of a wlRequestError.
+func callbackErrorResponse(lang i18n.Lang, err error) router.Response {
+	text := i18n.ErrTextWLRequestUpdate
+	var wlErr *wlRequestError
+	if errors.As(err, &wlErr) {
+		text = wlErr.text
+	}
+	return router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
+		Text: msgs.CallbackError(lang, text),
+	}, nil)
+}
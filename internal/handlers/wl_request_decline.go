@@ -4,12 +4,13 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"whitelist-bot/internal/callbacks"
 	"whitelist-bot/internal/core/logger"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/msgs"
 	"whitelist-bot/internal/router"
 
-	domainUser "whitelist-bot/internal/domain/user"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
 
 	"github.com/go-telegram/bot"
@@ -21,81 +22,35 @@ func DeclineWLRequest(
 	wlRequestRepo iWLRequestRepository,
 ) router.HandlerFunc {
 	return func(ctx context.Context, _ *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
-		callbackData, err := parseCallbackData(update.CallbackQuery.Data)
+		lang := i18n.LangFromContext(ctx)
+		callbackData, err := callbacks.DataFromContext[callbacks.WLRequestData](ctx)
 		if err != nil {
-			response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-				Text: msgs.CallbackError("неверный формат callback data"),
-			}, nil)
-			return state, response, fmt.Errorf("failed to unmarshal callback data: %w", err)
-		}
-
-		// TODO: ??????????????
-		if !callbackData.IsDecline() {
-			response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-				Text: msgs.CallbackError("неверный action"),
-			}, nil)
-			return state, response, fmt.Errorf("invalid action: expected decline, got %s", callbackData.Action())
+			return state, nil, fmt.Errorf("failed to get callback data: %w", err)
 		}
 
-		ctx = logger.WithLogValue(ctx, logger.WLRequestIDField, callbackData.ID().String())
+		ctx = logger.WithLogValue(ctx, logger.WLRequestIDField, callbackData.ID.String())
 
-		dbWLRequest, err := wlRequestRepo.WLRequestByID(ctx, callbackData.ID())
+		dbWLRequest, err := wlRequestRepo.WLRequestByID(ctx, callbackData.ID)
 		if err != nil {
 			slog.ErrorContext(ctx, "Failed to get wl request", logger.ErrorField, err.Error())
 			response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-				Text: msgs.CallbackError("заявка не найдена"),
+				Text: msgs.CallbackError(lang, i18n.ErrTextWLRequestNotFound),
 			}, nil)
 			return state, response, fmt.Errorf("failed to get wl request: %w", err)
 		}
 		slog.DebugContext(ctx, "WL request fetched from database")
 
-		arbiter, err := userRepo.UserByTelegramID(ctx, update.CallbackQuery.From.ID)
+		// The button declines with the default reason, /decline takes a custom one.
+		decision := declineDecision(domainWLRequest.DeclineReason(i18n.T(lang, i18n.MsgWLRequestDefaultDecline)))
+		decided, err := decideWLRequest(ctx, userRepo, wlRequestRepo, dbWLRequest, decision)
 		if err != nil {
-			slog.ErrorContext(ctx, "Failed to get arbiter", logger.ErrorField, err.Error())
-			response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-				Text: msgs.CallbackError("не удалось получить арбитра"),
-			}, nil)
-			return state, response, fmt.Errorf("failed to get arbiter: %w", err)
-		}
-		ctx = logger.WithLogValue(ctx, logger.ArbiterIDField, arbiter.ID().String())
-		slog.DebugContext(ctx, "Arbiter fetched from database")
-
-		requester, err := userRepo.UserByID(ctx, domainUser.ID(dbWLRequest.RequesterID()))
-		if err != nil {
-			response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-				Text: msgs.CallbackError("не удалось получить заявителя"),
-			}, nil)
-			return state, response, fmt.Errorf("failed to get requester: %w", err)
-		}
-		ctx = logger.WithLogValue(ctx, logger.RequesterIDField, requester.ID().String())
-		slog.DebugContext(ctx, "Requester fetched from database")
-
-		declinedRequest, err := dbWLRequest.Decline(
-			domainWLRequest.ArbiterID(arbiter.ID()),
-			// TODO: add possibility to customize decline reason.
-			domainWLRequest.DeclineReason("Отклонено администратором"),
-		)
-		if err != nil {
-			slog.ErrorContext(ctx, "Failed to decline wl request", logger.ErrorField, err.Error())
-			response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-				Text: msgs.CallbackError("ошибка при обновлении заявки"),
-			}, nil)
-			return state, response, fmt.Errorf("failed to decline wl request: %w", err)
-		}
-
-		_, err = wlRequestRepo.UpdateWLRequest(ctx, declinedRequest)
-		if err != nil {
-			slog.ErrorContext(ctx, "Failed to update wl request", logger.ErrorField, err.Error())
-			response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-				Text: msgs.CallbackError("ошибка при сохранении изменений"),
-			}, nil)
-			return state, response, fmt.Errorf("failed to update wl request: %w", err)
+			return state, callbackErrorResponse(lang, err), err
 		}
 
 		response := router.NewCallbackResponse(&bot.AnswerCallbackQueryParams{
-			Text: "❌ Заявка отклонена!",
+			Text: i18n.T(lang, i18n.MsgWLRequestDeclinedAnswer),
 		}, &bot.EditMessageTextParams{
-			Text: msgs.DeclinedWLRequest(declinedRequest, arbiter, requester),
+			Text: msgs.DeclinedWLRequest(lang, decided.wlRequest, decided.arbiter, decided.requester),
 		})
 
 		return state, response, nil
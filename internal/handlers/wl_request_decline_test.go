@@ -2,12 +2,10 @@ package handlers
 
 import (
 	"context"
-	"encoding/json"
 	"errors"
 	"testing"
 	"time"
 	"whitelist-bot/internal/callbacks"
-	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/fsm"
 	"whitelist-bot/internal/router"
 
@@ -60,14 +58,11 @@ func TestDeclineWLRequest_Success(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestDecline)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 			Message: models.MaybeInaccessibleMessage{
 				Message: &models.Message{
@@ -89,10 +84,7 @@ func TestDeclineWLRequest_Success(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
 	mockUserRepo.EXPECT().
 		UserByID(mock.Anything, requesterID).
@@ -103,7 +95,7 @@ func TestDeclineWLRequest_Success(t *testing.T) {
 		UpdateWLRequest(mock.Anything, mock.MatchedBy(func(req domainWLRequest.WLRequest) bool {
 			return req.Status() == domainWLRequest.StatusDeclined &&
 				req.ArbiterID() == domainWLRequest.ArbiterID(arbiter.ID())
-		})).
+		}), domainWLRequest.StatusPending).
 		Return(declinedRequest, nil).
 		Once()
 
@@ -121,7 +113,7 @@ func TestDeclineWLRequest_Success(t *testing.T) {
 	assert.NotNil(t, callbackResponse.EditParams)
 }
 
-func TestDeclineWLRequest_InvalidCallbackData(t *testing.T) {
+func TestDeclineWLRequest_NoCallbackData(t *testing.T) {
 	ctx := context.Background()
 
 	mockUserRepo := newMockiUserRepository(t)
@@ -130,7 +122,6 @@ func TestDeclineWLRequest_InvalidCallbackData(t *testing.T) {
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: "invalid json data",
 			From: models.User{ID: 789012},
 		},
 	}
@@ -138,66 +129,9 @@ func TestDeclineWLRequest_InvalidCallbackData(t *testing.T) {
 	handler := DeclineWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "failed to unmarshal callback data")
+	require.ErrorIs(t, err, callbacks.ErrNoData)
 	assert.Equal(t, fsm.StateIdle, state)
-	require.NotNil(t, response)
-
-	callbackResponse, ok := response.(*router.CallbackResponse)
-	require.True(t, ok)
-	assert.NotNil(t, callbackResponse.CallbackParams)
-}
-
-func TestDeclineWLRequest_InvalidAction(t *testing.T) {
-	ctx := context.Background()
-
-	mockUserRepo := newMockiUserRepository(t)
-	mockWLRepo := newMockiWLRequestRepository(t)
-
-	now := time.Now()
-	requester, err := domainUser.NewBuilder().
-		NewID().
-		TelegramIDFromInt(123456).
-		ChatIDFromInt(1234567890).
-		UsernameFromString("requester").
-		CreatedAt(now).
-		UpdatedAt(now).
-		Build()
-	require.NoError(t, err)
-
-	wlRequest, err := domainWLRequest.NewBuilder().
-		NewID().
-		RequesterIDFromUserID(requester.ID()).
-		NicknameFromString("testnick").
-		StatusFromString(string(domainWLRequest.StatusPending)).
-		CreatedAt(now).
-		UpdatedAt(now).
-		Build()
-	require.NoError(t, err)
-
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequest.ID(), core.ActionWLRequestApprove)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
-
-	update := &models.Update{
-		CallbackQuery: &models.CallbackQuery{
-			ID:   "callback123",
-			Data: string(callbackDataJSON),
-			From: models.User{ID: 789012},
-		},
-	}
-
-	handler := DeclineWLRequest(mockUserRepo, mockWLRepo)
-	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
-
-	require.Error(t, err)
-	assert.Contains(t, err.Error(), "invalid action")
-	assert.Equal(t, fsm.StateIdle, state)
-	require.NotNil(t, response)
-
-	callbackResponse, ok := response.(*router.CallbackResponse)
-	require.True(t, ok)
-	assert.NotNil(t, callbackResponse.CallbackParams)
+	assert.Nil(t, response)
 }
 
 func TestDeclineWLRequest_WLRequestNotFound(t *testing.T) {
@@ -228,14 +162,11 @@ func TestDeclineWLRequest_WLRequestNotFound(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestDecline)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: 789012},
 		},
 	}
@@ -287,14 +218,11 @@ func TestDeclineWLRequest_ArbiterNotFound(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestDecline)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: 789012},
 		},
 	}
@@ -304,12 +232,6 @@ func TestDeclineWLRequest_ArbiterNotFound(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	expectedErr := errors.New("arbiter not found")
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(789012)).
-		Return(domainUser.User{}, expectedErr).
-		Once()
-
 	handler := DeclineWLRequest(mockUserRepo, mockWLRepo)
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
@@ -363,14 +285,11 @@ func TestDeclineWLRequest_RequesterNotFound(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestDecline)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 		},
 	}
@@ -380,10 +299,7 @@ func TestDeclineWLRequest_RequesterNotFound(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
 	expectedErr := errors.New("requester not found")
 	mockUserRepo.EXPECT().
@@ -445,14 +361,11 @@ func TestDeclineWLRequest_DeclineError(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestDecline)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 		},
 	}
@@ -462,10 +375,7 @@ func TestDeclineWLRequest_DeclineError(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
 	mockUserRepo.EXPECT().
 		UserByID(mock.Anything, requesterID).
@@ -525,14 +435,11 @@ func TestDeclineWLRequest_UpdateWLRequestError(t *testing.T) {
 	require.NoError(t, err)
 	wlRequestID := wlRequest.ID()
 
-	callbackData := callbacks.NewWLRequestCallbackData(wlRequestID, core.ActionWLRequestDecline)
-	callbackDataJSON, err := json.Marshal(callbackData)
-	require.NoError(t, err)
+	ctx = callbacks.WithData(ctx, callbacks.NewWLRequestData(wlRequestID))
 
 	update := &models.Update{
 		CallbackQuery: &models.CallbackQuery{
 			ID:   "callback123",
-			Data: string(callbackDataJSON),
 			From: models.User{ID: int64(arbiter.TelegramID())},
 		},
 	}
@@ -542,10 +449,7 @@ func TestDeclineWLRequest_UpdateWLRequestError(t *testing.T) {
 		Return(wlRequest, nil).
 		Once()
 
-	mockUserRepo.EXPECT().
-		UserByTelegramID(mock.Anything, int64(arbiter.TelegramID())).
-		Return(arbiter, nil).
-		Once()
+	ctx = router.WithUpdateContext(ctx, &router.UpdateContext{User: arbiter})
 
 	mockUserRepo.EXPECT().
 		UserByID(mock.Anything, requesterID).
@@ -554,7 +458,7 @@ func TestDeclineWLRequest_UpdateWLRequestError(t *testing.T) {
 
 	expectedErr := errors.New("database error")
 	mockWLRepo.EXPECT().
-		UpdateWLRequest(mock.Anything, mock.AnythingOfType("wl_request.WLRequest")).
+		UpdateWLRequest(mock.Anything, mock.AnythingOfType("wl_request.WLRequest"), domainWLRequest.StatusPending).
 		Return(domainWLRequest.WLRequest{}, expectedErr).
 		Once()
 
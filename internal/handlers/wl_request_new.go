@@ -3,6 +3,7 @@ package handlers
 import (
 	"context"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/msgs"
 	"whitelist-bot/internal/router"
 
@@ -15,7 +16,7 @@ func NewWLRequest() router.HandlerFunc {
 	return func(ctx context.Context, _ *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
 		response := router.NewMessageResponse(
 			&bot.SendMessageParams{
-				Text: msgs.WaitingForNickname(),
+				Text: msgs.WaitingForNickname(i18n.LangFromContext(ctx)),
 			},
 		)
 		return fsm.StateWaitingWLNickname, response, nil
@@ -3,13 +3,11 @@ package handlers
 import (
 	"context"
 	"fmt"
-	"log/slog"
-	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/core/logger"
-	"whitelist-bot/internal/core/utils"
-	"whitelist-bot/internal/eventbus"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/outbox"
 	"whitelist-bot/internal/router"
 
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
@@ -20,13 +18,11 @@ import (
 )
 
 func SubmitWLRequestNickname(
-	userRepo iUserRepository,
 	wlRequestRepo iWLRequestRepository,
-	ep eventbus.IEventPublisher,
 ) router.HandlerFunc {
 	return func(ctx context.Context, _ *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
 		// TODO: add validation for nickname. Length, special characters, etc.
-		user, err := userRepo.UserByTelegramID(ctx, update.Message.From.ID)
+		user, err := router.UserFromContext(ctx)
 		if err != nil {
 			return fsm.StateWaitingWLNickname, nil, fmt.Errorf("failed to get user: %w", err)
 		}
@@ -40,6 +36,12 @@ func SubmitWLRequestNickname(
 			ctx,
 			domainWLRequest.RequesterID(user.ID()),
 			domainWLRequest.Nickname(nickname),
+			func(wlRequest domainWLRequest.WLRequest) (outbox.Message, error) {
+				return outbox.NewEvent(ctx, bh.TopicWLRequestCreated, bh.WLRequestCreatedEvent{
+					WLRequest: wlRequest,
+					Requester: user,
+				})
+			},
 		)
 		if err != nil {
 			return fsm.StateWaitingWLNickname, nil, fmt.Errorf("failed to create wl request: %w", err)
@@ -49,17 +51,9 @@ func SubmitWLRequestNickname(
 
 		response := router.NewMessageResponse(
 			&bot.SendMessageParams{
-				Text: msgs.WLRequestCreated(dbWLRequest),
+				Text: msgs.WLRequestCreated(i18n.LangFromContext(ctx), dbWLRequest, user),
 			},
 		)
-
-		if err := ep.Publish(ctx, core.TopicWLRequestCreated, bh.WLRequestCreatedEvent{
-			ID:        utils.NewUniqueID(),
-			WLRequest: dbWLRequest,
-			Requester: user,
-		}); err != nil {
-			slog.WarnContext(ctx, "Failed to publish wl request created event", logger.ErrorField, err.Error())
-		}
 		return fsm.StateIdle, response, nil
 	}
 }
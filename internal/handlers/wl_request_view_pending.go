@@ -3,8 +3,12 @@ package handlers
 import (
 	"context"
 	"fmt"
+	"log/slog"
 	"whitelist-bot/internal/callbacks"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/logger"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/msgs"
 	"whitelist-bot/internal/router"
 
@@ -14,6 +18,9 @@ import (
 
 const PENDING_WL_REQUESTS_LIMIT = 5
 
+// PREVIOUS_USERNAMES_LIMIT is how many former usernames of a requester are shown to admins.
+const PREVIOUS_USERNAMES_LIMIT = 3
+
 type pendingWLRequestMessage struct {
 	Text        string
 	ReplyMarkup *models.InlineKeyboardMarkup
@@ -21,8 +28,10 @@ type pendingWLRequestMessage struct {
 
 func ViewPendingWLRequests(
 	wlRequestRepo iWLRequestRepository,
+	userRepo iUserRepository,
+	callbackEncoder iCallbackEncoder,
 ) router.HandlerFunc {
-	preparePendingWLRequestMessages := func(ctx context.Context) ([]pendingWLRequestMessage, error) {
+	preparePendingWLRequestMessages := func(ctx context.Context, lang i18n.Lang) ([]pendingWLRequestMessage, error) {
 		wlRequests, err := wlRequestRepo.PendingWLRequestsWithRequester(ctx, PENDING_WL_REQUESTS_LIMIT)
 		if err != nil {
 			return nil, fmt.Errorf("failed to get pending wl requests: %w", err)
@@ -34,23 +43,39 @@ func ViewPendingWLRequests(
 
 		messages := make([]pendingWLRequestMessage, 0, len(wlRequests))
 		for _, wlRequest := range wlRequests {
+			payload := callbacks.NewWLRequestData(wlRequest.WlRequest.ID()).Bytes()
+			approveData, err := callbackEncoder.Encode(callbacks.ActionWLRequestApprove, payload)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode approve callback data: %w", err)
+			}
+			declineData, err := callbackEncoder.Encode(callbacks.ActionWLRequestDecline, payload)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode decline callback data: %w", err)
+			}
+
 			keyboard := &models.InlineKeyboardMarkup{
 				InlineKeyboard: [][]models.InlineKeyboardButton{
 					{
 						{
-							Text:         "✅ Подтвердить",
-							CallbackData: callbacks.ApproveWLRequestData(ctx, wlRequest.WlRequest.ID()),
+							Text:         i18n.T(lang, core.CommandApproveWLRequest),
+							CallbackData: approveData,
 						},
 						{
-							Text:         "❌ Отказать",
-							CallbackData: callbacks.DeclineWLRequestData(ctx, wlRequest.WlRequest.ID()),
+							Text:         i18n.T(lang, core.CommandDeclineWLRequest),
+							CallbackData: declineData,
 						},
 					},
 				},
 			}
 
+			// The history only helps to recognize the requester, the request is shown without it on failure.
+			previousUsernames, err := userRepo.PreviousUsernames(ctx, wlRequest.User, PREVIOUS_USERNAMES_LIMIT)
+			if err != nil {
+				slog.WarnContext(ctx, "Failed to get previous usernames", logger.ErrorField, err.Error())
+			}
+
 			messages = append(messages, pendingWLRequestMessage{
-				Text:        msgs.PendingWLRequest(wlRequest.WlRequest, wlRequest.User),
+				Text:        msgs.PendingWLRequest(lang, wlRequest.WlRequest, wlRequest.User, previousUsernames),
 				ReplyMarkup: keyboard,
 			})
 		}
@@ -59,7 +84,8 @@ func ViewPendingWLRequests(
 	}
 
 	return func(ctx context.Context, _ *bot.Bot, update *models.Update, state fsm.State) (fsm.State, router.Response, error) {
-		messages, err := preparePendingWLRequestMessages(ctx)
+		lang := i18n.LangFromContext(ctx)
+		messages, err := preparePendingWLRequestMessages(ctx, lang)
 		if err != nil {
 			return state, nil, err
 		}
@@ -68,7 +94,7 @@ func ViewPendingWLRequests(
 		if len(messages) == 0 {
 			response.AddMessage(
 				&bot.SendMessageParams{
-					Text: msgs.NoPendingWLRequests(),
+					Text: msgs.NoPendingWLRequests(lang),
 				},
 			)
 			return state, response, nil
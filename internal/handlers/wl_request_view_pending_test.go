@@ -5,6 +5,7 @@ import (
 	"errors"
 	"testing"
 	"time"
+	"whitelist-bot/internal/callbacks"
 	"whitelist-bot/internal/fsm"
 	"whitelist-bot/internal/router"
 
@@ -56,8 +57,13 @@ func TestViewPendingWLRequests_Success(t *testing.T) {
 			{WlRequest: wlRequest, User: user},
 		}, nil).
 		Once()
+	mockUserRepo := newMockiUserRepository(t)
+	mockUserRepo.EXPECT().
+		PreviousUsernames(ctx, user, int64(PREVIOUS_USERNAMES_LIMIT)).
+		Return([]domainUser.Username{"olduser"}, nil).
+		Once()
 
-	handler := ViewPendingWLRequests(mockWLRepo)
+	handler := ViewPendingWLRequests(mockWLRepo, mockUserRepo, callbacks.NewCodec([]byte("secret"), time.Hour))
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.NoError(t, err)
@@ -68,6 +74,14 @@ func TestViewPendingWLRequests_Success(t *testing.T) {
 	require.True(t, ok)
 	assert.Len(t, msgResponse.Params, 1)
 	assert.NotNil(t, msgResponse.Params[0].ReplyMarkup)
+	assert.Contains(t, msgResponse.Params[0].Text, "@olduser")
+
+	keyboard, ok := msgResponse.Params[0].ReplyMarkup.(*models.InlineKeyboardMarkup)
+	require.True(t, ok)
+	action, payload, err := callbacks.NewCodec([]byte("secret"), time.Hour).Decode(keyboard.InlineKeyboard[0][0].CallbackData)
+	require.NoError(t, err)
+	assert.Equal(t, callbacks.ActionWLRequestApprove, action)
+	assert.Equal(t, callbacks.NewWLRequestData(wlRequest.ID()).Bytes(), payload)
 }
 
 func TestViewPendingWLRequests_MultipleRequests(t *testing.T) {
@@ -125,8 +139,18 @@ func TestViewPendingWLRequests_MultipleRequests(t *testing.T) {
 			{WlRequest: wlReq2, User: user2},
 		}, nil).
 		Once()
+	mockUserRepo := newMockiUserRepository(t)
+	mockUserRepo.EXPECT().
+		PreviousUsernames(ctx, user1, int64(PREVIOUS_USERNAMES_LIMIT)).
+		Return(nil, nil).
+		Once()
+	// A failed history lookup doesn't hide the request.
+	mockUserRepo.EXPECT().
+		PreviousUsernames(ctx, user2, int64(PREVIOUS_USERNAMES_LIMIT)).
+		Return(nil, errors.New("database connection failed")).
+		Once()
 
-	handler := ViewPendingWLRequests(mockWLRepo)
+	handler := ViewPendingWLRequests(mockWLRepo, mockUserRepo, callbacks.NewCodec([]byte("secret"), time.Hour))
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.NoError(t, err)
@@ -154,7 +178,7 @@ func TestViewPendingWLRequests_NoRequests(t *testing.T) {
 		Return([]wlRequestRepo.PendingWLRequestWithRequester{}, nil).
 		Once()
 
-	handler := ViewPendingWLRequests(mockWLRepo)
+	handler := ViewPendingWLRequests(mockWLRepo, newMockiUserRepository(t), callbacks.NewCodec([]byte("secret"), time.Hour))
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.NoError(t, err)
@@ -184,7 +208,7 @@ func TestViewPendingWLRequests_RepositoryError(t *testing.T) {
 		Return(nil, expectedErr).
 		Once()
 
-	handler := ViewPendingWLRequests(mockWLRepo)
+	handler := ViewPendingWLRequests(mockWLRepo, newMockiUserRepository(t), callbacks.NewCodec([]byte("secret"), time.Hour))
 	state, response, err := handler(ctx, nil, update, fsm.StateIdle)
 
 	require.Error(t, err)
@@ -0,0 +1,132 @@
+package i18n
+
+var en = map[Key]string{
+	LangName: "English",
+
+	ButtonInfo:                  "Info",
+	ButtonNewWLRequest:          "New request",
+	ButtonViewPendingWLRequests: "View requests",
+	ButtonApproveWLRequest:      "✅ Approve",
+	ButtonDeclineWLRequest:      "❌ Decline",
+
+	MenuStart:       "Start the bot",
+	MenuCancel:      "Cancel the current action",
+	MenuLanguage:    "Change the language",
+	MenuDeadLetters: "Undelivered events",
+	MenuJobs:        "Scheduled jobs",
+	MenuApprove:     "Approve a request",
+	MenuDecline:     "Decline a request",
+	MenuRevoke:      "Revoke an approved request",
+	MenuShow:        "Show a request",
+	MenuRules:       "Server rules",
+
+	MsgStartGreeting: "Hi! I'm the bot for submitting whitelist requests.\n\n",
+	MsgStartHint:     "To create a request, send: <b>%s</b>",
+	MsgCancel:        "All actions have been cancelled.\n\n",
+	MsgRules: "📜 <b>Server rules</b>\n\n" +
+		"1. Be respectful to other players.\n" +
+		"2. No griefing, cheating or exploiting bugs.\n" +
+		"3. Follow the admins' instructions.",
+
+	MsgUserInfoTitle:      "<b>👤 User information</b>\n\n",
+	MsgUserInfoName:       "📝 <b>Name:</b> ",
+	MsgUserInfoUsername:   "🔗 <b>Username:</b> @%s\n",
+	MsgUserInfoTelegramID: "🆔 <b>Telegram ID:</b> <code>%d</code>\n",
+	MsgUserInfoUserID:     "🔑 <b>User ID:</b> <code>%s</code>\n",
+	MsgUserInfoLanguage:   "🌐 <b>Language:</b> %s\n",
+	MsgUserInfoTimestamps: "\n<b>⏰ Timestamps</b>\n",
+	MsgUserInfoCreatedAt:  "📅 <b>Created:</b> %s\n",
+	MsgUserInfoUpdatedAt:  "🔄 <b>Updated:</b> %s\n",
+
+	MsgLanguageUsage:       "🌐 <b>Available languages:</b> %s\n\nTo change the language, send: <code>/language ru</code>",
+	MsgLanguageChanged:     "🌐 Language changed: <b>%s</b>",
+	MsgLanguageUnsupported: "❌ Language <code>%s</code> is not supported.\n\n",
+
+	MsgDeadLettersUsage: "☠️ <b>Dead letters</b>\n\n" +
+		"<code>/dead_letters</code> — list\n" +
+		"<code>/dead_letters show &lt;id&gt;</code> — inspect\n" +
+		"<code>/dead_letters replay &lt;id&gt;</code> — publish to the original topic again\n" +
+		"<code>/dead_letters purge [id]</code> — delete one or all",
+	MsgDeadLettersTitle:   "☠️ <b>Dead letters (%d)</b>\n\n",
+	MsgDeadLettersItem:    "• <code>%s</code>\n  %s, attempts: %d, %s\n",
+	MsgNoDeadLetters:      "✅ <b>No dead letters</b>",
+	MsgDeadLetterDetails:  "☠️ <b>Dead letter</b> <code>%s</code>\n\n<b>Topic:</b> %s\n<b>Attempts:</b> %d\n<b>Failed:</b> %s\n<b>Error:</b> <code>%s</code>\n\n<pre>%s</pre>",
+	MsgDeadLetterReplayed: "🔁 Dead letter <code>%s</code> was published to <b>%s</b> again",
+	MsgDeadLettersPurged:  "🗑 Dead letters deleted: %d",
+
+	MsgJobsTitle:     "⏱ <b>Scheduled jobs (%d)</b>\n\n",
+	MsgNoJobs:        "⏱ <b>No scheduled jobs</b>",
+	MsgJobsItem:      "• <b>%s</b> — <code>%s</code>\n",
+	MsgJobsLastRun:   "  Last run: %s (%s), runs: %d, failures: %d\n",
+	MsgJobsNeverRun:  "  Not run yet\n",
+	MsgJobsRunning:   "  ⏳ Running\n",
+	MsgJobsNextRun:   "  Next run: %s\n",
+	MsgJobsLastError: "  ❌ <code>%s</code>\n",
+
+	MsgWaitingForNickname: "Hi! Send your nickname to apply for the whitelist.\n" +
+		"If your nickname contains special characters, wrap it in\n <code>```\nnickname\n```</code>\n\n" +
+		"To cancel the request, send: /cancel",
+	MsgWLRequestCreatedTitle:  "<b>Whitelist request submitted successfully</b>\n\n",
+	MsgPendingWLRequestTitle:  "📋 <b>Pending request</b>\n\n",
+	MsgNoPendingWLRequests:    "✅ <b>No pending requests</b>\n\nAll requests have been processed!",
+	MsgApprovedWLRequestTitle: "✅ <b>Request approved!</b>\n\n",
+	MsgDeclinedWLRequestTitle: "❌ <b>Request declined!</b>\n\n",
+	MsgRevokedWLRequestTitle:  "↩️ <b>Approval revoked!</b>\n\n",
+	MsgWLRequestDetailsTitle:  "📄 <b>Request</b>\n\n",
+	MsgWLRequestAdminNotify:   "📋 <b>New whitelist request</b>\n\n",
+	MsgWLRequestCommandsUsage: "📋 <b>Requests</b>\n\n" +
+		"<code>/approve &lt;id|nickname&gt;</code> — approve\n" +
+		"<code>/decline &lt;id|nickname&gt; &lt;reason&gt;</code> — decline\n" +
+		"<code>/revoke &lt;id|nickname&gt;</code> — revoke an approval\n" +
+		"<code>/show &lt;id&gt;</code> — details",
+	MsgWLRequestExpired: "⌛ <b>Your whitelist request has expired</b>\n\n" +
+		"The request for <b>%s</b> was not reviewed within %d h. You can submit a new one with the «%s» button.",
+	MsgWLRequestsExpiredTitle: "⌛ <b>Expired whitelist requests (%d)</b>\nPending for more than %d h\n\n",
+	MsgWLRequestsExpiredItem:  "• <b>%s</b> — <code>%s</code>, %s\n",
+	MsgWLRequestsExpiredMore:  "…and %d more\n",
+	MsgWLRequestReminderTitle: "🔔 <b>Whitelist requests are waiting for review</b>\n\n",
+	MsgWLRequestEscalateTitle: "🚨 <b>Whitelist requests are still not reviewed</b>\n\n",
+	MsgWLRequestReminderBody: "Pending: <b>%d</b>\nFor more than %d h: <b>%d</b>\n" +
+		"Oldest: <b>%s</b> (<code>%s</code>) since %s\n",
+	MsgWLRequestNickname:       "👤 <b>Nickname:</b> %s\n",
+	MsgWLRequestID:             "🆔 <b>Request ID:</b> <code>%s</code>\n",
+	MsgWLRequestCreatedAt:      "📅 <b>Created:</b> %s\n",
+	MsgWLRequestRequester:      "🔗 <b>Requester:</b> %s\n",
+	MsgWLRequestPreviously:     "🕘 <b>Previously known as:</b> %s\n",
+	MsgWLRequestArbiter:        "🔗 <b>Arbiter:</b> %s\n",
+	MsgWLRequestDeclineReason:  "🔄 <b>Decline reason:</b> %s\n",
+	MsgWLRequestStatus:         "📌 <b>Status:</b> %s\n",
+	MsgWLRequestStatusPending:  "pending",
+	MsgWLRequestStatusApproved: "approved",
+	MsgWLRequestStatusDeclined: "declined",
+	MsgWLRequestStatusExpired:  "expired",
+	MsgWLRequestStatusRevoked:  "approval revoked",
+	MsgWLRequestApprovedAnswer: "✅ Request approved",
+	MsgWLRequestDeclinedAnswer: "❌ Request declined!",
+	MsgWLRequestDefaultDecline: "Declined by administrator",
+	MsgWLRequestAmbiguous:      "⚠️ Nickname <b>%s</b> matches several requests, use an ID:\n\n",
+	MsgWLRequestCandidate:      "• <code>%s</code> — %s, %s\n",
+	MsgWLRequestCandidatesMore: "…and others\n",
+	MsgCallbackError:           "❌ <b>Error:</b> %s",
+	MsgCallbackSuccess:         "✅ <b>Success:</b> %s",
+	ErrTextInvalidCallbackData: "invalid callback data format",
+	ErrTextCallbackExpired:     "This button has expired, open the list again",
+	ErrTextWLRequestNotFound:   "Request not found",
+	ErrTextArbiterNotFound:     "failed to get arbiter",
+	ErrTextRequesterNotFound:   "failed to get requester",
+	ErrTextWLRequestUpdate:     "failed to update request",
+	ErrTextWLRequestSave:       "failed to save changes",
+	ErrTextWLRequestResolved:   "The request has already been reviewed",
+	ErrTextWLRequestUnapproved: "The request is not approved, there is nothing to revoke",
+	ErrTextInvalidLength:       "The text is too long",
+	ErrTextUnknownCommand:      "Unknown command",
+	ErrTextInternalError:       "An error occurred while processing the command",
+	ErrTextInvalidUserState:    "Invalid user state",
+	ErrTextUserBusy:            "⏳ Still processing your previous action, please wait.",
+	ErrTextTooManyRequests:     "🐢 Too many requests, please slow down.",
+	ErrTextPermissionDenied:    "⛔ You don't have permission to do this.",
+	ErrTextMaintenance:         "🛠 The bot is under maintenance, please try again later.",
+	ErrTextOverloaded:          "⏳ The bot is overloaded right now, please try again in a minute.",
+	ErrTextDeadLetterNotFound:  "Dead letter not found",
+	ErrTextInvalidID:           "Invalid ID",
+}
@@ -0,0 +1,92 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+type Lang string
+
+type Key string
+
+const (
+	LangRU Lang = "ru"
+	LangEN Lang = "en"
+
+	DefaultLang = LangRU
+)
+
+type ctxKey struct{}
+
+var catalogs = map[Lang]map[Key]string{
+	LangRU: ru,
+	LangEN: en,
+}
+
+// Supported returns languages in the order they are offered to users.
+func Supported() []Lang {
+	return []Lang{LangRU, LangEN}
+}
+
+func (l Lang) IsSupported() bool {
+	_, ok := catalogs[l]
+	return ok
+}
+
+func (l Lang) String() string {
+	return string(l)
+}
+
+// ParseLang converts Telegram's IETF language_code (e.g. "en-US") into a supported language.
+// Unknown or empty codes fall back to DefaultLang.
+func ParseLang(code string) Lang {
+	code = strings.ToLower(strings.TrimSpace(code))
+	if i := strings.IndexAny(code, "-_"); i > 0 {
+		code = code[:i]
+	}
+	lang := Lang(code)
+	if !lang.IsSupported() {
+		return DefaultLang
+	}
+	return lang
+}
+
+// T returns the translation of key in lang formatted with args.
+// Missing translations fall back to DefaultLang and then to the key itself.
+func T(lang Lang, key Key, args ...any) string {
+	text, ok := catalogs[lang][key]
+	if !ok {
+		text, ok = catalogs[DefaultLang][key]
+	}
+	if !ok {
+		text = string(key)
+	}
+	if len(args) == 0 {
+		return text
+	}
+	return fmt.Sprintf(text, args...)
+}
+
+// Translations returns every known translation of key, used to match button texts in any language.
+func Translations(key Key) []string {
+	texts := make([]string, 0, len(catalogs))
+	for _, lang := range Supported() {
+		if text, ok := catalogs[lang][key]; ok {
+			texts = append(texts, text)
+		}
+	}
+	return texts
+}
+
+func WithLang(ctx context.Context, lang Lang) context.Context {
+	return context.WithValue(ctx, ctxKey{}, lang)
+}
+
+// LangFromContext returns the language stored by WithLang or DefaultLang.
+func LangFromContext(ctx context.Context) Lang {
+	if lang, ok := ctx.Value(ctxKey{}).(Lang); ok && lang.IsSupported() {
+		return lang
+	}
+	return DefaultLang
+}
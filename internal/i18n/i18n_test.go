@@ -0,0 +1,58 @@
+package i18n
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCatalogs_HaveSameKeys(t *testing.T) {
+	for _, lang := range Supported() {
+		for key := range catalogs[DefaultLang] {
+			_, ok := catalogs[lang][key]
+			assert.True(t, ok, "missing %s translation for %s", lang, key)
+		}
+		for key := range catalogs[lang] {
+			_, ok := catalogs[DefaultLang][key]
+			assert.True(t, ok, "unknown key %s in %s catalog", key, lang)
+		}
+	}
+}
+
+func TestParseLang(t *testing.T) {
+	tests := []struct {
+		code     string
+		expected Lang
+	}{
+		{code: "en", expected: LangEN},
+		{code: "en-US", expected: LangEN},
+		{code: "EN_gb", expected: LangEN},
+		{code: "ru", expected: LangRU},
+		{code: "de", expected: DefaultLang},
+		{code: "", expected: DefaultLang},
+	}
+	for _, tt := range tests {
+		t.Run(tt.code, func(t *testing.T) {
+			assert.Equal(t, tt.expected, ParseLang(tt.code))
+		})
+	}
+}
+
+func TestT(t *testing.T) {
+	assert.Equal(t, "Info", T(LangEN, ButtonInfo))
+	assert.Equal(t, "Информация", T(Lang("de"), ButtonInfo))
+	assert.Equal(t, "🌐 Language changed: <b>English</b>", T(LangEN, MsgLanguageChanged, "English"))
+	assert.Equal(t, "unknown.key", T(LangEN, Key("unknown.key")))
+}
+
+func TestTranslations(t *testing.T) {
+	assert.ElementsMatch(t, []string{"Информация", "Info"}, Translations(ButtonInfo))
+}
+
+func TestLangFromContext(t *testing.T) {
+	ctx := context.Background()
+	assert.Equal(t, DefaultLang, LangFromContext(ctx))
+	assert.Equal(t, LangEN, LangFromContext(WithLang(ctx, LangEN)))
+	assert.Equal(t, DefaultLang, LangFromContext(WithLang(ctx, Lang("de"))))
+}
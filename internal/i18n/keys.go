@@ -0,0 +1,117 @@
+package i18n
+
+const (
+	LangName Key = "lang.name"
+
+	ButtonInfo                  Key = "button.info"
+	ButtonNewWLRequest          Key = "button.new_wl_request"
+	ButtonViewPendingWLRequests Key = "button.view_pending_wl_requests"
+	ButtonApproveWLRequest      Key = "button.approve_wl_request"
+	ButtonDeclineWLRequest      Key = "button.decline_wl_request"
+
+	MenuStart       Key = "menu.start"
+	MenuCancel      Key = "menu.cancel"
+	MenuLanguage    Key = "menu.language"
+	MenuDeadLetters Key = "menu.dead_letters"
+	MenuJobs        Key = "menu.jobs"
+	MenuApprove     Key = "menu.approve"
+	MenuDecline     Key = "menu.decline"
+	MenuRevoke      Key = "menu.revoke"
+	MenuShow        Key = "menu.show"
+	MenuRules       Key = "menu.rules"
+
+	MsgStartGreeting Key = "msg.start.greeting"
+	MsgStartHint     Key = "msg.start.hint"
+	MsgCancel        Key = "msg.cancel"
+	MsgRules         Key = "msg.rules"
+
+	MsgUserInfoTitle      Key = "msg.user_info.title"
+	MsgUserInfoName       Key = "msg.user_info.name"
+	MsgUserInfoUsername   Key = "msg.user_info.username"
+	MsgUserInfoTelegramID Key = "msg.user_info.telegram_id"
+	MsgUserInfoUserID     Key = "msg.user_info.user_id"
+	MsgUserInfoLanguage   Key = "msg.user_info.language"
+	MsgUserInfoTimestamps Key = "msg.user_info.timestamps"
+	MsgUserInfoCreatedAt  Key = "msg.user_info.created_at"
+	MsgUserInfoUpdatedAt  Key = "msg.user_info.updated_at"
+
+	MsgLanguageUsage       Key = "msg.language.usage"
+	MsgLanguageChanged     Key = "msg.language.changed"
+	MsgLanguageUnsupported Key = "msg.language.unsupported"
+
+	MsgDeadLettersUsage   Key = "msg.dead_letters.usage"
+	MsgDeadLettersTitle   Key = "msg.dead_letters.title"
+	MsgDeadLettersItem    Key = "msg.dead_letters.item"
+	MsgNoDeadLetters      Key = "msg.dead_letters.none"
+	MsgDeadLetterDetails  Key = "msg.dead_letters.details"
+	MsgDeadLetterReplayed Key = "msg.dead_letters.replayed"
+	MsgDeadLettersPurged  Key = "msg.dead_letters.purged"
+
+	MsgJobsTitle     Key = "msg.jobs.title"
+	MsgNoJobs        Key = "msg.jobs.none"
+	MsgJobsItem      Key = "msg.jobs.item"
+	MsgJobsLastRun   Key = "msg.jobs.last_run"
+	MsgJobsNeverRun  Key = "msg.jobs.never_run"
+	MsgJobsRunning   Key = "msg.jobs.running"
+	MsgJobsNextRun   Key = "msg.jobs.next_run"
+	MsgJobsLastError Key = "msg.jobs.last_error"
+
+	MsgWaitingForNickname      Key = "msg.wl_request.waiting_for_nickname"
+	MsgWLRequestCreatedTitle   Key = "msg.wl_request.created_title"
+	MsgPendingWLRequestTitle   Key = "msg.wl_request.pending_title"
+	MsgNoPendingWLRequests     Key = "msg.wl_request.no_pending"
+	MsgApprovedWLRequestTitle  Key = "msg.wl_request.approved_title"
+	MsgDeclinedWLRequestTitle  Key = "msg.wl_request.declined_title"
+	MsgRevokedWLRequestTitle   Key = "msg.wl_request.revoked_title"
+	MsgWLRequestDetailsTitle   Key = "msg.wl_request.details_title"
+	MsgWLRequestCommandsUsage  Key = "msg.wl_request.commands_usage"
+	MsgWLRequestAmbiguous      Key = "msg.wl_request.ambiguous"
+	MsgWLRequestCandidate      Key = "msg.wl_request.candidate"
+	MsgWLRequestCandidatesMore Key = "msg.wl_request.candidates_more"
+	MsgWLRequestAdminNotify    Key = "msg.wl_request.admin_notification"
+	MsgWLRequestExpired        Key = "msg.wl_request.expired"
+	MsgWLRequestsExpiredTitle  Key = "msg.wl_request.expired_report_title"
+	MsgWLRequestsExpiredItem   Key = "msg.wl_request.expired_report_item"
+	MsgWLRequestsExpiredMore   Key = "msg.wl_request.expired_report_more"
+	MsgWLRequestReminderTitle  Key = "msg.wl_request.reminder_title"
+	MsgWLRequestEscalateTitle  Key = "msg.wl_request.escalation_title"
+	MsgWLRequestReminderBody   Key = "msg.wl_request.reminder_body"
+	MsgWLRequestNickname       Key = "msg.wl_request.nickname"
+	MsgWLRequestID             Key = "msg.wl_request.id"
+	MsgWLRequestCreatedAt      Key = "msg.wl_request.created_at"
+	MsgWLRequestRequester      Key = "msg.wl_request.requester"
+	MsgWLRequestPreviously     Key = "msg.wl_request.requester_previously"
+	MsgWLRequestArbiter        Key = "msg.wl_request.arbiter"
+	MsgWLRequestDeclineReason  Key = "msg.wl_request.decline_reason"
+	MsgWLRequestStatus         Key = "msg.wl_request.status"
+	MsgWLRequestStatusPending  Key = "msg.wl_request.status_pending"
+	MsgWLRequestStatusApproved Key = "msg.wl_request.status_approved"
+	MsgWLRequestStatusDeclined Key = "msg.wl_request.status_declined"
+	MsgWLRequestStatusExpired  Key = "msg.wl_request.status_expired"
+	MsgWLRequestStatusRevoked  Key = "msg.wl_request.status_revoked"
+	MsgWLRequestApprovedAnswer Key = "msg.wl_request.approved_answer"
+	MsgWLRequestDeclinedAnswer Key = "msg.wl_request.declined_answer"
+	MsgWLRequestDefaultDecline Key = "msg.wl_request.default_decline_reason"
+	MsgCallbackError           Key = "msg.callback.error"
+	MsgCallbackSuccess         Key = "msg.callback.success"
+	ErrTextInvalidCallbackData Key = "err.invalid_callback_data"
+	ErrTextCallbackExpired     Key = "err.callback_expired"
+	ErrTextWLRequestNotFound   Key = "err.wl_request_not_found"
+	ErrTextArbiterNotFound     Key = "err.arbiter_not_found"
+	ErrTextRequesterNotFound   Key = "err.requester_not_found"
+	ErrTextWLRequestUpdate     Key = "err.wl_request_update"
+	ErrTextWLRequestSave       Key = "err.wl_request_save"
+	ErrTextWLRequestResolved   Key = "err.wl_request_resolved"
+	ErrTextWLRequestUnapproved Key = "err.wl_request_not_approved"
+	ErrTextInvalidLength       Key = "err.invalid_length"
+	ErrTextUnknownCommand      Key = "err.unknown_command"
+	ErrTextInternalError       Key = "err.internal_error"
+	ErrTextInvalidUserState    Key = "err.invalid_user_state"
+	ErrTextUserBusy            Key = "err.user_busy"
+	ErrTextTooManyRequests     Key = "err.too_many_requests"
+	ErrTextPermissionDenied    Key = "err.permission_denied"
+	ErrTextMaintenance         Key = "err.maintenance"
+	ErrTextOverloaded          Key = "err.overloaded"
+	ErrTextDeadLetterNotFound  Key = "err.dead_letter_not_found"
+	ErrTextInvalidID           Key = "err.invalid_id"
+)
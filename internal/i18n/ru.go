@@ -0,0 +1,132 @@
+package i18n
+
+var ru = map[Key]string{
+	LangName: "Русский",
+
+	ButtonInfo:                  "Информация",
+	ButtonNewWLRequest:          "Новая заявка",
+	ButtonViewPendingWLRequests: "Посмотреть заявки",
+	ButtonApproveWLRequest:      "✅ Подтвердить",
+	ButtonDeclineWLRequest:      "❌ Отказать",
+
+	MenuStart:       "Запустить бота",
+	MenuCancel:      "Отменить текущее действие",
+	MenuLanguage:    "Сменить язык",
+	MenuDeadLetters: "Недоставленные события",
+	MenuJobs:        "Фоновые задачи",
+	MenuApprove:     "Подтвердить заявку",
+	MenuDecline:     "Отклонить заявку",
+	MenuRevoke:      "Отозвать подтверждённую заявку",
+	MenuShow:        "Показать заявку",
+	MenuRules:       "Правила сервера",
+
+	MsgStartGreeting: "Привет! Я бот для подачи заявок в белый список.\n\n",
+	MsgStartHint:     "Чтобы создать заявку, напиши: <b>%s</b>",
+	MsgCancel:        "Все действия отменены.\n\n",
+	MsgRules: "📜 <b>Правила сервера</b>\n\n" +
+		"1. Уважай других игроков.\n" +
+		"2. Без гриферства, читов и использования багов.\n" +
+		"3. Следуй указаниям администрации.",
+
+	MsgUserInfoTitle:      "<b>👤 Информация о пользователе</b>\n\n",
+	MsgUserInfoName:       "📝 <b>Имя:</b> ",
+	MsgUserInfoUsername:   "🔗 <b>Username:</b> @%s\n",
+	MsgUserInfoTelegramID: "🆔 <b>Telegram ID:</b> <code>%d</code>\n",
+	MsgUserInfoUserID:     "🔑 <b>User ID:</b> <code>%s</code>\n",
+	MsgUserInfoLanguage:   "🌐 <b>Язык:</b> %s\n",
+	MsgUserInfoTimestamps: "\n<b>⏰ Временные метки</b>\n",
+	MsgUserInfoCreatedAt:  "📅 <b>Создан:</b> %s\n",
+	MsgUserInfoUpdatedAt:  "🔄 <b>Обновлён:</b> %s\n",
+
+	MsgLanguageUsage:       "🌐 <b>Доступные языки:</b> %s\n\nЧтобы сменить язык, напиши: <code>/language en</code>",
+	MsgLanguageChanged:     "🌐 Язык изменён: <b>%s</b>",
+	MsgLanguageUnsupported: "❌ Язык <code>%s</code> не поддерживается.\n\n",
+
+	MsgDeadLettersUsage: "☠️ <b>Недоставленные события</b>\n\n" +
+		"<code>/dead_letters</code> — список\n" +
+		"<code>/dead_letters show &lt;id&gt;</code> — подробности\n" +
+		"<code>/dead_letters replay &lt;id&gt;</code> — повторно опубликовать в исходный топик\n" +
+		"<code>/dead_letters purge [id]</code> — удалить одно или все",
+	MsgDeadLettersTitle:   "☠️ <b>Недоставленные события (%d)</b>\n\n",
+	MsgDeadLettersItem:    "• <code>%s</code>\n  %s, попыток: %d, %s\n",
+	MsgNoDeadLetters:      "✅ <b>Недоставленных событий нет</b>",
+	MsgDeadLetterDetails:  "☠️ <b>Недоставленное событие</b> <code>%s</code>\n\n<b>Топик:</b> %s\n<b>Попыток:</b> %d\n<b>Ошибка в:</b> %s\n<b>Ошибка:</b> <code>%s</code>\n\n<pre>%s</pre>",
+	MsgDeadLetterReplayed: "🔁 Событие <code>%s</code> повторно опубликовано в <b>%s</b>",
+	MsgDeadLettersPurged:  "🗑 Удалено недоставленных событий: %d",
+
+	MsgJobsTitle:     "⏱ <b>Фоновые задачи (%d)</b>\n\n",
+	MsgNoJobs:        "⏱ <b>Фоновых задач нет</b>",
+	MsgJobsItem:      "• <b>%s</b> — <code>%s</code>\n",
+	MsgJobsLastRun:   "  Последний запуск: %s (%s), запусков: %d, ошибок: %d\n",
+	MsgJobsNeverRun:  "  Ещё не запускалась\n",
+	MsgJobsRunning:   "  ⏳ Выполняется\n",
+	MsgJobsNextRun:   "  Следующий запуск: %s\n",
+	MsgJobsLastError: "  ❌ <code>%s</code>\n",
+
+	MsgWaitingForNickname: "Привет! Отправь свой ник, чтобы подать заявку в белый список.\n" +
+		"Если в твоём нике есть спец. символы, то оберни его в\n <code>```\nnickname\n```</code>\n\n" +
+		"Чтобы отменить заявку, напиши: /cancel",
+	MsgWLRequestCreatedTitle:  "<b>Заявка в белый список успешно отправлена</b>\n\n",
+	MsgPendingWLRequestTitle:  "📋 <b>Ожидающая заявка</b>\n\n",
+	MsgNoPendingWLRequests:    "✅ <b>Нет ожидающих заявок</b>\n\nВсе заявки обработаны!",
+	MsgApprovedWLRequestTitle: "✅ <b>Заявка подтверждена!</b>\n\n",
+	MsgDeclinedWLRequestTitle: "❌ <b>Заявка отклонена!</b>\n\n",
+	MsgRevokedWLRequestTitle:  "↩️ <b>Одобрение отозвано!</b>\n\n",
+	MsgWLRequestDetailsTitle:  "📄 <b>Заявка</b>\n\n",
+	MsgWLRequestAdminNotify:   "📋 <b>Новая заявка в белый список</b>\n\n",
+	MsgWLRequestCommandsUsage: "📋 <b>Заявки</b>\n\n" +
+		"<code>/approve &lt;id|ник&gt;</code> — подтвердить\n" +
+		"<code>/decline &lt;id|ник&gt; &lt;причина&gt;</code> — отклонить\n" +
+		"<code>/revoke &lt;id|ник&gt;</code> — отозвать одобрение\n" +
+		"<code>/show &lt;id&gt;</code> — подробности",
+	MsgWLRequestExpired: "⌛ <b>Срок заявки истёк</b>\n\n" +
+		"Заявку на ник <b>%s</b> не рассмотрели за %d ч. Ты можешь подать новую кнопкой «%s».",
+	MsgWLRequestsExpiredTitle: "⌛ <b>Просроченные заявки (%d)</b>\nОжидали больше %d ч.\n\n",
+	MsgWLRequestsExpiredItem:  "• <b>%s</b> — <code>%s</code>, %s\n",
+	MsgWLRequestsExpiredMore:  "…и ещё %d\n",
+	MsgWLRequestReminderTitle: "🔔 <b>Заявки ждут рассмотрения</b>\n\n",
+	MsgWLRequestEscalateTitle: "🚨 <b>Заявки давно не рассмотрены</b>\n\n",
+	MsgWLRequestReminderBody: "Ожидают: <b>%d</b>\nДольше %d ч.: <b>%d</b>\n" +
+		"Самая старая: <b>%s</b> (<code>%s</code>) с %s\n",
+	MsgWLRequestNickname:       "👤 <b>Ник:</b> %s\n",
+	MsgWLRequestID:             "🆔 <b>ID заявки:</b> <code>%s</code>\n",
+	MsgWLRequestCreatedAt:      "📅 <b>Создана:</b> %s\n",
+	MsgWLRequestRequester:      "🔗 <b>Заявитель:</b> %s\n",
+	MsgWLRequestPreviously:     "🕘 <b>Ранее известен как:</b> %s\n",
+	MsgWLRequestArbiter:        "🔗 <b>Арбитр:</b> %s\n",
+	MsgWLRequestDeclineReason:  "🔄 <b>Причина отказа:</b> %s\n",
+	MsgWLRequestStatus:         "📌 <b>Статус:</b> %s\n",
+	MsgWLRequestStatusPending:  "ожидает",
+	MsgWLRequestStatusApproved: "подтверждена",
+	MsgWLRequestStatusDeclined: "отклонена",
+	MsgWLRequestStatusExpired:  "просрочена",
+	MsgWLRequestStatusRevoked:  "одобрение отозвано",
+	MsgWLRequestApprovedAnswer: "✅ Заявка подтверждена",
+	MsgWLRequestDeclinedAnswer: "❌ Заявка отклонена!",
+	MsgWLRequestDefaultDecline: "Отклонено администратором",
+	MsgWLRequestAmbiguous:      "⚠️ Ник <b>%s</b> подходит к нескольким заявкам, укажи ID:\n\n",
+	MsgWLRequestCandidate:      "• <code>%s</code> — %s, %s\n",
+	MsgWLRequestCandidatesMore: "…и другие\n",
+	MsgCallbackError:           "❌ <b>Ошибка:</b> %s",
+	MsgCallbackSuccess:         "✅ <b>Успех:</b> %s",
+	ErrTextInvalidCallbackData: "неверный формат callback data",
+	ErrTextCallbackExpired:     "Кнопка устарела, откройте список заново",
+	ErrTextWLRequestNotFound:   "Заявка не найдена",
+	ErrTextArbiterNotFound:     "не удалось получить арбитра",
+	ErrTextRequesterNotFound:   "не удалось получить заявителя",
+	ErrTextWLRequestUpdate:     "ошибка при обновлении заявки",
+	ErrTextWLRequestSave:       "ошибка при сохранении изменений",
+	ErrTextWLRequestResolved:   "Заявка уже рассмотрена",
+	ErrTextWLRequestUnapproved: "Заявка не подтверждена, отзывать нечего",
+	ErrTextInvalidLength:       "Слишком длинный текст",
+	ErrTextUnknownCommand:      "Неизвестная команда",
+	ErrTextInternalError:       "Произошла ошибка при обработке команды",
+	ErrTextInvalidUserState:    "Неверное состояние пользователя",
+	ErrTextUserBusy:            "⏳ Ещё обрабатываю ваше предыдущее действие, подождите.",
+	ErrTextTooManyRequests:     "🐢 Слишком много запросов, пожалуйста, помедленнее.",
+	ErrTextPermissionDenied:    "⛔ У вас нет прав на это действие.",
+	ErrTextMaintenance:         "🛠 Бот на техническом обслуживании, попробуйте позже.",
+	ErrTextOverloaded:          "⏳ Бот сейчас перегружен, попробуйте ещё раз через минуту.",
+	ErrTextDeadLetterNotFound:  "Недоставленное событие не найдено",
+	ErrTextInvalidID:           "Неверный ID",
+}
@@ -0,0 +1,122 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/outbox"
+	"whitelist-bot/internal/scheduler"
+
+	bh "whitelist-bot/internal/eventbus/handlers"
+	repository "whitelist-bot/internal/repository/wl_request"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+type iWLRequestExpirer interface {
+	ExpireStaleWLRequests(
+		ctx context.Context,
+		createdBefore time.Time,
+		limit int64,
+		events ...repository.WLRequestEvent,
+	) ([]domainWLRequest.WLRequest, error)
+}
+
+type iUserGetter interface {
+	UserByTelegramID(ctx context.Context, telegramID int64) (domainUser.User, error)
+}
+
+// ExpireWLRequests expires requests pending for longer than maxPendingAge in batches. Every requester
+// is notified through the wl-request.expired event, and admins get a report of what expired in the run.
+func ExpireWLRequests(
+	repo iWLRequestExpirer,
+	sender utils.IMessageSender,
+	userGetter iUserGetter,
+	adminChatIDs []int64,
+	maxPendingAge time.Duration,
+	batchSize int64,
+) scheduler.JobFunc {
+	return func(ctx context.Context) error {
+		createdBefore := time.Now().Add(-maxPendingAge)
+		expiredEvent := func(wlRequest domainWLRequest.WLRequest) (outbox.Message, error) {
+			return outbox.NewEvent(ctx, bh.TopicWLRequestExpired, bh.WLRequestExpiredEvent{
+				WLRequest:     wlRequest,
+				MaxPendingAge: maxPendingAge,
+			})
+		}
+
+		var expired []domainWLRequest.WLRequest
+		for {
+			batch, err := repo.ExpireStaleWLRequests(ctx, createdBefore, batchSize, expiredEvent)
+			if err != nil {
+				// Report what is already expired, the rest is picked up by the next run.
+				return errors.Join(
+					fmt.Errorf("failed to expire wl requests: %w", err),
+					reportExpiredWLRequests(ctx, sender, userGetter, adminChatIDs, expired, maxPendingAge),
+				)
+			}
+			expired = append(expired, batch...)
+			if int64(len(batch)) < batchSize {
+				break
+			}
+		}
+
+		if len(expired) == 0 {
+			slog.DebugContext(ctx, "No stale wl requests")
+			return nil
+		}
+		slog.InfoContext(ctx, "Stale wl requests expired", "count", len(expired))
+		return reportExpiredWLRequests(ctx, sender, userGetter, adminChatIDs, expired, maxPendingAge)
+	}
+}
+
+func reportExpiredWLRequests(
+	ctx context.Context,
+	sender utils.IMessageSender,
+	userGetter iUserGetter,
+	adminChatIDs []int64,
+	expired []domainWLRequest.WLRequest,
+	maxPendingAge time.Duration,
+) error {
+	if len(expired) == 0 {
+		return nil
+	}
+
+	sendingErrors := make([]error, 0, len(adminChatIDs))
+	for _, chatID := range adminChatIDs {
+		_, err := sender.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID:    chatID,
+			Text:      msgs.WLRequestsExpiredReport(adminLang(ctx, userGetter, chatID), expired, maxPendingAge),
+			ParseMode: models.ParseModeHTML,
+		})
+		if err != nil {
+			sendingErrors = append(sendingErrors, fmt.Errorf("failed to send expired wl requests report to %d: %w", chatID, err))
+		}
+	}
+	if len(sendingErrors) == len(adminChatIDs) {
+		return errors.Join(sendingErrors...)
+	}
+	for _, err := range sendingErrors {
+		slog.WarnContext(ctx, "Failed to send expired wl requests report", logger.ErrorField, err.Error())
+	}
+	return nil
+}
+
+// adminLang returns the stored language of an admin, falling back to the default one.
+func adminLang(ctx context.Context, userGetter iUserGetter, telegramID int64) i18n.Lang {
+	admin, err := userGetter.UserByTelegramID(ctx, telegramID)
+	if err != nil {
+		slog.DebugContext(ctx, "Failed to get admin language", logger.ErrorField, err.Error())
+		return i18n.DefaultLang
+	}
+	return i18n.ParseLang(string(admin.LanguageCode()))
+}
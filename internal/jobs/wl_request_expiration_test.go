@@ -0,0 +1,193 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/eventbus"
+
+	bh "whitelist-bot/internal/eventbus/handlers"
+	repository "whitelist-bot/internal/repository/wl_request"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeExpirer struct {
+	batches [][]domainWLRequest.WLRequest
+	err     error
+
+	calls         int
+	createdBefore time.Time
+	events        []eventbus.Envelope
+}
+
+func (f *fakeExpirer) ExpireStaleWLRequests(
+	_ context.Context,
+	createdBefore time.Time,
+	_ int64,
+	events ...repository.WLRequestEvent,
+) ([]domainWLRequest.WLRequest, error) {
+	f.createdBefore = createdBefore
+	if f.calls >= len(f.batches) {
+		return nil, f.err
+	}
+	batch := f.batches[f.calls]
+	f.calls++
+	for _, wlRequest := range batch {
+		for _, event := range events {
+			message, err := event(wlRequest)
+			if err != nil {
+				return nil, err
+			}
+			envelope, err := eventbus.DecodeEnvelope(message.Data)
+			if err != nil {
+				return nil, err
+			}
+			f.events = append(f.events, envelope)
+		}
+	}
+	return batch, nil
+}
+
+type fakeSender struct {
+	mu   sync.Mutex
+	sent []*bot.SendMessageParams
+	err  error
+}
+
+func (f *fakeSender) SendMessage(_ context.Context, params *bot.SendMessageParams) (*models.Message, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.err != nil {
+		return nil, f.err
+	}
+	f.sent = append(f.sent, params)
+	return &models.Message{}, nil
+}
+
+func (f *fakeSender) AnswerCallbackQuery(context.Context, *bot.AnswerCallbackQueryParams) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeSender) EditMessageText(context.Context, *bot.EditMessageTextParams) (*models.Message, error) {
+	return &models.Message{}, nil
+}
+
+type fakeUserGetter struct{}
+
+func (fakeUserGetter) UserByTelegramID(context.Context, int64) (domainUser.User, error) {
+	return domainUser.User{}, errors.New("not found")
+}
+
+func expiredWLRequest(t *testing.T, nickname string) domainWLRequest.WLRequest {
+	t.Helper()
+
+	now := time.Now()
+	wlRequest, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterID(domainWLRequest.NewRequesterID()).
+		NicknameFromString(nickname).
+		Status(domainWLRequest.StatusPending).
+		CreatedAt(now.Add(-100 * time.Hour)).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	wlRequest, err = wlRequest.Expire()
+	require.NoError(t, err)
+	return wlRequest
+}
+
+func TestExpireWLRequests(t *testing.T) {
+	t.Parallel()
+
+	const maxPendingAge = 72 * time.Hour
+	steve := expiredWLRequest(t, "steve")
+	alex := expiredWLRequest(t, "<alex>")
+
+	tests := []struct {
+		name         string
+		batches      [][]domainWLRequest.WLRequest
+		expireErr    error
+		sendErr      error
+		wantCalls    int
+		wantEvents   int
+		wantReported []string
+		wantErr      bool
+	}{
+		{
+			name:      "nothing_to_expire",
+			batches:   [][]domainWLRequest.WLRequest{{}},
+			wantCalls: 1,
+		},
+		{
+			name:         "several_batches",
+			batches:      [][]domainWLRequest.WLRequest{{steve}, {alex}, {}},
+			wantCalls:    3,
+			wantEvents:   2,
+			wantReported: []string{"steve", "&lt;alex&gt;"},
+		},
+		{
+			name:         "repository_error_reports_expired_so_far",
+			batches:      [][]domainWLRequest.WLRequest{{steve}},
+			expireErr:    errors.New("db is down"),
+			wantCalls:    1,
+			wantEvents:   1,
+			wantReported: []string{"steve"},
+			wantErr:      true,
+		},
+		{
+			name:       "report_failed",
+			batches:    [][]domainWLRequest.WLRequest{{steve}, {}},
+			sendErr:    errors.New("telegram is down"),
+			wantCalls:  2,
+			wantEvents: 1,
+			wantErr:    true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := &fakeExpirer{batches: tt.batches, err: tt.expireErr}
+			sender := &fakeSender{err: tt.sendErr}
+			job := ExpireWLRequests(repo, sender, fakeUserGetter{}, []int64{1, 2}, maxPendingAge, 1)
+
+			err := job(context.Background())
+
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+			assert.Equal(t, tt.wantCalls, repo.calls)
+			assert.WithinDuration(t, time.Now().Add(-maxPendingAge), repo.createdBefore, time.Minute)
+
+			require.Len(t, repo.events, tt.wantEvents)
+			for _, envelope := range repo.events {
+				event, err := bh.TopicWLRequestExpired.Decode(envelope)
+				require.NoError(t, err)
+				assert.Equal(t, domainWLRequest.StatusExpired, event.WLRequest.Status())
+				assert.Equal(t, maxPendingAge, event.MaxPendingAge)
+			}
+
+			if len(tt.wantReported) == 0 {
+				assert.Empty(t, sender.sent)
+				return
+			}
+			require.Len(t, sender.sent, 2)
+			for _, params := range sender.sent {
+				for _, nickname := range tt.wantReported {
+					assert.Contains(t, params.Text, nickname)
+				}
+			}
+		})
+	}
+}
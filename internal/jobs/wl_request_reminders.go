@@ -0,0 +1,190 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/metastore"
+	"whitelist-bot/internal/msgs"
+	"whitelist-bot/internal/scheduler"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	keyWLRequestRemindedOnDuty = "wl_request_reminded_on_duty"
+	keyWLRequestRemindedOwners = "wl_request_reminded_owners"
+	// maxOverdueWLRequests bounds a single run, the rest is reminded about by the next one.
+	maxOverdueWLRequests = 1000
+	// reminderClaimMargin keeps a claim a bit longer than its request can stay pending,
+	// so a late expiration run doesn't remind about the request again.
+	reminderClaimMargin = 24 * time.Hour
+)
+
+type iPendingWLRequestGetter interface {
+	CountPendingWLRequests(ctx context.Context) (int64, error)
+	PendingWLRequestsCreatedBefore(ctx context.Context, createdBefore time.Time, limit int64) ([]domainWLRequest.WLRequest, error)
+}
+
+type iReminderStore interface {
+	metastore.IMetastoreCreator
+	metastore.IMetastoreDeleter
+}
+
+// reminderStage is one level of escalation. Every request is reminded about once per stage.
+type reminderStage struct {
+	key        string
+	after      time.Duration
+	chatIDs    []int64
+	escalation bool
+}
+
+// RemindAboutWLRequests reminds on-duty admins about requests pending for longer than remindAfter
+// and escalates to owners after escalateAfter. The reminded stage of every request is claimed in the
+// metastore before sending, so replicas and restarts don't send duplicates. The claims expire
+// after maxPendingAge and a margin, when the request can't be pending anymore.
+func RemindAboutWLRequests(
+	repo iPendingWLRequestGetter,
+	store iReminderStore,
+	sender utils.IMessageSender,
+	userGetter iUserGetter,
+	onDutyChatIDs []int64,
+	ownerChatIDs []int64,
+	remindAfter time.Duration,
+	escalateAfter time.Duration,
+	maxPendingAge time.Duration,
+) scheduler.JobFunc {
+	claimTTL := maxPendingAge + reminderClaimMargin
+	stages := []reminderStage{
+		{key: keyWLRequestRemindedOnDuty, after: remindAfter, chatIDs: onDutyChatIDs},
+		{key: keyWLRequestRemindedOwners, after: escalateAfter, chatIDs: ownerChatIDs, escalation: true},
+	}
+
+	return func(ctx context.Context) error {
+		now := time.Now()
+		overdue, err := repo.PendingWLRequestsCreatedBefore(ctx, now.Add(-remindAfter), maxOverdueWLRequests)
+		if err != nil {
+			return fmt.Errorf("failed to get overdue wl requests: %w", err)
+		}
+		if len(overdue) == 0 {
+			return nil
+		}
+		backlog, err := repo.CountPendingWLRequests(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to count pending wl requests: %w", err)
+		}
+		// Requests are sorted by age, and every overdue request is older than the rest of the backlog.
+		oldest := overdue[0]
+
+		var stageErrors []error
+		for _, stage := range stages {
+			if len(stage.chatIDs) == 0 {
+				continue
+			}
+			due := overdueFor(overdue, now, stage.after)
+			claimed, err := claimReminders(ctx, store, stage.key, due, claimTTL)
+			if err != nil {
+				stageErrors = append(stageErrors, err)
+			}
+			if len(claimed) == 0 {
+				continue
+			}
+
+			err = sendReminder(ctx, sender, userGetter, stage, backlog, len(due), oldest)
+			if err != nil {
+				releaseReminders(ctx, store, stage.key, claimed)
+				stageErrors = append(stageErrors, err)
+				continue
+			}
+			slog.InfoContext(ctx, "Reminded about overdue wl requests",
+				"escalation", stage.escalation, "count", len(claimed), "backlog", backlog)
+		}
+		return errors.Join(stageErrors...)
+	}
+}
+
+func overdueFor(wlRequests []domainWLRequest.WLRequest, now time.Time, after time.Duration) []domainWLRequest.WLRequest {
+	var due []domainWLRequest.WLRequest
+	for _, wlRequest := range wlRequests {
+		if now.Sub(wlRequest.CreatedAt()) >= after {
+			due = append(due, wlRequest)
+		}
+	}
+	return due
+}
+
+// claimReminders returns the requests nobody has reminded about at this stage yet.
+func claimReminders(
+	ctx context.Context,
+	store iReminderStore,
+	key string,
+	wlRequests []domainWLRequest.WLRequest,
+	ttl time.Duration,
+) ([]domainWLRequest.WLRequest, error) {
+	var claimed []domainWLRequest.WLRequest
+	var claimErrors []error
+	for _, wlRequest := range wlRequests {
+		err := store.Create(ctx, wlRequest.ID().String(), key, time.Now(), ttl)
+		if errors.Is(err, metastore.ErrKeyExists) {
+			continue
+		}
+		if err != nil {
+			claimErrors = append(claimErrors, fmt.Errorf("failed to claim wl request reminder %s: %w", wlRequest.ID(), err))
+			continue
+		}
+		claimed = append(claimed, wlRequest)
+	}
+	return claimed, errors.Join(claimErrors...)
+}
+
+// releaseReminders lets the next run retry reminders that were not sent.
+func releaseReminders(ctx context.Context, store iReminderStore, key string, wlRequests []domainWLRequest.WLRequest) {
+	for _, wlRequest := range wlRequests {
+		if err := store.Delete(ctx, wlRequest.ID().String(), key); err != nil {
+			slog.WarnContext(ctx, "Failed to release wl request reminder",
+				logger.WLRequestIDField, wlRequest.ID().String(), logger.ErrorField, err.Error())
+		}
+	}
+}
+
+func sendReminder(
+	ctx context.Context,
+	sender utils.IMessageSender,
+	userGetter iUserGetter,
+	stage reminderStage,
+	backlog int64,
+	overdue int,
+	oldest domainWLRequest.WLRequest,
+) error {
+	sendingErrors := make([]error, 0, len(stage.chatIDs))
+	for _, chatID := range stage.chatIDs {
+		_, err := sender.SendMessage(ctx, &bot.SendMessageParams{
+			ChatID: chatID,
+			Text: msgs.WLRequestReminder(
+				adminLang(ctx, userGetter, chatID),
+				stage.escalation,
+				backlog,
+				overdue,
+				stage.after,
+				oldest,
+			),
+			ParseMode: models.ParseModeHTML,
+		})
+		if err != nil {
+			sendingErrors = append(sendingErrors, fmt.Errorf("failed to send wl request reminder to %d: %w", chatID, err))
+		}
+	}
+	if len(sendingErrors) == len(stage.chatIDs) {
+		return errors.Join(sendingErrors...)
+	}
+	for _, err := range sendingErrors {
+		slog.WarnContext(ctx, "Failed to send wl request reminder", logger.ErrorField, err.Error())
+	}
+	return nil
+}
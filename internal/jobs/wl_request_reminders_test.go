@@ -0,0 +1,200 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	memoryMetastore "whitelist-bot/internal/metastore/memory"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakePendingGetter struct {
+	pending []domainWLRequest.WLRequest
+}
+
+func (f *fakePendingGetter) CountPendingWLRequests(context.Context) (int64, error) {
+	return int64(len(f.pending)), nil
+}
+
+func (f *fakePendingGetter) PendingWLRequestsCreatedBefore(
+	_ context.Context,
+	createdBefore time.Time,
+	_ int64,
+) ([]domainWLRequest.WLRequest, error) {
+	var wlRequests []domainWLRequest.WLRequest
+	for _, wlRequest := range f.pending {
+		if wlRequest.CreatedAt().Before(createdBefore) {
+			wlRequests = append(wlRequests, wlRequest)
+		}
+	}
+	return wlRequests, nil
+}
+
+func pendingWLRequest(t *testing.T, nickname string, age time.Duration) domainWLRequest.WLRequest {
+	t.Helper()
+
+	createdAt := time.Now().Add(-age)
+	wlRequest, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterID(domainWLRequest.NewRequesterID()).
+		NicknameFromString(nickname).
+		Status(domainWLRequest.StatusPending).
+		CreatedAt(createdAt).
+		UpdatedAt(createdAt).
+		Build()
+	require.NoError(t, err)
+	return wlRequest
+}
+
+const (
+	onDutyChatID = 1
+	ownerChatID  = 100
+)
+
+func sentTo(sender *fakeSender) []int64 {
+	sender.mu.Lock()
+	defer sender.mu.Unlock()
+
+	chatIDs := make([]int64, 0, len(sender.sent))
+	for _, params := range sender.sent {
+		chatIDs = append(chatIDs, params.ChatID.(int64))
+	}
+	return chatIDs
+}
+
+func TestRemindAboutWLRequests(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name       string
+		pending    []time.Duration
+		owners     []int64
+		wantSentTo []int64
+	}{
+		{
+			name:       "nothing_overdue",
+			pending:    []time.Duration{time.Hour},
+			owners:     []int64{ownerChatID},
+			wantSentTo: []int64{},
+		},
+		{
+			name:       "remind_on_duty",
+			pending:    []time.Duration{time.Hour, 7 * time.Hour},
+			owners:     []int64{ownerChatID},
+			wantSentTo: []int64{onDutyChatID},
+		},
+		{
+			name:       "escalate_to_owners",
+			pending:    []time.Duration{7 * time.Hour, 30 * time.Hour},
+			owners:     []int64{ownerChatID},
+			wantSentTo: []int64{onDutyChatID, ownerChatID},
+		},
+		{
+			name:       "no_owners",
+			pending:    []time.Duration{30 * time.Hour},
+			wantSentTo: []int64{onDutyChatID},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			repo := &fakePendingGetter{}
+			for _, age := range tt.pending {
+				repo.pending = append(repo.pending, pendingWLRequest(t, "steve", age))
+			}
+			// The fake keeps the repository order: oldest first.
+			for i, j := 0, len(repo.pending)-1; i < j; i, j = i+1, j-1 {
+				repo.pending[i], repo.pending[j] = repo.pending[j], repo.pending[i]
+			}
+			sender := &fakeSender{}
+			job := RemindAboutWLRequests(
+				repo,
+				memoryMetastore.New("test"),
+				sender,
+				fakeUserGetter{},
+				[]int64{onDutyChatID},
+				tt.owners,
+				6*time.Hour,
+				24*time.Hour,
+				168*time.Hour,
+			)
+
+			require.NoError(t, job(context.Background()))
+			assert.Equal(t, tt.wantSentTo, sentTo(sender))
+
+			// The next run must not repeat reminders.
+			require.NoError(t, job(context.Background()))
+			assert.Equal(t, tt.wantSentTo, sentTo(sender))
+		})
+	}
+}
+
+func TestRemindAboutWLRequests_Content(t *testing.T) {
+	t.Parallel()
+
+	oldest := pendingWLRequest(t, "<steve>", 30*time.Hour)
+	repo := &fakePendingGetter{pending: []domainWLRequest.WLRequest{
+		oldest,
+		pendingWLRequest(t, "alex", 7*time.Hour),
+		pendingWLRequest(t, "herobrine", time.Hour),
+	}}
+	sender := &fakeSender{}
+	job := RemindAboutWLRequests(repo, memoryMetastore.New("test"), sender, fakeUserGetter{},
+		[]int64{onDutyChatID}, nil, 6*time.Hour, 24*time.Hour, 168*time.Hour)
+
+	require.NoError(t, job(context.Background()))
+
+	require.Len(t, sender.sent, 1)
+	text := sender.sent[0].Text
+	assert.Contains(t, text, "Ожидают: <b>3</b>")
+	assert.Contains(t, text, "Дольше 6 ч.: <b>2</b>")
+	assert.Contains(t, text, "&lt;steve&gt;")
+	assert.Contains(t, text, oldest.ID().String())
+}
+
+func TestRemindAboutWLRequests_RetriesFailedReminders(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakePendingGetter{pending: []domainWLRequest.WLRequest{pendingWLRequest(t, "steve", 7*time.Hour)}}
+	sender := &fakeSender{err: errors.New("telegram is down")}
+	job := RemindAboutWLRequests(repo, memoryMetastore.New("test"), sender, fakeUserGetter{},
+		[]int64{onDutyChatID}, nil, 6*time.Hour, 24*time.Hour, 168*time.Hour)
+
+	require.Error(t, job(context.Background()))
+	assert.Empty(t, sentTo(sender))
+
+	sender.mu.Lock()
+	sender.err = nil
+	sender.mu.Unlock()
+
+	require.NoError(t, job(context.Background()))
+	assert.Equal(t, []int64{onDutyChatID}, sentTo(sender))
+}
+
+// ttlRecordingStore records the TTL of every claim.
+type ttlRecordingStore struct {
+	*memoryMetastore.Metastore
+	ttls []time.Duration
+}
+
+func (s *ttlRecordingStore) Create(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error {
+	s.ttls = append(s.ttls, ttl)
+	return s.Metastore.Create(ctx, uniqueID, key, value, ttl)
+}
+
+func TestRemindAboutWLRequests_ClaimsExpireAfterMaxPendingAge(t *testing.T) {
+	t.Parallel()
+
+	repo := &fakePendingGetter{pending: []domainWLRequest.WLRequest{pendingWLRequest(t, "steve", 25*time.Hour)}}
+	store := &ttlRecordingStore{Metastore: memoryMetastore.New("test")}
+	job := RemindAboutWLRequests(repo, store, &fakeSender{}, fakeUserGetter{},
+		[]int64{onDutyChatID}, []int64{ownerChatID}, 6*time.Hour, 24*time.Hour, 168*time.Hour)
+
+	require.NoError(t, job(context.Background()))
+	assert.Equal(t, []time.Duration{168*time.Hour + reminderClaimMargin, 168*time.Hour + reminderClaimMargin}, store.ttls)
+}
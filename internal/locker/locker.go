@@ -1,8 +1,26 @@
 package locker
 
-import domainUser "whitelist-bot/internal/domain/user"
+import (
+	"context"
+	"errors"
+	domainUser "whitelist-bot/internal/domain/user"
+)
+
+var (
+	ErrLockBusy     = errors.New("user lock is busy")
+	ErrLockTimeout  = errors.New("user lock wait timeout")
+	ErrLockReleased = errors.New("user lock already released")
+)
 
 type ILocker interface {
-	Lock(userID domainUser.ID) error
-	Unlock(userID domainUser.ID) error
+	// Lock waits for the user lock until ctx is done and returns ErrLockTimeout if it is not acquired in time.
+	Lock(ctx context.Context, userID domainUser.ID) (IHandle, error)
+	// TryLock acquires the user lock only if it is free and returns ErrLockBusy otherwise.
+	TryLock(ctx context.Context, userID domainUser.ID) (IHandle, error)
+}
+
+// IHandle is a held user lock. The lock is released by Unlock or automatically after the hold timeout,
+// so a stuck handler can't block the user forever.
+type IHandle interface {
+	Unlock(ctx context.Context) error
 }
@@ -1,54 +1,123 @@
 package memory
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"log/slog"
 	"sync"
+	"time"
+	"whitelist-bot/internal/core/logger"
 	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/locker"
 )
 
-var (
-	ErrUserLockNotFound = errors.New("user lock not found")
-)
+// entry is a per-user mutex. It is removed from the map as soon as nobody holds or waits for it.
+type entry struct {
+	ch   chan struct{}
+	refs int
+}
 
 type Locker struct {
-	mu    sync.RWMutex
-	locks map[domainUser.ID]*sync.RWMutex
+	mu          sync.Mutex
+	locks       map[domainUser.ID]*entry
+	holdTimeout time.Duration
 }
 
-// WARNING: This locker can potentially cause memory leaks with large number of users.
-// User different lockers with TTL.
-func New() *Locker {
+func New(holdTimeout time.Duration) *Locker {
 	return &Locker{
-		locks: make(map[domainUser.ID]*sync.RWMutex),
+		locks:       make(map[domainUser.ID]*entry),
+		holdTimeout: holdTimeout,
 	}
 }
 
-func (l *Locker) Lock(userID domainUser.ID) error {
-	l.mu.Lock()
+func (l *Locker) Lock(ctx context.Context, userID domainUser.ID) (locker.IHandle, error) {
+	e := l.acquireEntry(userID)
 
-	userLock, ok := l.locks[userID]
-	if !ok {
-		l.locks[userID] = &sync.RWMutex{}
-		userLock = l.locks[userID]
+	select {
+	case e.ch <- struct{}{}:
+		return l.newHandle(userID, e), nil
+	case <-ctx.Done():
+		l.releaseEntry(userID, e)
+		return nil, fmt.Errorf("%w: %w", locker.ErrLockTimeout, ctx.Err())
 	}
+}
 
-	l.mu.Unlock()
+func (l *Locker) TryLock(_ context.Context, userID domainUser.ID) (locker.IHandle, error) {
+	e := l.acquireEntry(userID)
 
-	userLock.Lock()
-	return nil
+	select {
+	case e.ch <- struct{}{}:
+		return l.newHandle(userID, e), nil
+	default:
+		l.releaseEntry(userID, e)
+		return nil, locker.ErrLockBusy
+	}
 }
 
-func (l *Locker) Unlock(userID domainUser.ID) error {
-	l.mu.RLock()
+func (l *Locker) acquireEntry(userID domainUser.ID) *entry {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	e, ok := l.locks[userID]
+	if !ok {
+		e = &entry{ch: make(chan struct{}, 1)}
+		l.locks[userID] = e
+	}
+	e.refs++
+	return e
+}
 
-	userLock, ok := l.locks[userID]
+func (l *Locker) releaseEntry(userID domainUser.ID, e *entry) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
 
-	l.mu.RUnlock()
+	e.refs--
+	if e.refs == 0 {
+		delete(l.locks, userID)
+	}
+}
 
-	if !ok {
-		return ErrUserLockNotFound
+func (l *Locker) newHandle(userID domainUser.ID, e *entry) *handle {
+	h := &handle{
+		locker: l,
+		userID: userID,
+		entry:  e,
+	}
+	if l.holdTimeout > 0 {
+		h.timer = time.AfterFunc(l.holdTimeout, func() {
+			if h.release() {
+				slog.Warn("User lock hold timeout exceeded, lock released", logger.UserIDField, userID.String())
+			}
+		})
 	}
+	return h
+}
 
-	userLock.Unlock()
+type handle struct {
+	locker *Locker
+	userID domainUser.ID
+	entry  *entry
+	timer  *time.Timer
+	once   sync.Once
+}
+
+func (h *handle) Unlock(_ context.Context) error {
+	if h.timer != nil {
+		h.timer.Stop()
+	}
+	if !h.release() {
+		return locker.ErrLockReleased
+	}
 	return nil
 }
+
+// release frees the lock once and reports whether this call released it.
+func (h *handle) release() bool {
+	released := false
+	h.once.Do(func() {
+		<-h.entry.ch
+		h.locker.releaseEntry(h.userID, h.entry)
+		released = true
+	})
+	return released
+}
@@ -1,10 +1,12 @@
 package memory
 
 import (
+	"context"
 	"sync"
 	"testing"
 	"time"
 	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/locker"
 
 	"github.com/google/uuid"
 	"github.com/stretchr/testify/assert"
@@ -12,27 +14,30 @@ import (
 )
 
 func TestLocker_Lock_Unlock(t *testing.T) {
-	locker := New()
+	l := New(0)
 	userID := domainUser.ID(uuid.New())
 
-	err := locker.Lock(userID)
+	h, err := l.Lock(context.Background(), userID)
 	require.NoError(t, err)
 
-	err = locker.Unlock(userID)
+	err = h.Unlock(context.Background())
 	require.NoError(t, err)
 }
 
-func TestLocker_Unlock_NotLocked(t *testing.T) {
-	locker := New()
+func TestLocker_Unlock_Twice(t *testing.T) {
+	l := New(0)
 	userID := domainUser.ID(uuid.New())
 
-	err := locker.Unlock(userID)
-	assert.Error(t, err)
-	assert.ErrorIs(t, err, ErrUserLockNotFound)
+	h, err := l.Lock(context.Background(), userID)
+	require.NoError(t, err)
+	require.NoError(t, h.Unlock(context.Background()))
+
+	err = h.Unlock(context.Background())
+	assert.ErrorIs(t, err, locker.ErrLockReleased)
 }
 
 func TestLocker_Concurrent_SameUser(t *testing.T) {
-	locker := New()
+	l := New(0)
 	userID := domainUser.ID(uuid.New())
 
 	var counter int
@@ -40,8 +45,11 @@ func TestLocker_Concurrent_SameUser(t *testing.T) {
 
 	for range 10 {
 		wg.Go(func() {
-			locker.Lock(userID)
-			defer locker.Unlock(userID)
+			h, err := l.Lock(context.Background(), userID)
+			if !assert.NoError(t, err) {
+				return
+			}
+			defer h.Unlock(context.Background())
 
 			temp := counter
 			time.Sleep(time.Millisecond * 10)
@@ -51,10 +59,11 @@ func TestLocker_Concurrent_SameUser(t *testing.T) {
 
 	wg.Wait()
 	assert.Equal(t, 10, counter)
+	assert.Empty(t, l.locks)
 }
 
 func TestLocker_Concurrent_DifferentUsers(t *testing.T) {
-	locker := New()
+	l := New(0)
 
 	var wg sync.WaitGroup
 	users := make([]domainUser.ID, 100)
@@ -67,13 +76,66 @@ func TestLocker_Concurrent_DifferentUsers(t *testing.T) {
 		go func(id domainUser.ID) {
 			defer wg.Done()
 
-			err := locker.Lock(id)
-			require.NoError(t, err)
+			h, err := l.Lock(context.Background(), id)
+			assert.NoError(t, err)
 			time.Sleep(time.Millisecond * 10)
-			err = locker.Unlock(id)
-			require.NoError(t, err)
+			err = h.Unlock(context.Background())
+			assert.NoError(t, err)
 		}(userID)
 	}
 
 	wg.Wait()
+	assert.Empty(t, l.locks)
+}
+
+func TestLocker_Lock_ContextDeadline(t *testing.T) {
+	l := New(0)
+	userID := domainUser.ID(uuid.New())
+
+	h, err := l.Lock(context.Background(), userID)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err = l.Lock(ctx, userID)
+	require.ErrorIs(t, err, locker.ErrLockTimeout)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	require.NoError(t, h.Unlock(context.Background()))
+	assert.Empty(t, l.locks)
+}
+
+func TestLocker_TryLock(t *testing.T) {
+	l := New(0)
+	userID := domainUser.ID(uuid.New())
+
+	h, err := l.TryLock(context.Background(), userID)
+	require.NoError(t, err)
+
+	_, err = l.TryLock(context.Background(), userID)
+	require.ErrorIs(t, err, locker.ErrLockBusy)
+
+	require.NoError(t, h.Unlock(context.Background()))
+
+	h, err = l.TryLock(context.Background(), userID)
+	require.NoError(t, err)
+	require.NoError(t, h.Unlock(context.Background()))
+	assert.Empty(t, l.locks)
+}
+
+func TestLocker_HoldTimeout(t *testing.T) {
+	l := New(20 * time.Millisecond)
+	userID := domainUser.ID(uuid.New())
+
+	h, err := l.Lock(context.Background(), userID)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	h2, err := l.Lock(ctx, userID)
+	require.NoError(t, err)
+	assert.ErrorIs(t, h.Unlock(context.Background()), locker.ErrLockReleased)
+	require.NoError(t, h2.Unlock(context.Background()))
 }
@@ -0,0 +1,190 @@
+package nats
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/locker"
+
+	"github.com/google/uuid"
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+const (
+	defaultMaxBytes      = 1024 * 1024 // 1MB
+	defaultRetryInterval = 50 * time.Millisecond
+)
+
+// Locker is a distributed locker backed by NATS KV.
+// A lock is a key created with Create, so only one replica can hold it.
+// The holder renews the lease while the lock is held; if the replica crashes,
+// the bucket TTL removes the key and the lock is released.
+type Locker struct {
+	bucket        jetstream.KeyValue
+	owner         string
+	ttl           time.Duration
+	holdTimeout   time.Duration
+	retryInterval time.Duration
+}
+
+func New(
+	ctx context.Context,
+	conn *nats.Conn,
+	bucketName string,
+	replicas int,
+	ttl time.Duration,
+	holdTimeout time.Duration,
+) (*Locker, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create jetstream: %w", err)
+	}
+	bucket, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
+		Bucket:   bucketName,
+		MaxBytes: defaultMaxBytes,
+		TTL:      ttl,
+		Storage:  jetstream.MemoryStorage,
+		Replicas: replicas,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create or update keyvalue bucket: %w", err)
+	}
+	return &Locker{
+		bucket:        bucket,
+		owner:         uuid.NewString(),
+		ttl:           ttl,
+		holdTimeout:   holdTimeout,
+		retryInterval: defaultRetryInterval,
+	}, nil
+}
+
+func (l *Locker) Lock(ctx context.Context, userID domainUser.ID) (locker.IHandle, error) {
+	ticker := time.NewTicker(l.retryInterval)
+	defer ticker.Stop()
+
+	for {
+		h, err := l.TryLock(ctx, userID)
+		if err == nil {
+			return h, nil
+		}
+		if !errors.Is(err, locker.ErrLockBusy) {
+			if ctx.Err() != nil {
+				return nil, fmt.Errorf("%w: %w", locker.ErrLockTimeout, ctx.Err())
+			}
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, fmt.Errorf("%w: %w", locker.ErrLockTimeout, ctx.Err())
+		case <-ticker.C:
+		}
+	}
+}
+
+func (l *Locker) TryLock(ctx context.Context, userID domainUser.ID) (locker.IHandle, error) {
+	key := l.lockKey(userID)
+
+	revision, err := l.bucket.Create(ctx, key, []byte(l.owner))
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		return nil, locker.ErrLockBusy
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock key: %w", err)
+	}
+
+	renewCtx, stop := context.WithCancel(context.WithoutCancel(ctx))
+	h := &handle{
+		locker:   l,
+		key:      key,
+		revision: revision,
+		stop:     stop,
+		done:     make(chan struct{}),
+	}
+	go h.renew(renewCtx)
+	return h, nil
+}
+
+func (l *Locker) lockKey(userID domainUser.ID) string {
+	return fmt.Sprintf("lock__%s", userID.String())
+}
+
+type handle struct {
+	locker *Locker
+	key    string
+	stop   context.CancelFunc
+	done   chan struct{}
+	once   sync.Once
+
+	mu       sync.Mutex
+	revision uint64
+}
+
+func (h *handle) Unlock(ctx context.Context) error {
+	released := false
+	var err error
+	h.once.Do(func() {
+		released = true
+		h.stop()
+		<-h.done
+
+		h.mu.Lock()
+		revision := h.revision
+		h.mu.Unlock()
+
+		err = h.locker.bucket.Delete(ctx, h.key, jetstream.LastRevision(revision))
+	})
+	if !released {
+		return locker.ErrLockReleased
+	}
+	if err != nil {
+		return fmt.Errorf("failed to delete lock key: %w", err)
+	}
+	return nil
+}
+
+// renew keeps the lease alive by updating the key before the bucket TTL removes it.
+// Renewal stops after the hold timeout, so a stuck handler loses the lock once the lease expires.
+func (h *handle) renew(ctx context.Context) {
+	defer close(h.done)
+
+	ticker := time.NewTicker(h.locker.ttl / 3)
+	defer ticker.Stop()
+
+	var holdTimeout <-chan time.Time
+	if h.locker.holdTimeout > 0 {
+		holdTimer := time.NewTimer(h.locker.holdTimeout)
+		defer holdTimer.Stop()
+		holdTimeout = holdTimer.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-holdTimeout:
+			slog.WarnContext(ctx, "User lock hold timeout exceeded, lease renewal stopped", "key", h.key)
+			return
+		case <-ticker.C:
+			h.mu.Lock()
+			revision, err := h.locker.bucket.Update(ctx, h.key, []byte(h.locker.owner), h.revision)
+			if err == nil {
+				h.revision = revision
+			}
+			h.mu.Unlock()
+
+			if err != nil {
+				if ctx.Err() == nil {
+					slog.ErrorContext(ctx, "Failed to renew user lock lease", logger.ErrorField, err.Error())
+				}
+				return
+			}
+		}
+	}
+}
@@ -3,23 +3,36 @@ package memory
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
-	"log/slog"
 	"sync"
 	"time"
+	"whitelist-bot/internal/metastore"
 )
 
+// entry is a stored value. A zero expiresAt keeps it until it is deleted.
+type entry struct {
+	data      []byte
+	expiresAt time.Time
+}
+
+func (e entry) expired(now time.Time) bool {
+	return !e.expiresAt.IsZero() && !now.Before(e.expiresAt)
+}
+
+// Metastore keeps the keys in memory. Expired keys are treated as missing
+// and removed on the next write with a TTL, so the store does not grow with them.
 type Metastore struct {
 	mu        sync.RWMutex
-	store     map[string][]byte
+	store     map[string]entry
 	keyPrefix string
+	now       func() time.Time
 }
 
 func New(keyPrefix string) *Metastore {
 	return &Metastore{
-		store:     make(map[string][]byte),
+		store:     make(map[string]entry),
 		keyPrefix: keyPrefix,
+		now:       time.Now,
 	}
 }
 
@@ -27,31 +40,47 @@ func (m *Metastore) Get(ctx context.Context, uniqueID string, key string) ([]byt
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	data, ok := m.store[m.dataKey(uniqueID, key)]
-	if !ok {
-		return nil, errors.New("data not found")
+	e, ok := m.store[m.dataKey(uniqueID, key)]
+	if !ok || e.expired(m.now()) {
+		return nil, metastore.ErrKeyNotFound
 	}
 
-	return data, nil
+	return e.data, nil
 }
 
 func (m *Metastore) Set(ctx context.Context, uniqueID string, key string, value any) error {
-	m.mu.Lock()
-	defer m.mu.Unlock()
+	return m.SetWithTTL(ctx, uniqueID, key, value, 0)
+}
 
+// SetWithTTL sets the key until the TTL passes. A zero TTL keeps it until it is deleted.
+func (m *Metastore) SetWithTTL(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error {
 	dataBytes, err := json.Marshal(value)
 	if err != nil {
 		return fmt.Errorf("failed to json marshal value: %w", err)
 	}
 
-	m.store[m.dataKey(uniqueID, key)] = dataBytes
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.put(m.dataKey(uniqueID, key), dataBytes, ttl)
 	return nil
 }
 
-func (m *Metastore) SetWithTTL(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error {
-	// TODO: Implement TTL
-	slog.InfoContext(ctx, "Memory metastore does not support TTL")
-	return m.Set(ctx, uniqueID, key, value)
+func (m *Metastore) Create(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error {
+	dataBytes, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to json marshal value: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	dataKey := m.dataKey(uniqueID, key)
+	if e, ok := m.store[dataKey]; ok && !e.expired(m.now()) {
+		return metastore.ErrKeyExists
+	}
+	m.put(dataKey, dataBytes, ttl)
+	return nil
 }
 
 func (m *Metastore) Delete(ctx context.Context, uniqueID string, key string) error {
@@ -66,8 +95,28 @@ func (m *Metastore) Exists(ctx context.Context, uniqueID string, key string) (bo
 	m.mu.RLock()
 	defer m.mu.RUnlock()
 
-	_, ok := m.store[m.dataKey(uniqueID, key)]
-	return ok, nil
+	e, ok := m.store[m.dataKey(uniqueID, key)]
+	return ok && !e.expired(m.now()), nil
+}
+
+// put stores the value, the caller must hold the write lock.
+// Keys with a TTL trigger the removal of the expired ones.
+func (m *Metastore) put(dataKey string, data []byte, ttl time.Duration) {
+	now := m.now()
+	e := entry{data: data}
+	if ttl > 0 {
+		e.expiresAt = now.Add(ttl)
+		m.deleteExpired(now)
+	}
+	m.store[dataKey] = e
+}
+
+func (m *Metastore) deleteExpired(now time.Time) {
+	for dataKey, e := range m.store {
+		if e.expired(now) {
+			delete(m.store, dataKey)
+		}
+	}
 }
 
 func (m *Metastore) dataKey(uniqueID string, key string) string {
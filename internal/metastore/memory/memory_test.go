@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+	"whitelist-bot/internal/metastore"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetastore_TTL(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	m := New("test")
+	m.now = func() time.Time { return now }
+
+	require.NoError(t, m.Create(ctx, "id", "claim", 1, time.Minute))
+	require.NoError(t, m.SetWithTTL(ctx, "id", "ttl", 2, time.Minute))
+	require.NoError(t, m.Set(ctx, "id", "forever", 3))
+	require.ErrorIs(t, m.Create(ctx, "id", "claim", 1, time.Minute), metastore.ErrKeyExists)
+
+	now = now.Add(time.Minute)
+
+	for _, key := range []string{"claim", "ttl"} {
+		_, err := m.Get(ctx, "id", key)
+		require.ErrorIs(t, err, metastore.ErrKeyNotFound, key)
+		exists, err := m.Exists(ctx, "id", key)
+		require.NoError(t, err)
+		assert.False(t, exists, key)
+	}
+	data, err := m.Get(ctx, "id", "forever")
+	require.NoError(t, err)
+	assert.JSONEq(t, "3", string(data))
+
+	// An expired key can be claimed again, and the write removes the other expired keys.
+	require.NoError(t, m.Create(ctx, "id", "claim", 4, time.Minute))
+	assert.Len(t, m.store, 2)
+}
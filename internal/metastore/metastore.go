@@ -9,11 +9,13 @@ import (
 
 var (
 	ErrKeyNotFound = errors.New("key not found")
+	ErrKeyExists   = errors.New("key already exists")
 )
 
 type IMetastore interface {
 	IMetastoreGetter
 	IMetastoreSetter
+	IMetastoreCreator
 	IMetastoreDeleter
 }
 
@@ -30,6 +32,12 @@ type IMetastoreSetter interface {
 	SetStringWithTTL(ctx context.Context, uniqueID string, key string, value string, ttl time.Duration) error
 }
 
+// IMetastoreCreator atomically creates a key, so only one replica can claim it.
+type IMetastoreCreator interface {
+	// Create returns ErrKeyExists if the key is already set.
+	Create(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error
+}
+
 type IMetastoreDeleter interface {
 	Delete(ctx context.Context, uniqueID string, key string) error
 }
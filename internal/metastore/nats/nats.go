@@ -14,14 +14,20 @@ import (
 
 const (
 	defaultMaxBytes = 1024 * 1024 * 10 // 10MB
-	defaultTTL      = 30 * 24 * time.Hour
+	// DefaultTTL is the bucket TTL of the shared metastore.
+	DefaultTTL = 30 * 24 * time.Hour
+	// kvSubjectPrefix is the subject prefix of the stream behind a KV bucket.
+	kvSubjectPrefix = "$KV."
 )
 
 type Metastore struct {
+	js     jetstream.JetStream
 	bucket jetstream.KeyValue
+	ttl    time.Duration
 }
 
-func New(ctx context.Context, conn *nats.Conn, bucketName string, replicas int) (*Metastore, error) {
+// New creates or updates the bucket. Keys set without a TTL live for the bucket ttl, zero keeps them forever.
+func New(ctx context.Context, conn *nats.Conn, bucketName string, replicas int, ttl time.Duration) (*Metastore, error) {
 	js, err := jetstream.New(conn)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create jetstream: %w", err)
@@ -29,7 +35,7 @@ func New(ctx context.Context, conn *nats.Conn, bucketName string, replicas int)
 	bucket, err := js.CreateOrUpdateKeyValue(ctx, jetstream.KeyValueConfig{
 		Bucket:         bucketName,
 		MaxBytes:       defaultMaxBytes,
-		TTL:            defaultTTL,
+		TTL:            ttl,
 		Storage:        jetstream.FileStorage,
 		Replicas:       replicas,
 		Compression:    true,
@@ -38,7 +44,7 @@ func New(ctx context.Context, conn *nats.Conn, bucketName string, replicas int)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create or update keyvalue bucket: %w", err)
 	}
-	return &Metastore{bucket: bucket}, nil
+	return &Metastore{js: js, bucket: bucket, ttl: ttl}, nil
 }
 
 func (m *Metastore) Get(ctx context.Context, uniqueID string, key string) ([]byte, error) {
@@ -97,6 +103,24 @@ func (m *Metastore) SetStringWithTTL(ctx context.Context, uniqueID string, key s
 	return m.сreateOrUpdate(ctx, m.dataKey(uniqueID, key), []byte(value), ttl)
 }
 
+func (m *Metastore) Create(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to json marshal value: %w", err)
+	}
+	if ttl == 0 {
+		ttl = m.ttl
+	}
+	_, err = m.bucket.Create(ctx, m.dataKey(uniqueID, key), data, jetstream.KeyTTL(ttl))
+	if errors.Is(err, jetstream.ErrKeyExists) {
+		return metastore.ErrKeyExists
+	}
+	if err != nil {
+		return fmt.Errorf("failed to create data: %w", err)
+	}
+	return nil
+}
+
 func (m *Metastore) Delete(ctx context.Context, uniqueID string, key string) error {
 	err := m.bucket.Delete(ctx, m.dataKey(uniqueID, key))
 	if err != nil {
@@ -122,7 +146,7 @@ func (m *Metastore) dataKey(uniqueID string, key string) string {
 
 func (m *Metastore) сreateOrUpdate(ctx context.Context, dataKey string, data []byte, ttl time.Duration) error {
 	if ttl == 0 {
-		ttl = defaultTTL
+		ttl = m.ttl
 	}
 
 	keyEntry, err := m.bucket.Get(ctx, dataKey)
@@ -134,10 +158,22 @@ func (m *Metastore) сreateOrUpdate(ctx context.Context, dataKey string, data []
 			return fmt.Errorf("failed to create data: %w", err)
 		}
 	} else {
-		_, err = m.bucket.Update(ctx, dataKey, data, keyEntry.Revision())
+		err = m.updateWithTTL(ctx, dataKey, data, keyEntry.Revision(), ttl)
 		if err != nil {
 			return fmt.Errorf("failed to update data: %w", err)
 		}
 	}
 	return nil
 }
+
+// updateWithTTL is KeyValue.Update that also renews the TTL of the key. Update takes no TTL,
+// so a key updated with it would live for the bucket TTL. It publishes to the bucket stream the way
+// Update does, with the expected revision and the message TTL.
+func (m *Metastore) updateWithTTL(ctx context.Context, dataKey string, data []byte, revision uint64, ttl time.Duration) error {
+	msg := &nats.Msg{Subject: kvSubjectPrefix + m.bucket.Bucket() + "." + dataKey, Data: data}
+	_, err := m.js.PublishMsg(ctx, msg,
+		jetstream.WithExpectLastSequencePerSubject(revision),
+		jetstream.WithMsgTTL(ttl),
+	)
+	return err
+}
@@ -2,10 +2,14 @@ package msgs
 
 import (
 	"strings"
+	"whitelist-bot/internal/i18n"
 )
 
-func Cancel() string {
+func Cancel(lang i18n.Lang) string {
+	if text, ok := renderTemplate(lang, TemplateCancel, struct{}{}); ok {
+		return text
+	}
 	var sb strings.Builder
-	sb.WriteString("Все действия отменены.\n\n")
+	sb.WriteString(i18n.T(lang, i18n.MsgCancel))
 	return sb.String()
 }
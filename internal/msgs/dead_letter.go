@@ -0,0 +1,55 @@
+package msgs
+
+import (
+	"html"
+	"strings"
+	"whitelist-bot/internal/eventbus"
+	"whitelist-bot/internal/i18n"
+)
+
+const maxDeadLetterDataLength = 3000
+
+func DeadLettersUsage(lang i18n.Lang) string {
+	return i18n.T(lang, i18n.MsgDeadLettersUsage)
+}
+
+func DeadLetters(lang i18n.Lang, deadLetters []eventbus.DeadLetter) string {
+	if len(deadLetters) == 0 {
+		return i18n.T(lang, i18n.MsgNoDeadLetters)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.MsgDeadLettersTitle, len(deadLetters)))
+	for _, dl := range deadLetters {
+		sb.WriteString(i18n.T(lang, i18n.MsgDeadLettersItem,
+			dl.ID.String(),
+			html.EscapeString(dl.Topic),
+			dl.Attempts,
+			formatTime(dl.FailedAt),
+		))
+	}
+	return sb.String()
+}
+
+func DeadLetterDetails(lang i18n.Lang, dl eventbus.DeadLetter) string {
+	data := string(dl.Data)
+	if len(data) > maxDeadLetterDataLength {
+		data = strings.ToValidUTF8(data[:maxDeadLetterDataLength], "") + "…"
+	}
+	return i18n.T(lang, i18n.MsgDeadLetterDetails,
+		dl.ID.String(),
+		html.EscapeString(dl.Topic),
+		dl.Attempts,
+		formatTime(dl.FailedAt),
+		html.EscapeString(dl.Error),
+		html.EscapeString(data),
+	)
+}
+
+func DeadLetterReplayed(lang i18n.Lang, dl eventbus.DeadLetter) string {
+	return i18n.T(lang, i18n.MsgDeadLetterReplayed, dl.ID.String(), html.EscapeString(dl.Topic))
+}
+
+func DeadLettersPurged(lang i18n.Lang, count int64) string {
+	return i18n.T(lang, i18n.MsgDeadLettersPurged, count)
+}
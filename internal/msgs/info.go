@@ -1,44 +1,50 @@
 package msgs
 
 import (
-	"fmt"
+	"html"
 	"strings"
 	"time"
 
 	"whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/i18n"
 )
 
-func UserInfo(u user.User) string {
+func UserInfo(lang i18n.Lang, u user.User) string {
+	if text, ok := renderTemplate(lang, TemplateUserInfo, newUserView(u)); ok {
+		return text
+	}
+
 	var sb strings.Builder
 
-	sb.WriteString("<b>👤 Информация о пользователе</b>\n\n")
+	sb.WriteString(i18n.T(lang, i18n.MsgUserInfoTitle))
 
 	// Basic info
 	if u.FirstName() != "" || u.LastName() != "" {
-		sb.WriteString("📝 <b>Имя:</b> ")
+		sb.WriteString(i18n.T(lang, i18n.MsgUserInfoName))
 		if u.FirstName() != "" {
-			sb.WriteString(string(u.FirstName()))
+			sb.WriteString(html.EscapeString(string(u.FirstName())))
 		}
 		if u.LastName() != "" {
 			if u.FirstName() != "" {
 				sb.WriteString(" ")
 			}
-			sb.WriteString(string(u.LastName()))
+			sb.WriteString(html.EscapeString(string(u.LastName())))
 		}
 		sb.WriteString("\n")
 	}
 
 	if u.Username() != "" {
-		sb.WriteString(fmt.Sprintf("🔗 <b>Username:</b> @%s\n", u.Username()))
+		sb.WriteString(i18n.T(lang, i18n.MsgUserInfoUsername, html.EscapeString(string(u.Username()))))
 	}
 
-	sb.WriteString(fmt.Sprintf("🆔 <b>Telegram ID:</b> <code>%d</code>\n", u.TelegramID()))
-	sb.WriteString(fmt.Sprintf("🔑 <b>User ID:</b> <code>%s</code>\n", u.ID()))
+	sb.WriteString(i18n.T(lang, i18n.MsgUserInfoTelegramID, u.TelegramID()))
+	sb.WriteString(i18n.T(lang, i18n.MsgUserInfoUserID, u.ID()))
+	sb.WriteString(i18n.T(lang, i18n.MsgUserInfoLanguage, i18n.T(lang, i18n.LangName)))
 
 	// Timestamps
-	sb.WriteString("\n<b>⏰ Временные метки</b>\n")
-	sb.WriteString(fmt.Sprintf("📅 <b>Создан:</b> %s\n", formatTime(u.CreatedAt())))
-	sb.WriteString(fmt.Sprintf("🔄 <b>Обновлён:</b> %s\n", formatTime(u.UpdatedAt())))
+	sb.WriteString(i18n.T(lang, i18n.MsgUserInfoTimestamps))
+	sb.WriteString(i18n.T(lang, i18n.MsgUserInfoCreatedAt, formatTime(u.CreatedAt())))
+	sb.WriteString(i18n.T(lang, i18n.MsgUserInfoUpdatedAt, formatTime(u.UpdatedAt())))
 
 	return sb.String()
 }
@@ -0,0 +1,47 @@
+package msgs
+
+import (
+	"html"
+	"strings"
+	"time"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/scheduler"
+)
+
+const maxJobErrorLength = 500
+
+func Jobs(lang i18n.Lang, statuses []scheduler.Status) string {
+	if len(statuses) == 0 {
+		return i18n.T(lang, i18n.MsgNoJobs)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.MsgJobsTitle, len(statuses)))
+	for _, status := range statuses {
+		sb.WriteString(i18n.T(lang, i18n.MsgJobsItem, html.EscapeString(status.Name), html.EscapeString(status.Schedule)))
+		if status.LastRun.IsZero() {
+			sb.WriteString(i18n.T(lang, i18n.MsgJobsNeverRun))
+		} else {
+			sb.WriteString(i18n.T(lang, i18n.MsgJobsLastRun,
+				formatTime(status.LastRun),
+				status.LastDuration.Round(time.Millisecond).String(),
+				status.Runs,
+				status.Failures,
+			))
+		}
+		if status.Running {
+			sb.WriteString(i18n.T(lang, i18n.MsgJobsRunning))
+		}
+		if !status.NextRun.IsZero() {
+			sb.WriteString(i18n.T(lang, i18n.MsgJobsNextRun, formatTime(status.NextRun)))
+		}
+		if status.LastError != "" {
+			lastError := status.LastError
+			if len(lastError) > maxJobErrorLength {
+				lastError = strings.ToValidUTF8(lastError[:maxJobErrorLength], "") + "…"
+			}
+			sb.WriteString(i18n.T(lang, i18n.MsgJobsLastError, html.EscapeString(lastError)))
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,27 @@
+package msgs
+
+import (
+	"fmt"
+	"html"
+	"strings"
+	"whitelist-bot/internal/i18n"
+)
+
+func LanguageUsage(lang i18n.Lang) string {
+	available := make([]string, 0, len(i18n.Supported()))
+	for _, l := range i18n.Supported() {
+		available = append(available, fmt.Sprintf("<code>%s</code> (%s)", l, i18n.T(l, i18n.LangName)))
+	}
+	return i18n.T(lang, i18n.MsgLanguageUsage, strings.Join(available, ", "))
+}
+
+func LanguageUnsupported(lang i18n.Lang, code string) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.MsgLanguageUnsupported, html.EscapeString(code)))
+	sb.WriteString(LanguageUsage(lang))
+	return sb.String()
+}
+
+func LanguageChanged(lang i18n.Lang) string {
+	return i18n.T(lang, i18n.MsgLanguageChanged, i18n.T(lang, i18n.LangName))
+}
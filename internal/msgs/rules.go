@@ -0,0 +1,12 @@
+package msgs
+
+import (
+	"whitelist-bot/internal/i18n"
+)
+
+func Rules(lang i18n.Lang) string {
+	if text, ok := renderTemplate(lang, TemplateRules, rulesData{Server: server}); ok {
+		return text
+	}
+	return i18n.T(lang, i18n.MsgRules)
+}
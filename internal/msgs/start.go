@@ -1,14 +1,19 @@
 package msgs
 
 import (
-	"fmt"
 	"strings"
 	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/i18n"
 )
 
-func Start() string {
+func Start(lang i18n.Lang) string {
+	data := startData{NewWLRequestCommand: i18n.T(lang, core.CommandNewWLRequest)}
+	if text, ok := renderTemplate(lang, TemplateStart, data); ok {
+		return text
+	}
+
 	var sb strings.Builder
-	sb.WriteString("Привет! Я бот для подачи заявок в белый список.\n\n")
-	sb.WriteString(fmt.Sprintf("Чтобы создать заявку, напиши: <b>%s</b>", core.CommandNewWLRequest))
+	sb.WriteString(i18n.T(lang, i18n.MsgStartGreeting))
+	sb.WriteString(i18n.T(lang, i18n.MsgStartHint, data.NewWLRequestCommand))
 	return sb.String()
 }
@@ -0,0 +1,243 @@
+package msgs
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/i18n"
+)
+
+// Names of the messages that can be overridden by admin templates.
+// A template is looked up as <dir>/<lang>/<name>.tmpl and then as <dir>/<name>.tmpl.
+const (
+	TemplateStart                      = "start"
+	TemplateCancel                     = "cancel"
+	TemplateRules                      = "rules"
+	TemplateUserInfo                   = "user_info"
+	TemplateWaitingForNickname         = "waiting_for_nickname"
+	TemplateWLRequestCreated           = "wl_request_created"
+	TemplatePendingWLRequest           = "pending_wl_request"
+	TemplateNoPendingWLRequests        = "no_pending_wl_requests"
+	TemplateApprovedWLRequest          = "approved_wl_request"
+	TemplateDeclinedWLRequest          = "declined_wl_request"
+	TemplateRevokedWLRequest           = "revoked_wl_request"
+	TemplateWLRequestAdminNotification = "wl_request_admin_notification"
+
+	templateExt = ".tmpl"
+)
+
+var (
+	ErrUnknownTemplate  = errors.New("unknown template")
+	ErrInvalidTemplate  = errors.New("invalid template")
+	ErrTemplatesDirRead = errors.New("failed to read templates dir")
+)
+
+// templateSamples holds sample data for every overridable template.
+// Templates are executed against it on load, so typos in field names fail at startup.
+var templateSamples = map[string]any{
+	TemplateStart:                      startData{NewWLRequestCommand: "New request"},
+	TemplateCancel:                     struct{}{},
+	TemplateRules:                      rulesData{Server: sampleServer()},
+	TemplateUserInfo:                   sampleUser(),
+	TemplateWaitingForNickname:         struct{}{},
+	TemplateWLRequestCreated:           wlRequestData{Request: sampleWLRequest(), Requester: sampleUser(), Server: sampleServer()},
+	TemplatePendingWLRequest:           wlRequestData{Request: sampleWLRequest(), Requester: sampleUser(), Server: sampleServer()},
+	TemplateNoPendingWLRequests:        struct{}{},
+	TemplateApprovedWLRequest:          sampleDecidedWLRequest(),
+	TemplateDeclinedWLRequest:          sampleDecidedWLRequest(),
+	TemplateRevokedWLRequest:           sampleDecidedWLRequest(),
+	TemplateWLRequestAdminNotification: struct{}{},
+}
+
+type templateStore struct {
+	mu        sync.RWMutex
+	dir       string
+	signature string
+	templates map[string]*template.Template
+}
+
+var templates = &templateStore{}
+
+// LoadTemplates parses and validates every template in dir and replaces the active set.
+// On error the previously loaded templates stay active.
+func LoadTemplates(dir string) error {
+	signature, err := templatesSignature(dir)
+	if err != nil {
+		return err
+	}
+	loaded, err := parseTemplates(dir)
+	if err != nil {
+		return err
+	}
+
+	templates.mu.Lock()
+	defer templates.mu.Unlock()
+	templates.dir = dir
+	templates.signature = signature
+	templates.templates = loaded
+	return nil
+}
+
+// WatchTemplates reloads templates from the directory passed to LoadTemplates whenever its files change.
+// It blocks until ctx is done.
+func WatchTemplates(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			templates.mu.RLock()
+			dir, oldSignature := templates.dir, templates.signature
+			templates.mu.RUnlock()
+
+			signature, err := templatesSignature(dir)
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to check templates", logger.ErrorField, err.Error())
+				continue
+			}
+			if signature == oldSignature {
+				continue
+			}
+			if err := LoadTemplates(dir); err != nil {
+				slog.ErrorContext(ctx, "Failed to reload templates, keeping previous ones", logger.ErrorField, err.Error())
+				continue
+			}
+			slog.InfoContext(ctx, "Templates reloaded", "dir", dir)
+		}
+	}
+}
+
+// renderTemplate executes the admin template for name if one is loaded.
+// It returns false when the built-in message should be used instead.
+func renderTemplate(lang i18n.Lang, name string, data any) (string, bool) {
+	templates.mu.RLock()
+	tmpl, ok := templates.templates[templateKey(lang, name)]
+	if !ok {
+		tmpl, ok = templates.templates[name]
+	}
+	templates.mu.RUnlock()
+	if !ok {
+		return "", false
+	}
+
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, data); err != nil {
+		slog.Error("Failed to execute template, using default message", "template", name, logger.ErrorField, err.Error())
+		return "", false
+	}
+	return buf.String(), true
+}
+
+func parseTemplates(dir string) (map[string]*template.Template, error) {
+	files, err := templateFiles(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	loaded := make(map[string]*template.Template, len(files))
+	var errs []error
+	for _, path := range files {
+		key, name, err := templateKeyFromPath(dir, path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		content, err := os.ReadFile(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, path, err))
+			continue
+		}
+		tmpl, err := template.New(key).Option("missingkey=error").Parse(string(content))
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, path, err))
+			continue
+		}
+		if err := tmpl.Execute(io.Discard, templateSamples[name]); err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, path, err))
+			continue
+		}
+		loaded[key] = tmpl
+	}
+	if len(errs) > 0 {
+		return nil, errors.Join(errs...)
+	}
+	return loaded, nil
+}
+
+func templateFiles(dir string) ([]string, error) {
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && filepath.Ext(path) == templateExt {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrTemplatesDirRead, err)
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// templatesSignature summarises names, sizes and modification times of the template files.
+func templatesSignature(dir string) (string, error) {
+	files, err := templateFiles(dir)
+	if err != nil {
+		return "", err
+	}
+	var sb strings.Builder
+	for _, path := range files {
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", fmt.Errorf("%w: %w", ErrTemplatesDirRead, err)
+		}
+		fmt.Fprintf(&sb, "%s:%d:%d;", path, info.Size(), info.ModTime().UnixNano())
+	}
+	return sb.String(), nil
+}
+
+func templateKeyFromPath(dir string, path string) (string, string, error) {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %s: %w", ErrInvalidTemplate, path, err)
+	}
+	name := strings.TrimSuffix(filepath.Base(rel), templateExt)
+	if _, ok := templateSamples[name]; !ok {
+		return "", "", fmt.Errorf("%w: %s", ErrUnknownTemplate, path)
+	}
+
+	switch parts := strings.Split(filepath.ToSlash(rel), "/"); len(parts) {
+	case 1:
+		return name, name, nil
+	case 2:
+		lang := i18n.Lang(parts[0])
+		if !lang.IsSupported() {
+			return "", "", fmt.Errorf("%w: %s: unsupported language %q", ErrInvalidTemplate, path, lang)
+		}
+		return templateKey(lang, name), name, nil
+	default:
+		return "", "", fmt.Errorf("%w: %s: templates must be placed in <dir> or <dir>/<lang>", ErrInvalidTemplate, path)
+	}
+}
+
+func templateKey(lang i18n.Lang, name string) string {
+	return string(lang) + "/" + name
+}
@@ -0,0 +1,189 @@
+package msgs
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"whitelist-bot/internal/i18n"
+
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeTemplate(t *testing.T, path string, content string) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	// Write through a rename so the watcher never sees a half-written file.
+	tmp := path + ".tmp"
+	require.NoError(t, os.WriteFile(tmp, []byte(content), 0o644))
+	require.NoError(t, os.Rename(tmp, path))
+}
+
+func resetTemplates(t *testing.T) {
+	t.Helper()
+	t.Cleanup(func() {
+		templates = &templateStore{}
+	})
+}
+
+func TestLoadTemplates_OverridesAndFallbacks(t *testing.T) {
+	resetTemplates(t)
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "start.tmpl"), "Welcome! Send {{.NewWLRequestCommand}}")
+	writeTemplate(t, filepath.Join(dir, "en", "start.tmpl"), "Hello! Send {{.NewWLRequestCommand}}")
+
+	require.NoError(t, LoadTemplates(dir))
+
+	assert.Equal(t, "Hello! Send New request", Start(i18n.LangEN))
+	assert.Equal(t, "Welcome! Send Новая заявка", Start(i18n.LangRU))
+	assert.Equal(t, i18n.T(i18n.LangEN, i18n.MsgCancel), Cancel(i18n.LangEN))
+}
+
+func TestLoadTemplates_EscapesUserFields(t *testing.T) {
+	resetTemplates(t)
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "approved_wl_request.tmpl"),
+		"{{.Request.Nickname}} by @{{.Requester.Username}}, server: 1.2.3.4")
+	require.NoError(t, LoadTemplates(dir))
+
+	now := time.Now()
+	requester, err := domainUser.NewBuilder().
+		NewID().
+		TelegramIDFromInt(1).
+		ChatIDFromInt(1).
+		UsernameFromString("<b>user</b>").
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	wlRequest, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterIDFromUserID(requester.ID()).
+		NicknameFromString("<i>nick</i>").
+		StatusFromString(string(domainWLRequest.StatusPending)).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+
+	text := ApprovedWLRequest(i18n.LangRU, wlRequest, requester, requester)
+	assert.Equal(t, "&lt;i&gt;nick&lt;/i&gt; by @&lt;b&gt;user&lt;/b&gt;, server: 1.2.3.4", text)
+}
+
+func TestLoadTemplates_Server(t *testing.T) {
+	resetTemplates(t)
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "rules.tmpl"), "Be nice on {{.Server.Address}}")
+	writeTemplate(t, filepath.Join(dir, "approved_wl_request.tmpl"), "{{.Request.Nickname}}, join {{.Server.Address}}")
+	require.NoError(t, LoadTemplates(dir))
+	SetServer("play.example.com")
+	t.Cleanup(func() { SetServer("") })
+
+	now := time.Now()
+	user, err := domainUser.NewBuilder().
+		NewID().
+		TelegramIDFromInt(1).
+		ChatIDFromInt(1).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	wlRequest, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterIDFromUserID(user.ID()).
+		NicknameFromString("steve").
+		ArbiterIDFromUserID(user.ID()).
+		StatusFromString(string(domainWLRequest.StatusApproved)).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "Be nice on play.example.com", Rules(i18n.LangEN))
+	assert.Equal(t, "steve, join play.example.com", ApprovedWLRequest(i18n.LangEN, wlRequest, user, user))
+}
+
+func TestLoadTemplates_Validation(t *testing.T) {
+	tests := []struct {
+		name  string
+		path  string
+		body  string
+		error error
+	}{
+		{name: "unknown_template", path: "faq.tmpl", body: "faq", error: ErrUnknownTemplate},
+		{name: "syntax_error", path: "start.tmpl", body: "{{.NewWLRequestCommand", error: ErrInvalidTemplate},
+		{name: "unknown_field", path: "start.tmpl", body: "{{.ServerIP}}", error: ErrInvalidTemplate},
+		{name: "unknown_server_field", path: "approved_wl_request.tmpl", body: "{{.Server.IP}}", error: ErrInvalidTemplate},
+		{name: "unsupported_language", path: "de/start.tmpl", body: "Hallo", error: ErrInvalidTemplate},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resetTemplates(t)
+			dir := t.TempDir()
+			writeTemplate(t, filepath.Join(dir, tt.path), tt.body)
+
+			err := LoadTemplates(dir)
+			require.ErrorIs(t, err, tt.error)
+		})
+	}
+}
+
+func TestWatchTemplates_ReloadsAndKeepsValidSet(t *testing.T) {
+	resetTemplates(t)
+	dir := t.TempDir()
+	path := filepath.Join(dir, "cancel.tmpl")
+	writeTemplate(t, path, "v1")
+	require.NoError(t, LoadTemplates(dir))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		WatchTemplates(ctx, 10*time.Millisecond)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	writeTemplate(t, path, "version 2")
+	require.Eventually(t, func() bool { return Cancel(i18n.LangEN) == "version 2" }, time.Second, 10*time.Millisecond)
+
+	writeTemplate(t, path, "{{.Broken")
+	time.Sleep(50 * time.Millisecond)
+	assert.Equal(t, "version 2", Cancel(i18n.LangEN))
+}
+
+func TestLoadTemplates_WLRequestCreatedRequester(t *testing.T) {
+	resetTemplates(t)
+	dir := t.TempDir()
+	writeTemplate(t, filepath.Join(dir, "wl_request_created.tmpl"), "{{.Request.Nickname}} from @{{.Requester.Username}}")
+	require.NoError(t, LoadTemplates(dir))
+
+	now := time.Now()
+	requester, err := domainUser.NewBuilder().
+		NewID().
+		TelegramIDFromInt(1).
+		ChatIDFromInt(1).
+		UsernameFromString("user").
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+	wlRequest, err := domainWLRequest.NewBuilder().
+		NewID().
+		RequesterIDFromUserID(requester.ID()).
+		NicknameFromString("steve").
+		StatusFromString(string(domainWLRequest.StatusPending)).
+		CreatedAt(now).
+		UpdatedAt(now).
+		Build()
+	require.NoError(t, err)
+
+	assert.Equal(t, "steve from @user", WLRequestCreated(i18n.LangEN, wlRequest, requester))
+}
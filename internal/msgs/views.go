@@ -0,0 +1,161 @@
+package msgs
+
+import (
+	"fmt"
+	"html"
+	"strconv"
+	"strings"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+)
+
+// View types are what admin templates see. Every user-provided field is HTML-escaped here,
+// so templates can print them as is.
+
+type userView struct {
+	ID         string
+	TelegramID int64
+	Username   string
+	FirstName  string
+	LastName   string
+	FullName   string
+	Language   string
+	CreatedAt  string
+	UpdatedAt  string
+	// Mention is an HTML link to the user's profile, it works without a public username too.
+	Mention string
+	// PreviousUsernames is filled only where admins need it, see PendingWLRequest.
+	PreviousUsernames []string
+}
+
+type wlRequestView struct {
+	ID            string
+	Nickname      string
+	Status        string
+	DeclineReason string
+	CreatedAt     string
+}
+
+type wlRequestData struct {
+	Request   wlRequestView
+	Requester userView
+	Arbiter   userView
+	Server    serverView
+}
+
+type startData struct {
+	NewWLRequestCommand string
+}
+
+// serverView is the game server, configured by the admins.
+type serverView struct {
+	Address string
+}
+
+type rulesData struct {
+	Server serverView
+}
+
+// server is set once at startup by SetServer.
+var server serverView
+
+// SetServer sets the game server address shown by the templates.
+func SetServer(address string) {
+	server = serverView{Address: address}
+}
+
+func newUserView(u domainUser.User) userView {
+	fullName := strings.TrimSpace(string(u.FirstName()) + " " + string(u.LastName()))
+	return userView{
+		ID:         u.ID().String(),
+		TelegramID: int64(u.TelegramID()),
+		Mention:    mention(u),
+		Username:   html.EscapeString(string(u.Username())),
+		FirstName:  html.EscapeString(string(u.FirstName())),
+		LastName:   html.EscapeString(string(u.LastName())),
+		FullName:   html.EscapeString(fullName),
+		Language:   html.EscapeString(string(u.LanguageCode())),
+		CreatedAt:  formatTime(u.CreatedAt()),
+		UpdatedAt:  formatTime(u.UpdatedAt()),
+	}
+}
+
+// mention links to the user by Telegram ID, so admins can reach users without a public username.
+// The username is added when there is one.
+func mention(u domainUser.User) string {
+	name := strings.TrimSpace(string(u.FirstName()) + " " + string(u.LastName()))
+	if name == "" {
+		name = string(u.Username())
+	}
+	if name == "" {
+		name = strconv.FormatInt(int64(u.TelegramID()), 10)
+	}
+	link := fmt.Sprintf(`<a href="tg://user?id=%d">%s</a>`, u.TelegramID(), html.EscapeString(name))
+	if !u.Username().IsZero() {
+		link += " (@" + html.EscapeString(string(u.Username())) + ")"
+	}
+	return link
+}
+
+func previousUsernamesView(usernames []domainUser.Username) []string {
+	views := make([]string, 0, len(usernames))
+	for _, username := range usernames {
+		views = append(views, html.EscapeString(string(username)))
+	}
+	return views
+}
+
+func newWLRequestView(w domainWLRequest.WLRequest) wlRequestView {
+	return wlRequestView{
+		ID:            w.ID().String(),
+		Nickname:      html.EscapeString(string(w.Nickname())),
+		Status:        string(w.Status()),
+		DeclineReason: html.EscapeString(string(w.DeclineReason())),
+		CreatedAt:     w.CreatedAt().Format(timeFormat),
+	}
+}
+
+func newWLRequestData(w domainWLRequest.WLRequest, arbiter domainUser.User, requester domainUser.User) wlRequestData {
+	return wlRequestData{
+		Request:   newWLRequestView(w),
+		Requester: newUserView(requester),
+		Arbiter:   newUserView(arbiter),
+		Server:    server,
+	}
+}
+
+func sampleUser() userView {
+	now := formatTime(time.Now())
+	return userView{
+		ID:                "00000000-0000-0000-0000-000000000000",
+		TelegramID:        123456789,
+		Mention:           `<a href="tg://user?id=123456789">First Last</a> (@username)`,
+		Username:          "username",
+		FirstName:         "First",
+		LastName:          "Last",
+		FullName:          "First Last",
+		Language:          "en",
+		CreatedAt:         now,
+		UpdatedAt:         now,
+		PreviousUsernames: []string{"old_username"},
+	}
+}
+
+func sampleWLRequest() wlRequestView {
+	return wlRequestView{
+		ID:            "00000000-0000-0000-0000-000000000000",
+		Nickname:      "nickname",
+		Status:        string(domainWLRequest.StatusPending),
+		DeclineReason: "reason",
+		CreatedAt:     time.Now().Format(timeFormat),
+	}
+}
+
+func sampleServer() serverView {
+	return serverView{Address: "play.example.com:25565"}
+}
+
+func sampleDecidedWLRequest() wlRequestData {
+	return wlRequestData{Request: sampleWLRequest(), Requester: sampleUser(), Arbiter: sampleUser(), Server: sampleServer()}
+}
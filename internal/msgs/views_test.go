@@ -0,0 +1,67 @@
+package msgs
+
+import (
+	"testing"
+	"time"
+
+	domainUser "whitelist-bot/internal/domain/user"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMention(t *testing.T) {
+	tests := []struct {
+		name      string
+		firstName string
+		lastName  string
+		username  string
+		want      string
+	}{
+		{
+			name:      "name and username",
+			firstName: "John",
+			lastName:  "Doe",
+			username:  "john",
+			want:      `<a href="tg://user?id=42">John Doe</a> (@john)`,
+		},
+		{
+			name:      "hidden username",
+			firstName: "John",
+			want:      `<a href="tg://user?id=42">John</a>`,
+		},
+		{
+			name:     "username only",
+			username: "john",
+			want:     `<a href="tg://user?id=42">john</a> (@john)`,
+		},
+		{
+			name: "neither name nor username",
+			want: `<a href="tg://user?id=42">42</a>`,
+		},
+		{
+			name:      "name is escaped",
+			firstName: "<b>John</b>",
+			want:      `<a href="tg://user?id=42">&lt;b&gt;John&lt;/b&gt;</a>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			now := time.Now()
+			user, err := domainUser.NewBuilder().
+				NewID().
+				TelegramIDFromInt(42).
+				ChatIDFromInt(42).
+				FirstNameFromString(tt.firstName).
+				LastNameFromString(tt.lastName).
+				UsernameFromString(tt.username).
+				CreatedAt(now).
+				UpdatedAt(now).
+				Build()
+			require.NoError(t, err)
+
+			assert.Equal(t, tt.want, mention(user))
+		})
+	}
+}
@@ -1,88 +1,266 @@
 package msgs
 
 import (
-	"fmt"
 	"html"
 	"strings"
+	"time"
 	domainUser "whitelist-bot/internal/domain/user"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/i18n"
 )
 
 const (
 	timeFormat = "02.01.2006 15:04:05"
+	// maxExpiredReportItems keeps the report within the Telegram message limit.
+	maxExpiredReportItems = 30
 )
 
-func WaitingForNickname() string {
+func WaitingForNickname(lang i18n.Lang) string {
+	if text, ok := renderTemplate(lang, TemplateWaitingForNickname, struct{}{}); ok {
+		return text
+	}
+	return i18n.T(lang, i18n.MsgWaitingForNickname)
+}
+
+func WLRequestCreated(lang i18n.Lang, wlRequest domainWLRequest.WLRequest, requester domainUser.User) string {
+	data := wlRequestData{Request: newWLRequestView(wlRequest), Requester: newUserView(requester), Server: server}
+	if text, ok := renderTemplate(lang, TemplateWLRequestCreated, data); ok {
+		return text
+	}
+
 	var sb strings.Builder
-	sb.WriteString("Привет! Отправь свой ник, чтобы подать заявку в белый список.\n")
-	sb.WriteString("Если в твоём нике есть спец. символы, то оберни его в\n <code>```\nnickname\n```</code>\n\n")
-	sb.WriteString("Чтобы отменить заявку, напиши: /cancel")
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestCreatedTitle))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestNickname, html.EscapeString(string(wlRequest.Nickname()))))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestID, wlRequest.ID()))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestCreatedAt, wlRequest.CreatedAt().Format(timeFormat)))
 	return sb.String()
 }
 
-func WLRequestCreated(wlRequest domainWLRequest.WLRequest) string {
+// PendingWLRequest renders a request for review. previousUsernames are the requester's former usernames,
+// so admins can recognize someone who came back under a new one.
+func PendingWLRequest(
+	lang i18n.Lang,
+	wlRequest domainWLRequest.WLRequest,
+	requester domainUser.User,
+	previousUsernames []domainUser.Username,
+) string {
+	data := wlRequestData{Request: newWLRequestView(wlRequest), Requester: newUserView(requester), Server: server}
+	data.Requester.PreviousUsernames = previousUsernamesView(previousUsernames)
+	if text, ok := renderTemplate(lang, TemplatePendingWLRequest, data); ok {
+		return text
+	}
+
 	var sb strings.Builder
-	sb.WriteString("<b>Заявка в белый список успешно отправлена</b>\n\n")
-	sb.WriteString(fmt.Sprintf("👤 <b>Ник:</b> %s\n", html.EscapeString(string(wlRequest.Nickname()))))
-	sb.WriteString(fmt.Sprintf("🆔 <b>ID заявки:</b> <code>%s</code>\n", wlRequest.ID()))
-	sb.WriteString(fmt.Sprintf("📅 <b>Создана:</b> %s\n", wlRequest.CreatedAt().Format(timeFormat)))
+	sb.WriteString(i18n.T(lang, i18n.MsgPendingWLRequestTitle))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestNickname, html.EscapeString(string(wlRequest.Nickname()))))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestID, wlRequest.ID()))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestRequester, data.Requester.Mention))
+	if len(data.Requester.PreviousUsernames) > 0 {
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestPreviously, "@"+strings.Join(data.Requester.PreviousUsernames, ", @")))
+	}
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestCreatedAt, wlRequest.CreatedAt().Format(timeFormat)))
 	return sb.String()
 }
 
-func PendingWLRequest(wlRequest domainWLRequest.WLRequest, requester domainUser.User) string {
+func NoPendingWLRequests(lang i18n.Lang) string {
+	if text, ok := renderTemplate(lang, TemplateNoPendingWLRequests, struct{}{}); ok {
+		return text
+	}
+	return i18n.T(lang, i18n.MsgNoPendingWLRequests)
+}
+
+func CallbackError(lang i18n.Lang, errorText i18n.Key) string {
+	return i18n.T(lang, i18n.MsgCallbackError, i18n.T(lang, errorText))
+}
+
+func CallbackSuccess(lang i18n.Lang, successText string) string {
+	return i18n.T(lang, i18n.MsgCallbackSuccess, successText)
+}
+
+func ApprovedWLRequest(
+	lang i18n.Lang,
+	wlRequest domainWLRequest.WLRequest,
+	arbiter domainUser.User,
+	requester domainUser.User,
+) string {
+	if text, ok := renderTemplate(lang, TemplateApprovedWLRequest, newWLRequestData(wlRequest, arbiter, requester)); ok {
+		return text
+	}
+
 	var sb strings.Builder
-	sb.WriteString("📋 <b>Ожидающая заявка</b>\n\n")
-	sb.WriteString(fmt.Sprintf("👤 <b>Ник:</b> %s\n", html.EscapeString(string(wlRequest.Nickname()))))
-	sb.WriteString(fmt.Sprintf("🆔 <b>ID заявки:</b> <code>%s</code>\n", wlRequest.ID()))
-	sb.WriteString(fmt.Sprintf("👥 <b>Заявитель:</b> @%s\n", requester.Username()))
-	sb.WriteString(fmt.Sprintf("📅 <b>Создана:</b> %s\n", wlRequest.CreatedAt().Format(timeFormat)))
+	sb.WriteString(i18n.T(lang, i18n.MsgApprovedWLRequestTitle))
+	wlRequestBody(&sb, lang, wlRequest, arbiter, requester)
 	return sb.String()
 }
 
-func NoPendingWLRequests() string {
-	return "✅ <b>Нет ожидающих заявок</b>\n\nВсе заявки обработаны!"
-}
+func DeclinedWLRequest(
+	lang i18n.Lang,
+	wlRequest domainWLRequest.WLRequest,
+	arbiter domainUser.User,
+	requester domainUser.User,
+) string {
+	if text, ok := renderTemplate(lang, TemplateDeclinedWLRequest, newWLRequestData(wlRequest, arbiter, requester)); ok {
+		return text
+	}
 
-func CallbackError(errorText string) string {
-	return fmt.Sprintf("❌ <b>Ошибка:</b> %s", errorText)
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.MsgDeclinedWLRequestTitle))
+	wlRequestBody(&sb, lang, wlRequest, arbiter, requester)
+	return sb.String()
 }
 
-func CallbackSuccess(successText string) string {
-	return fmt.Sprintf("✅ <b>Успех:</b> %s", successText)
+func RevokedWLRequest(
+	lang i18n.Lang,
+	wlRequest domainWLRequest.WLRequest,
+	arbiter domainUser.User,
+	requester domainUser.User,
+) string {
+	if text, ok := renderTemplate(lang, TemplateRevokedWLRequest, newWLRequestData(wlRequest, arbiter, requester)); ok {
+		return text
+	}
+
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.MsgRevokedWLRequestTitle))
+	wlRequestBody(&sb, lang, wlRequest, arbiter, requester)
+	return sb.String()
 }
 
-func ApprovedWLRequest(wlRequest domainWLRequest.WLRequest, arbiter domainUser.User, requester domainUser.User) string {
+// WLRequestDetails renders a request in any status for /show. arbiter is nil while nobody has reviewed it
+// and for requests closed by the bot itself.
+func WLRequestDetails(
+	lang i18n.Lang,
+	wlRequest domainWLRequest.WLRequest,
+	requester domainUser.User,
+	arbiter *domainUser.User,
+) string {
 	var sb strings.Builder
-	sb.WriteString("✅ <b>Заявка подтверждена!</b>\n\n")
-	wlRequestBody(&sb, wlRequest, arbiter, requester)
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestDetailsTitle))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestNickname, html.EscapeString(string(wlRequest.Nickname()))))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestStatus, wlRequestStatus(lang, wlRequest.Status())))
+	if wlRequest.Status() == domainWLRequest.StatusDeclined && !wlRequest.DeclineReason().IsZero() {
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestDeclineReason, html.EscapeString(string(wlRequest.DeclineReason()))))
+	}
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestRequester, mention(requester)))
+	if arbiter != nil {
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestArbiter, mention(*arbiter)))
+	}
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestID, wlRequest.ID()))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestCreatedAt, wlRequest.CreatedAt().Format(timeFormat)))
 	return sb.String()
 }
 
-func DeclinedWLRequest(wlRequest domainWLRequest.WLRequest, arbiter domainUser.User, requester domainUser.User) string {
+// AmbiguousWLRequest lists the requests a nickname matches, at most limit of them.
+func AmbiguousWLRequest(lang i18n.Lang, nickname string, candidates []domainWLRequest.WLRequest, limit int) string {
 	var sb strings.Builder
-	sb.WriteString("❌ <b>Заявка отклонена!</b>\n\n")
-	wlRequestBody(&sb, wlRequest, arbiter, requester)
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestAmbiguous, html.EscapeString(nickname)))
+	for i, wlRequest := range candidates {
+		if i == limit {
+			sb.WriteString(i18n.T(lang, i18n.MsgWLRequestCandidatesMore))
+			break
+		}
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestCandidate,
+			wlRequest.ID(),
+			wlRequestStatus(lang, wlRequest.Status()),
+			wlRequest.CreatedAt().Format(timeFormat),
+		))
+	}
 	return sb.String()
 }
 
+func WLRequestCommandsUsage(lang i18n.Lang) string {
+	return i18n.T(lang, i18n.MsgWLRequestCommandsUsage)
+}
+
+var wlRequestStatusKeys = map[domainWLRequest.Status]i18n.Key{
+	domainWLRequest.StatusPending:  i18n.MsgWLRequestStatusPending,
+	domainWLRequest.StatusApproved: i18n.MsgWLRequestStatusApproved,
+	domainWLRequest.StatusDeclined: i18n.MsgWLRequestStatusDeclined,
+	domainWLRequest.StatusExpired:  i18n.MsgWLRequestStatusExpired,
+	domainWLRequest.StatusRevoked:  i18n.MsgWLRequestStatusRevoked,
+}
+
+func wlRequestStatus(lang i18n.Lang, status domainWLRequest.Status) string {
+	if key, ok := wlRequestStatusKeys[status]; ok {
+		return i18n.T(lang, key)
+	}
+	return string(status)
+}
+
 func wlRequestBody(
 	sb *strings.Builder,
+	lang i18n.Lang,
 	wlRequest domainWLRequest.WLRequest,
 	arbiter domainUser.User,
 	requester domainUser.User,
 ) {
-	fmt.Fprintf(sb, "👤 <b>Ник:</b> %s\n", html.EscapeString(string(wlRequest.Nickname())))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestNickname, html.EscapeString(string(wlRequest.Nickname()))))
 	if wlRequest.Status() == domainWLRequest.StatusDeclined && !wlRequest.DeclineReason().IsZero() {
-		fmt.Fprintf(sb, "🔄 <b>Причина отказа:</b> %s\n", html.EscapeString(string(wlRequest.DeclineReason())))
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestDeclineReason, html.EscapeString(string(wlRequest.DeclineReason()))))
 	}
-	fmt.Fprintf(sb, "🔗 <b>Заявитель:</b> @%s\n", requester.Username())
-	fmt.Fprintf(sb, "🔗 <b>Арбитр:</b> @%s\n", arbiter.Username())
-	fmt.Fprintf(sb, "🆔 <b>ID заявки:</b> <code>%s</code>\n", wlRequest.ID())
-	fmt.Fprintf(sb, "📅 <b>Создана:</b> %s\n", wlRequest.CreatedAt().Format(timeFormat))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestRequester, mention(requester)))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestArbiter, mention(arbiter)))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestID, wlRequest.ID()))
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestCreatedAt, wlRequest.CreatedAt().Format(timeFormat)))
 }
 
-func WLRequestAdminNotification() string {
+func WLRequestAdminNotification(lang i18n.Lang) string {
+	if text, ok := renderTemplate(lang, TemplateWLRequestAdminNotification, struct{}{}); ok {
+		return text
+	}
 	var sb strings.Builder
-	sb.WriteString("📋 <b>Новая заявка в белый список</b>\n\n")
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestAdminNotify))
+	return sb.String()
+}
+
+// WLRequestExpired tells the requester that nobody reviewed the request in time and invites them to reapply.
+func WLRequestExpired(lang i18n.Lang, wlRequest domainWLRequest.WLRequest, maxPendingAge time.Duration) string {
+	return i18n.T(lang, i18n.MsgWLRequestExpired,
+		html.EscapeString(string(wlRequest.Nickname())),
+		int(maxPendingAge.Hours()),
+		i18n.T(lang, i18n.ButtonNewWLRequest),
+	)
+}
+
+// WLRequestsExpiredReport lists requests expired by one run of the expiration job for admins.
+func WLRequestsExpiredReport(lang i18n.Lang, wlRequests []domainWLRequest.WLRequest, maxPendingAge time.Duration) string {
+	var sb strings.Builder
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestsExpiredTitle, len(wlRequests), int(maxPendingAge.Hours())))
+	for i, wlRequest := range wlRequests {
+		if i == maxExpiredReportItems {
+			sb.WriteString(i18n.T(lang, i18n.MsgWLRequestsExpiredMore, len(wlRequests)-i))
+			break
+		}
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestsExpiredItem,
+			html.EscapeString(string(wlRequest.Nickname())),
+			wlRequest.ID(),
+			wlRequest.CreatedAt().Format(timeFormat),
+		))
+	}
+	return sb.String()
+}
+
+// WLRequestReminder reminds admins about the review backlog. Escalations go to owners.
+func WLRequestReminder(
+	lang i18n.Lang,
+	escalation bool,
+	backlog int64,
+	overdue int,
+	after time.Duration,
+	oldest domainWLRequest.WLRequest,
+) string {
+	var sb strings.Builder
+	if escalation {
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestEscalateTitle))
+	} else {
+		sb.WriteString(i18n.T(lang, i18n.MsgWLRequestReminderTitle))
+	}
+	sb.WriteString(i18n.T(lang, i18n.MsgWLRequestReminderBody,
+		backlog,
+		int(after.Hours()),
+		overdue,
+		html.EscapeString(string(oldest.Nickname())),
+		oldest.ID(),
+		oldest.CreatedAt().Format(timeFormat),
+	))
 	return sb.String()
 }
@@ -0,0 +1,54 @@
+package outbox
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"time"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/eventbus"
+)
+
+// Message is an event stored in the outbox table within the same transaction as the change it describes.
+// The relay publishes it to the event bus later.
+type Message struct {
+	ID        utils.UniqueID
+	Topic     string
+	Data      []byte
+	CreatedAt time.Time
+}
+
+// NewMessage marshals data into an outbox message for the topic.
+func NewMessage(topic string, data any) (Message, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return Message{}, fmt.Errorf("failed to marshal outbox message data: %w", err)
+	}
+	return Message{
+		ID:        utils.NewUniqueID(),
+		Topic:     topic,
+		Data:      raw,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// NewEvent wraps the payload into an event envelope of the topic and stores it as an outbox message.
+func NewEvent[T any](ctx context.Context, topic eventbus.Topic[T], payload T) (Message, error) {
+	envelope, err := topic.Envelope(ctx, payload)
+	if err != nil {
+		return Message{}, err
+	}
+	return NewMessage(topic.Name, envelope)
+}
+
+// SortMessages orders messages by creation time and then by ID, which is time-ordered as well.
+func SortMessages(messages []Message) {
+	slices.SortFunc(messages, func(a, b Message) int {
+		if c := a.CreatedAt.Compare(b.CreatedAt); c != 0 {
+			return c
+		}
+		return bytes.Compare(a.ID[:], b.ID[:])
+	})
+}
@@ -0,0 +1,124 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/eventbus"
+)
+
+type iRepository interface {
+	// ClaimOutboxMessages returns up to limit undelivered messages that are not claimed by another relay
+	// and claims them until lockedUntil, oldest first.
+	ClaimOutboxMessages(ctx context.Context, limit int64, now, lockedUntil time.Time) ([]Message, error)
+	MarkOutboxMessageDelivered(ctx context.Context, id utils.UniqueID, deliveredAt time.Time) error
+	DeleteDeliveredOutboxMessages(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Relay drains the outbox into the event bus. A message is marked as delivered only after it is published,
+// so a crash in between publishes it again: delivery is at-least-once and consumers must tolerate duplicates.
+type Relay struct {
+	repo      iRepository
+	publisher eventbus.IEventPublisher
+
+	pollInterval time.Duration
+	batchSize    int64
+	leaseTTL     time.Duration
+	retention    time.Duration
+}
+
+func NewRelay(
+	repo iRepository,
+	publisher eventbus.IEventPublisher,
+	pollInterval time.Duration,
+	batchSize int64,
+	leaseTTL time.Duration,
+	retention time.Duration,
+) *Relay {
+	return &Relay{
+		repo:         repo,
+		publisher:    publisher,
+		pollInterval: pollInterval,
+		batchSize:    batchSize,
+		leaseTTL:     leaseTTL,
+		retention:    retention,
+	}
+}
+
+// Run relays messages every poll interval until ctx is done. Cleanup is run separately as a scheduled job.
+func (r *Relay) Run(ctx context.Context) {
+	pollTicker := time.NewTicker(r.pollInterval)
+	defer pollTicker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-pollTicker.C:
+			err := r.Drain(ctx)
+			if errors.Is(err, eventbus.ErrBufferFull) {
+				// The bus is overloaded, not broken. The message is published again once its claim expires,
+				// like any failed one; the bus pushed it to no subscriber, so nobody gets it twice.
+				slog.WarnContext(ctx, "Event bus is full, outbox messages are delayed", logger.ErrorField, err.Error())
+				continue
+			}
+			if err != nil {
+				slog.ErrorContext(ctx, "Failed to relay outbox messages", logger.ErrorField, err.Error())
+			}
+		}
+	}
+}
+
+// Drain publishes claimed batches until the outbox is empty. It stops at the first failed message,
+// which is retried once its claim expires. A full event bus buffer is one of these failures,
+// the returned error wraps eventbus.ErrBufferFull then.
+func (r *Relay) Drain(ctx context.Context) error {
+	for {
+		now := time.Now()
+		messages, err := r.repo.ClaimOutboxMessages(ctx, r.batchSize, now, now.Add(r.leaseTTL))
+		if err != nil {
+			return fmt.Errorf("failed to claim outbox messages: %w", err)
+		}
+
+		for _, message := range messages {
+			if err := r.publish(ctx, message); err != nil {
+				return err
+			}
+		}
+
+		if int64(len(messages)) < r.batchSize {
+			return nil
+		}
+	}
+}
+
+func (r *Relay) publish(ctx context.Context, message Message) error {
+	ctx = logger.WithLogValue(ctx, logger.OutboxMessageIDField, message.ID.String())
+	ctx = logger.WithLogValue(ctx, logger.TopicField, message.Topic)
+
+	if err := r.publisher.Publish(ctx, message.Topic, json.RawMessage(message.Data)); err != nil {
+		return fmt.Errorf("failed to publish outbox message %s: %w", message.ID, err)
+	}
+	if err := r.repo.MarkOutboxMessageDelivered(ctx, message.ID, time.Now()); err != nil {
+		return fmt.Errorf("failed to mark outbox message %s as delivered: %w", message.ID, err)
+	}
+	slog.DebugContext(ctx, "Outbox message relayed")
+	return nil
+}
+
+// Cleanup deletes messages delivered earlier than the retention.
+func (r *Relay) Cleanup(ctx context.Context) error {
+	deleted, err := r.repo.DeleteDeliveredOutboxMessages(ctx, time.Now().Add(-r.retention))
+	if err != nil {
+		return fmt.Errorf("failed to delete delivered outbox messages: %w", err)
+	}
+	if deleted > 0 {
+		slog.InfoContext(ctx, "Delivered outbox messages deleted", "count", deleted)
+	}
+	return nil
+}
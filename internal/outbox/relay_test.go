@@ -0,0 +1,226 @@
+package outbox
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/eventbus"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRepository keeps messages in creation order and mimics claiming with a lease.
+type fakeRepository struct {
+	messages    []Message
+	lockedUntil map[utils.UniqueID]time.Time
+	delivered   map[utils.UniqueID]time.Time
+	claimErr    error
+}
+
+func newFakeRepository(messages ...Message) *fakeRepository {
+	return &fakeRepository{
+		messages:    messages,
+		lockedUntil: make(map[utils.UniqueID]time.Time),
+		delivered:   make(map[utils.UniqueID]time.Time),
+	}
+}
+
+func (r *fakeRepository) ClaimOutboxMessages(
+	_ context.Context,
+	limit int64,
+	now, lockedUntil time.Time,
+) ([]Message, error) {
+	if r.claimErr != nil {
+		return nil, r.claimErr
+	}
+	var claimed []Message
+	for _, message := range r.messages {
+		if int64(len(claimed)) == limit {
+			break
+		}
+		if _, ok := r.delivered[message.ID]; ok {
+			continue
+		}
+		if r.lockedUntil[message.ID].After(now) {
+			continue
+		}
+		r.lockedUntil[message.ID] = lockedUntil
+		claimed = append(claimed, message)
+	}
+	return claimed, nil
+}
+
+func (r *fakeRepository) MarkOutboxMessageDelivered(_ context.Context, id utils.UniqueID, deliveredAt time.Time) error {
+	r.delivered[id] = deliveredAt
+	delete(r.lockedUntil, id)
+	return nil
+}
+
+func (r *fakeRepository) DeleteDeliveredOutboxMessages(_ context.Context, before time.Time) (int64, error) {
+	var deleted int64
+	kept := r.messages[:0]
+	for _, message := range r.messages {
+		if deliveredAt, ok := r.delivered[message.ID]; ok && deliveredAt.Before(before) {
+			delete(r.delivered, message.ID)
+			deleted++
+			continue
+		}
+		kept = append(kept, message)
+	}
+	r.messages = kept
+	return deleted, nil
+}
+
+type published struct {
+	topic string
+	data  string
+}
+
+type fakePublisher struct {
+	published []published
+	// failOn makes Publish fail for the message with this data.
+	failOn string
+	// failErr is the error returned for failOn, a generic one if nil.
+	failErr error
+}
+
+func (p *fakePublisher) Publish(_ context.Context, topic string, data any) error {
+	raw, ok := data.(json.RawMessage)
+	if !ok {
+		return errors.New("unexpected data type")
+	}
+	if string(raw) == p.failOn {
+		if p.failErr != nil {
+			return p.failErr
+		}
+		return errors.New("bus unavailable")
+	}
+	p.published = append(p.published, published{topic: topic, data: string(raw)})
+	return nil
+}
+
+func newTestMessage(t *testing.T, topic string, data any) Message {
+	t.Helper()
+	message, err := NewMessage(topic, data)
+	require.NoError(t, err)
+	return message
+}
+
+func TestRelay_Drain(t *testing.T) {
+	t.Parallel()
+
+	t.Run("publishes all messages in order across batches", func(t *testing.T) {
+		t.Parallel()
+
+		messages := []Message{
+			newTestMessage(t, "a", 1),
+			newTestMessage(t, "b", 2),
+			newTestMessage(t, "a", 3),
+		}
+		repo := newFakeRepository(messages...)
+		publisher := &fakePublisher{}
+		relay := NewRelay(repo, publisher, time.Second, 2, time.Minute, time.Hour)
+
+		require.NoError(t, relay.Drain(context.Background()))
+
+		assert.Equal(t, []published{{"a", "1"}, {"b", "2"}, {"a", "3"}}, publisher.published)
+		assert.Len(t, repo.delivered, 3)
+		assert.Empty(t, repo.lockedUntil)
+	})
+
+	t.Run("stops at the first failure and keeps the rest undelivered", func(t *testing.T) {
+		t.Parallel()
+
+		messages := []Message{
+			newTestMessage(t, "a", 1),
+			newTestMessage(t, "a", 2),
+			newTestMessage(t, "a", 3),
+		}
+		repo := newFakeRepository(messages...)
+		publisher := &fakePublisher{failOn: "2"}
+		relay := NewRelay(repo, publisher, time.Second, 10, time.Minute, time.Hour)
+
+		err := relay.Drain(context.Background())
+
+		require.Error(t, err)
+		assert.Equal(t, []published{{"a", "1"}}, publisher.published)
+		assert.Contains(t, repo.delivered, messages[0].ID)
+		assert.NotContains(t, repo.delivered, messages[1].ID)
+		assert.NotContains(t, repo.delivered, messages[2].ID)
+	})
+
+	t.Run("retries failed messages after the lease expires", func(t *testing.T) {
+		t.Parallel()
+
+		message := newTestMessage(t, "a", 1)
+		repo := newFakeRepository(message)
+		publisher := &fakePublisher{failOn: "1"}
+		relay := NewRelay(repo, publisher, time.Second, 10, time.Minute, time.Hour)
+
+		require.Error(t, relay.Drain(context.Background()))
+
+		publisher.failOn = ""
+		require.NoError(t, relay.Drain(context.Background()))
+		assert.Empty(t, publisher.published, "claimed message must not be published before its lease expires")
+
+		repo.lockedUntil[message.ID] = time.Now().Add(-time.Second)
+		require.NoError(t, relay.Drain(context.Background()))
+		assert.Equal(t, []published{{"a", "1"}}, publisher.published)
+	})
+
+	t.Run("keeps messages rejected by a full buffer for a retry", func(t *testing.T) {
+		t.Parallel()
+
+		message := newTestMessage(t, "a", 1)
+		repo := newFakeRepository(message)
+		publisher := &fakePublisher{failOn: "1", failErr: eventbus.ErrBufferFull}
+		relay := NewRelay(repo, publisher, time.Second, 10, time.Minute, time.Hour)
+
+		require.ErrorIs(t, relay.Drain(context.Background()), eventbus.ErrBufferFull)
+		assert.NotContains(t, repo.delivered, message.ID)
+	})
+
+	t.Run("returns claim errors", func(t *testing.T) {
+		t.Parallel()
+
+		repo := newFakeRepository()
+		repo.claimErr = errors.New("db is down")
+		relay := NewRelay(repo, &fakePublisher{}, time.Second, 10, time.Minute, time.Hour)
+
+		require.ErrorIs(t, relay.Drain(context.Background()), repo.claimErr)
+	})
+}
+
+func TestRelay_Cleanup(t *testing.T) {
+	t.Parallel()
+
+	old := newTestMessage(t, "a", 1)
+	recent := newTestMessage(t, "a", 2)
+	pending := newTestMessage(t, "a", 3)
+	repo := newFakeRepository(old, recent, pending)
+	repo.delivered[old.ID] = time.Now().Add(-2 * time.Hour)
+	repo.delivered[recent.ID] = time.Now()
+	relay := NewRelay(repo, &fakePublisher{}, time.Second, 10, time.Minute, time.Hour)
+
+	require.NoError(t, relay.Cleanup(context.Background()))
+
+	assert.Equal(t, []Message{recent, pending}, repo.messages)
+}
+
+func TestSortMessages(t *testing.T) {
+	t.Parallel()
+
+	now := time.Now()
+	first := Message{ID: utils.NewUniqueID(), CreatedAt: now}
+	second := Message{ID: utils.NewUniqueID(), CreatedAt: now}
+	third := Message{ID: utils.NewUniqueID(), CreatedAt: now.Add(time.Second)}
+	messages := []Message{third, second, first}
+
+	SortMessages(messages)
+
+	assert.Equal(t, []Message{first, second, third}, messages)
+}
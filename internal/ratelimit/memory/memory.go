@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+	"whitelist-bot/internal/ratelimit"
+)
+
+const sweepInterval = time.Minute
+
+type bucketKey struct {
+	class      ratelimit.Class
+	telegramID int64
+}
+
+type entry struct {
+	bucket ratelimit.Bucket
+	limit  ratelimit.Limit
+}
+
+// Store keeps token buckets in memory. Limits are per replica.
+type Store struct {
+	mu        sync.Mutex
+	buckets   map[bucketKey]entry
+	lastSweep time.Time
+	now       func() time.Time
+}
+
+func New() *Store {
+	return &Store{
+		buckets: make(map[bucketKey]entry),
+		now:     time.Now,
+	}
+}
+
+func (s *Store) Take(_ context.Context, class ratelimit.Class, telegramID int64, limit ratelimit.Limit) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	key := bucketKey{class: class, telegramID: telegramID}
+	bucket, allowed := s.buckets[key].bucket.Take(limit, now)
+	s.buckets[key] = entry{bucket: bucket, limit: limit}
+	return allowed, nil
+}
+
+// sweep forgets buckets that are full again, so idle users don't hold memory.
+func (s *Store) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, e := range s.buckets {
+		if now.Sub(e.bucket.UpdatedAt) >= e.limit.RefillTime() {
+			delete(s.buckets, key)
+		}
+	}
+}
+
+// Len returns the number of tracked buckets.
+func (s *Store) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.buckets)
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"testing"
+	"time"
+	"whitelist-bot/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Take(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	s := New()
+	s.now = func() time.Time { return now }
+	limit := ratelimit.Limit{Burst: 2, Interval: time.Second}
+
+	for range 2 {
+		allowed, err := s.Take(ctx, ratelimit.ClassMessage, 1, limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := s.Take(ctx, ratelimit.ClassMessage, 1, limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	// Other users and classes have their own buckets.
+	allowed, err = s.Take(ctx, ratelimit.ClassMessage, 2, limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+	allowed, err = s.Take(ctx, ratelimit.ClassCallback, 1, limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	now = now.Add(time.Second)
+	allowed, err = s.Take(ctx, ratelimit.ClassMessage, 1, limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+}
+
+func TestStore_SweepsRefilledBuckets(t *testing.T) {
+	ctx := context.Background()
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+	s := New()
+	s.now = func() time.Time { return now }
+	short := ratelimit.Limit{Burst: 1, Interval: time.Second}
+	long := ratelimit.Limit{Burst: 1, Interval: time.Hour}
+
+	_, err := s.Take(ctx, ratelimit.ClassMessage, 1, short)
+	require.NoError(t, err)
+	_, err = s.Take(ctx, ratelimit.ClassWLRequestSubmit, 1, long)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Len())
+
+	now = now.Add(sweepInterval)
+	_, err = s.Take(ctx, ratelimit.ClassCallback, 2, short)
+	require.NoError(t, err)
+	assert.Equal(t, 2, s.Len())
+}
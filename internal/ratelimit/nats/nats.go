@@ -0,0 +1,53 @@
+package nats
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+	"whitelist-bot/internal/metastore"
+	"whitelist-bot/internal/ratelimit"
+)
+
+const keyPrefix = "ratelimit_"
+
+type iStore interface {
+	Get(ctx context.Context, uniqueID string, key string) ([]byte, error)
+	SetWithTTL(ctx context.Context, uniqueID string, key string, value any, ttl time.Duration) error
+}
+
+// Store keeps token buckets in the NATS KV metastore bucket, so limits are shared by replicas.
+// Concurrent takes of the same bucket are not serialized, so the limit is best effort under a race.
+type Store struct {
+	store iStore
+}
+
+func New(store iStore) *Store {
+	return &Store{store: store}
+}
+
+func (s *Store) Take(ctx context.Context, class ratelimit.Class, telegramID int64, limit ratelimit.Limit) (bool, error) {
+	uniqueID := strconv.FormatInt(telegramID, 10)
+	key := keyPrefix + string(class)
+
+	var bucket ratelimit.Bucket
+	data, err := s.store.Get(ctx, uniqueID, key)
+	switch {
+	case errors.Is(err, metastore.ErrKeyNotFound):
+	case err != nil:
+		return false, fmt.Errorf("failed to get bucket: %w", err)
+	default:
+		if err := json.Unmarshal(data, &bucket); err != nil {
+			return false, fmt.Errorf("failed to json unmarshal bucket: %w", err)
+		}
+	}
+
+	bucket, allowed := bucket.Take(limit, time.Now())
+	// A full bucket is the same as no bucket, so every take renews the TTL of the key to the refill time.
+	if err := s.store.SetWithTTL(ctx, uniqueID, key, bucket, limit.RefillTime()); err != nil {
+		return false, fmt.Errorf("failed to set bucket: %w", err)
+	}
+	return allowed, nil
+}
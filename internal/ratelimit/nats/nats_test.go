@@ -0,0 +1,52 @@
+package nats
+
+import (
+	"context"
+	"testing"
+	"time"
+	memoryMetastore "whitelist-bot/internal/metastore/memory"
+	"whitelist-bot/internal/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStore_Take(t *testing.T) {
+	ctx := context.Background()
+	store := memoryMetastore.New("test")
+	s := New(store)
+	limit := ratelimit.Limit{Burst: 2, Interval: time.Hour}
+
+	for range 2 {
+		allowed, err := s.Take(ctx, ratelimit.ClassMessage, 1, limit)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+	}
+	allowed, err := s.Take(ctx, ratelimit.ClassMessage, 1, limit)
+	require.NoError(t, err)
+	assert.False(t, allowed)
+
+	allowed, err = s.Take(ctx, ratelimit.ClassMessage, 2, limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	exists, err := store.Exists(ctx, "1", keyPrefix+string(ratelimit.ClassMessage))
+	require.NoError(t, err)
+	assert.True(t, exists)
+}
+
+func TestStore_Take_ExpiredBucketIsFull(t *testing.T) {
+	ctx := context.Background()
+	store := memoryMetastore.New("test")
+	s := New(store)
+	limit := ratelimit.Limit{Burst: 1, Interval: 20 * time.Millisecond}
+
+	allowed, err := s.Take(ctx, ratelimit.ClassCallback, 1, limit)
+	require.NoError(t, err)
+	assert.True(t, allowed)
+
+	assert.Eventually(t, func() bool {
+		allowed, err := s.Take(ctx, ratelimit.ClassCallback, 1, limit)
+		return err == nil && allowed
+	}, time.Second, 10*time.Millisecond)
+}
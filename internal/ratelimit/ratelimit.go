@@ -0,0 +1,95 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// Class groups routes that share a limit.
+type Class string
+
+const (
+	ClassMessage         Class = "message"
+	ClassCallback        Class = "callback"
+	ClassWLRequestSubmit Class = "wl_request_submit"
+	// ClassWarning limits the "slow down" replies themselves, so a flood doesn't turn into a reply flood.
+	ClassWarning Class = "warning"
+)
+
+// Limit is a token bucket that holds up to Burst tokens and gets one back every Interval.
+type Limit struct {
+	Burst    int
+	Interval time.Duration
+}
+
+// Limits maps route classes to their limits. Classes without a limit are not limited.
+type Limits map[Class]Limit
+
+// Bucket is the state of a token bucket. The zero bucket is full.
+type Bucket struct {
+	Tokens    float64   `json:"tokens"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Take refills the bucket for the time passed since the last update and takes a token if there is one.
+func (b Bucket) Take(limit Limit, now time.Time) (Bucket, bool) {
+	b = b.refill(limit, now)
+	if b.Tokens < 1 {
+		return b, false
+	}
+	b.Tokens--
+	return b, true
+}
+
+// Wait returns how long to wait until Take succeeds.
+func (b Bucket) Wait(limit Limit, now time.Time) time.Duration {
+	b = b.refill(limit, now)
+	if b.Tokens >= 1 {
+		return 0
+	}
+	return time.Duration((1 - b.Tokens) * float64(limit.Interval))
+}
+
+func (b Bucket) refill(limit Limit, now time.Time) Bucket {
+	burst := float64(limit.Burst)
+	if b.UpdatedAt.IsZero() {
+		b.Tokens = burst
+	} else if elapsed := now.Sub(b.UpdatedAt); elapsed > 0 {
+		b.Tokens = min(burst, b.Tokens+float64(elapsed)/float64(limit.Interval))
+	}
+	b.UpdatedAt = now
+	return b
+}
+
+// RefillTime is how long an empty bucket takes to get full again. After that its state can be forgotten.
+func (l Limit) RefillTime() time.Duration {
+	return time.Duration(l.Burst) * l.Interval
+}
+
+type IStore interface {
+	// Take takes a token from the bucket of the user in the class and reports whether there was one.
+	Take(ctx context.Context, class Class, telegramID int64, limit Limit) (bool, error)
+}
+
+type Limiter struct {
+	store  IStore
+	limits Limits
+}
+
+func NewLimiter(store IStore, limits Limits) *Limiter {
+	return &Limiter{store: store, limits: limits}
+}
+
+// Allow reports whether the user may go on with an update of the class.
+func (l *Limiter) Allow(ctx context.Context, class Class, telegramID int64) (bool, error) {
+	limit, ok := l.limits[class]
+	if !ok {
+		return true, nil
+	}
+	allowed, err := l.store.Take(ctx, class, telegramID, limit)
+	if err != nil {
+		return false, fmt.Errorf("failed to take %s token: %w", class, err)
+	}
+	return allowed, nil
+}
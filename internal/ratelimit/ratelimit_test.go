@@ -0,0 +1,130 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBucket_Take(t *testing.T) {
+	limit := Limit{Burst: 2, Interval: time.Second}
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name        string
+		bucket      Bucket
+		now         time.Time
+		wantAllowed bool
+		wantTokens  float64
+	}{
+		{
+			name:        "zero bucket is full",
+			bucket:      Bucket{},
+			now:         now,
+			wantAllowed: true,
+			wantTokens:  1,
+		},
+		{
+			name:        "empty bucket",
+			bucket:      Bucket{Tokens: 0.5, UpdatedAt: now},
+			now:         now,
+			wantAllowed: false,
+			wantTokens:  0.5,
+		},
+		{
+			name:        "refilled by interval",
+			bucket:      Bucket{Tokens: 0, UpdatedAt: now},
+			now:         now.Add(time.Second),
+			wantAllowed: true,
+			wantTokens:  0,
+		},
+		{
+			name:        "refill is capped by burst",
+			bucket:      Bucket{Tokens: 0, UpdatedAt: now},
+			now:         now.Add(time.Hour),
+			wantAllowed: true,
+			wantTokens:  1,
+		},
+		{
+			name:        "clock going back doesn't drain",
+			bucket:      Bucket{Tokens: 1, UpdatedAt: now},
+			now:         now.Add(-time.Second),
+			wantAllowed: true,
+			wantTokens:  0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bucket, allowed := tt.bucket.Take(limit, tt.now)
+			assert.Equal(t, tt.wantAllowed, allowed)
+			assert.InDelta(t, tt.wantTokens, bucket.Tokens, 1e-9)
+			assert.Equal(t, tt.now, bucket.UpdatedAt)
+		})
+	}
+}
+
+type fakeStore struct {
+	allowed bool
+	err     error
+	classes []Class
+}
+
+func (s *fakeStore) Take(_ context.Context, class Class, _ int64, _ Limit) (bool, error) {
+	s.classes = append(s.classes, class)
+	return s.allowed, s.err
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	ctx := context.Background()
+	limits := Limits{ClassMessage: {Burst: 1, Interval: time.Second}}
+
+	t.Run("class without limit", func(t *testing.T) {
+		store := &fakeStore{}
+		allowed, err := NewLimiter(store, limits).Allow(ctx, ClassCallback, 1)
+		require.NoError(t, err)
+		assert.True(t, allowed)
+		assert.Empty(t, store.classes)
+	})
+
+	t.Run("limited class", func(t *testing.T) {
+		store := &fakeStore{allowed: false}
+		allowed, err := NewLimiter(store, limits).Allow(ctx, ClassMessage, 1)
+		require.NoError(t, err)
+		assert.False(t, allowed)
+		assert.Equal(t, []Class{ClassMessage}, store.classes)
+	})
+
+	t.Run("store error", func(t *testing.T) {
+		store := &fakeStore{err: errors.New("boom")}
+		_, err := NewLimiter(store, limits).Allow(ctx, ClassMessage, 1)
+		require.Error(t, err)
+	})
+}
+
+func TestBucket_Wait(t *testing.T) {
+	limit := Limit{Burst: 1, Interval: time.Second}
+	now := time.Date(2025, 12, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name   string
+		bucket Bucket
+		now    time.Time
+		want   time.Duration
+	}{
+		{name: "zero bucket", bucket: Bucket{}, now: now, want: 0},
+		{name: "empty bucket", bucket: Bucket{Tokens: 0, UpdatedAt: now}, now: now, want: time.Second},
+		{name: "partly refilled", bucket: Bucket{Tokens: 0, UpdatedAt: now}, now: now.Add(750 * time.Millisecond), want: 250 * time.Millisecond},
+		{name: "refilled", bucket: Bucket{Tokens: 0, UpdatedAt: now}, now: now.Add(2 * time.Second), want: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.bucket.Wait(limit, tt.now))
+		})
+	}
+}
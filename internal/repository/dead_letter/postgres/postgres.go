@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/eventbus"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type iQueryable interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+}
+
+type DeadLetterRepository struct {
+	db iQueryable
+}
+
+func NewDeadLetterRepository(db iQueryable) *DeadLetterRepository {
+	return &DeadLetterRepository{db: db}
+}
+
+func (r *DeadLetterRepository) CreateDeadLetter(ctx context.Context, deadLetter eventbus.DeadLetter) error {
+	q := New(r.db)
+
+	err := q.CreateDeadLetter(ctx, CreateDeadLetterParams{
+		ID:       uuid.UUID(deadLetter.ID),
+		Topic:    deadLetter.Topic,
+		Data:     deadLetter.Data,
+		Error:    deadLetter.Error,
+		Attempts: int32(deadLetter.Attempts),
+		FailedAt: deadLetter.FailedAt,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create dead letter: %w", err)
+	}
+	return nil
+}
+
+func (r *DeadLetterRepository) DeadLetters(ctx context.Context, limit int64) ([]eventbus.DeadLetter, error) {
+	q := New(r.db)
+
+	dbDeadLetters, err := q.DeadLetters(ctx, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get dead letters: %w", err)
+	}
+
+	deadLetters := make([]eventbus.DeadLetter, 0, len(dbDeadLetters))
+	for _, dbDeadLetter := range dbDeadLetters {
+		deadLetters = append(deadLetters, toDeadLetter(dbDeadLetter))
+	}
+	return deadLetters, nil
+}
+
+func (r *DeadLetterRepository) DeadLetterByID(ctx context.Context, id utils.UniqueID) (eventbus.DeadLetter, error) {
+	q := New(r.db)
+
+	dbDeadLetter, err := q.DeadLetterByID(ctx, uuid.UUID(id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return eventbus.DeadLetter{}, core.ErrDeadLetterNotFound
+		}
+		return eventbus.DeadLetter{}, fmt.Errorf("failed to get dead letter by id: %w", err)
+	}
+	return toDeadLetter(dbDeadLetter), nil
+}
+
+func (r *DeadLetterRepository) DeleteDeadLetter(ctx context.Context, id utils.UniqueID) error {
+	q := New(r.db)
+
+	deleted, err := q.DeleteDeadLetter(ctx, uuid.UUID(id))
+	if err != nil {
+		return fmt.Errorf("failed to delete dead letter: %w", err)
+	}
+	if deleted == 0 {
+		return core.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+func (r *DeadLetterRepository) DeleteAllDeadLetters(ctx context.Context) (int64, error) {
+	q := New(r.db)
+
+	deleted, err := q.DeleteAllDeadLetters(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete all dead letters: %w", err)
+	}
+	return deleted, nil
+}
+
+func toDeadLetter(dbDeadLetter DeadLetter) eventbus.DeadLetter {
+	return eventbus.DeadLetter{
+		ID:       utils.UniqueID(dbDeadLetter.ID),
+		Topic:    dbDeadLetter.Topic,
+		Data:     dbDeadLetter.Data,
+		Error:    dbDeadLetter.Error,
+		Attempts: int(dbDeadLetter.Attempts),
+		FailedAt: dbDeadLetter.FailedAt,
+	}
+}
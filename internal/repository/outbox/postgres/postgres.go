@@ -0,0 +1,83 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/outbox"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+type iQueryable interface {
+	Exec(ctx context.Context, sql string, arguments ...any) (pgconn.CommandTag, error)
+	Query(ctx context.Context, sql string, optionsAndArgs ...any) (pgx.Rows, error)
+	QueryRow(ctx context.Context, sql string, optionsAndArgs ...any) pgx.Row
+}
+
+type OutboxRepository struct {
+	db iQueryable
+}
+
+func NewOutboxRepository(db iQueryable) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+func (r *OutboxRepository) ClaimOutboxMessages(
+	ctx context.Context,
+	limit int64,
+	now, lockedUntil time.Time,
+) ([]outbox.Message, error) {
+	q := New(r.db)
+
+	dbMessages, err := q.ClaimOutboxMessages(ctx, ClaimOutboxMessagesParams{
+		LockedUntil: lockedUntil,
+		Now:         now,
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	messages := make([]outbox.Message, 0, len(dbMessages))
+	for _, dbMessage := range dbMessages {
+		messages = append(messages, outbox.Message{
+			ID:        utils.UniqueID(dbMessage.ID),
+			Topic:     dbMessage.Topic,
+			Data:      dbMessage.Data,
+			CreatedAt: dbMessage.CreatedAt,
+		})
+	}
+	outbox.SortMessages(messages)
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkOutboxMessageDelivered(
+	ctx context.Context,
+	id utils.UniqueID,
+	deliveredAt time.Time,
+) error {
+	q := New(r.db)
+
+	err := q.MarkOutboxMessageDelivered(ctx, MarkOutboxMessageDeliveredParams{
+		DeliveredAt: deliveredAt,
+		ID:          uuid.UUID(id),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message as delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) DeleteDeliveredOutboxMessages(ctx context.Context, before time.Time) (int64, error) {
+	q := New(r.db)
+
+	deleted, err := q.DeleteDeliveredOutboxMessages(ctx, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered outbox messages: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,96 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/outbox"
+)
+
+const SQLITE_TIME_FORMAT = "2006-01-02T15:04:05-0700"
+
+type iQueryable interface {
+	ExecContext(context.Context, string, ...any) (sql.Result, error)
+	PrepareContext(context.Context, string) (*sql.Stmt, error)
+	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
+	QueryRowContext(context.Context, string, ...any) *sql.Row
+}
+
+type OutboxRepository struct {
+	db iQueryable
+}
+
+func NewOutboxRepository(db iQueryable) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// Times are stored in UTC, so the text columns compare in chronological order.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(SQLITE_TIME_FORMAT)
+}
+
+func (r *OutboxRepository) ClaimOutboxMessages(
+	ctx context.Context,
+	limit int64,
+	now, lockedUntil time.Time,
+) ([]outbox.Message, error) {
+	q := New(r.db)
+
+	dbMessages, err := q.ClaimOutboxMessages(ctx, ClaimOutboxMessagesParams{
+		LockedUntil: formatTime(lockedUntil),
+		Now:         formatTime(now),
+		Limit:       limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim outbox messages: %w", err)
+	}
+
+	messages := make([]outbox.Message, 0, len(dbMessages))
+	for _, dbMessage := range dbMessages {
+		id, err := utils.UUIDFromString[utils.UniqueID](dbMessage.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse outbox message id: %w", err)
+		}
+		createdAt, err := time.Parse(SQLITE_TIME_FORMAT, dbMessage.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse createdAt: %w", err)
+		}
+		messages = append(messages, outbox.Message{
+			ID:        id,
+			Topic:     dbMessage.Topic,
+			Data:      dbMessage.Data,
+			CreatedAt: createdAt,
+		})
+	}
+	outbox.SortMessages(messages)
+	return messages, nil
+}
+
+func (r *OutboxRepository) MarkOutboxMessageDelivered(
+	ctx context.Context,
+	id utils.UniqueID,
+	deliveredAt time.Time,
+) error {
+	q := New(r.db)
+
+	err := q.MarkOutboxMessageDelivered(ctx, MarkOutboxMessageDeliveredParams{
+		DeliveredAt: formatTime(deliveredAt),
+		ID:          id.String(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to mark outbox message as delivered: %w", err)
+	}
+	return nil
+}
+
+func (r *OutboxRepository) DeleteDeliveredOutboxMessages(ctx context.Context, before time.Time) (int64, error) {
+	q := New(r.db)
+
+	deleted, err := q.DeleteDeliveredOutboxMessages(ctx, formatTime(before))
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete delivered outbox messages: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,156 @@
+package cache
+
+import (
+	"context"
+	"sync"
+	"time"
+	domainUser "whitelist-bot/internal/domain/user"
+)
+
+const sweepInterval = time.Minute
+
+type iUserRepository interface {
+	UserByTelegramID(ctx context.Context, telegramID int64) (domainUser.User, error)
+	UserByID(ctx context.Context, id domainUser.ID) (domainUser.User, error)
+	CreateUser(
+		ctx context.Context,
+		telegramId domainUser.TelegramID,
+		chatID domainUser.ChatID,
+		firstName domainUser.FirstName,
+		lastName domainUser.LastName,
+		username domainUser.Username,
+		languageCode domainUser.LanguageCode,
+	) (domainUser.User, error)
+	UpdateUser(ctx context.Context, user domainUser.User) (domainUser.User, error)
+	SyncUserProfile(ctx context.Context, previous, synced domainUser.User) (domainUser.User, error)
+	PreviousUsernames(ctx context.Context, user domainUser.User, limit int64) ([]domainUser.Username, error)
+}
+
+type entry struct {
+	user      domainUser.User
+	expiresAt time.Time
+}
+
+// UserRepository caches users by Telegram ID in memory. Writes through it keep the cache fresh,
+// writes of other replicas are seen once the entry expires. A zero TTL disables the cache.
+type UserRepository struct {
+	next iUserRepository
+	ttl  time.Duration
+	now  func() time.Time
+
+	mu        sync.Mutex
+	users     map[int64]entry
+	lastSweep time.Time
+}
+
+func NewUserRepository(next iUserRepository, ttl time.Duration) *UserRepository {
+	return &UserRepository{
+		next:  next,
+		ttl:   ttl,
+		now:   time.Now,
+		users: make(map[int64]entry),
+	}
+}
+
+func (r *UserRepository) UserByTelegramID(ctx context.Context, telegramID int64) (domainUser.User, error) {
+	if user, ok := r.get(telegramID); ok {
+		return user, nil
+	}
+	user, err := r.next.UserByTelegramID(ctx, telegramID)
+	if err != nil {
+		return domainUser.User{}, err
+	}
+	r.set(user)
+	return user, nil
+}
+
+func (r *UserRepository) UserByID(ctx context.Context, id domainUser.ID) (domainUser.User, error) {
+	return r.next.UserByID(ctx, id)
+}
+
+func (r *UserRepository) CreateUser(
+	ctx context.Context,
+	telegramId domainUser.TelegramID,
+	chatID domainUser.ChatID,
+	firstName domainUser.FirstName,
+	lastName domainUser.LastName,
+	username domainUser.Username,
+	languageCode domainUser.LanguageCode,
+) (domainUser.User, error) {
+	user, err := r.next.CreateUser(ctx, telegramId, chatID, firstName, lastName, username, languageCode)
+	if err != nil {
+		return domainUser.User{}, err
+	}
+	r.set(user)
+	return user, nil
+}
+
+func (r *UserRepository) UpdateUser(ctx context.Context, user domainUser.User) (domainUser.User, error) {
+	// Dropped before the write, so a failed write doesn't leave a stale entry behind either.
+	r.delete(int64(user.TelegramID()))
+	updated, err := r.next.UpdateUser(ctx, user)
+	if err != nil {
+		return domainUser.User{}, err
+	}
+	r.set(updated)
+	return updated, nil
+}
+
+func (r *UserRepository) SyncUserProfile(ctx context.Context, previous, synced domainUser.User) (domainUser.User, error) {
+	r.delete(int64(synced.TelegramID()))
+	updated, err := r.next.SyncUserProfile(ctx, previous, synced)
+	if err != nil {
+		return domainUser.User{}, err
+	}
+	r.set(updated)
+	return updated, nil
+}
+
+func (r *UserRepository) PreviousUsernames(ctx context.Context, user domainUser.User, limit int64) ([]domainUser.Username, error) {
+	return r.next.PreviousUsernames(ctx, user, limit)
+}
+
+func (r *UserRepository) get(telegramID int64) (domainUser.User, bool) {
+	if r.ttl <= 0 {
+		return domainUser.User{}, false
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	e, ok := r.users[telegramID]
+	if !ok || !r.now().Before(e.expiresAt) {
+		return domainUser.User{}, false
+	}
+	return e.user, true
+}
+
+func (r *UserRepository) set(user domainUser.User) {
+	if r.ttl <= 0 {
+		return
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := r.now()
+	r.sweep(now)
+	r.users[int64(user.TelegramID())] = entry{user: user, expiresAt: now.Add(r.ttl)}
+}
+
+func (r *UserRepository) delete(telegramID int64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.users, telegramID)
+}
+
+func (r *UserRepository) sweep(now time.Time) {
+	if now.Sub(r.lastSweep) < sweepInterval {
+		return
+	}
+	r.lastSweep = now
+	for telegramID, e := range r.users {
+		if !now.Before(e.expiresAt) {
+			delete(r.users, telegramID)
+		}
+	}
+}
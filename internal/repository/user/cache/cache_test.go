@@ -0,0 +1,122 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core"
+	domainUser "whitelist-bot/internal/domain/user"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRepository struct {
+	users map[int64]domainUser.User
+	reads int
+}
+
+func (f *fakeRepository) UserByTelegramID(_ context.Context, telegramID int64) (domainUser.User, error) {
+	f.reads++
+	user, ok := f.users[telegramID]
+	if !ok {
+		return domainUser.User{}, core.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeRepository) UserByID(context.Context, domainUser.ID) (domainUser.User, error) {
+	return domainUser.User{}, core.ErrUserNotFound
+}
+
+func (f *fakeRepository) CreateUser(
+	_ context.Context,
+	telegramID domainUser.TelegramID,
+	chatID domainUser.ChatID,
+	_ domainUser.FirstName,
+	_ domainUser.LastName,
+	username domainUser.Username,
+	_ domainUser.LanguageCode,
+) (domainUser.User, error) {
+	user, err := domainUser.NewBuilder().
+		IDFromUUID(uuid.New()).
+		TelegramID(telegramID).
+		ChatID(chatID).
+		Username(username).
+		CreatedAt(time.Now()).
+		UpdatedAt(time.Now()).
+		Build()
+	if err != nil {
+		return domainUser.User{}, err
+	}
+	f.users[int64(telegramID)] = user
+	return user, nil
+}
+
+func (f *fakeRepository) UpdateUser(_ context.Context, user domainUser.User) (domainUser.User, error) {
+	f.users[int64(user.TelegramID())] = user
+	return user, nil
+}
+
+func (f *fakeRepository) SyncUserProfile(_ context.Context, _, synced domainUser.User) (domainUser.User, error) {
+	f.users[int64(synced.TelegramID())] = synced
+	return synced, nil
+}
+
+func (f *fakeRepository) PreviousUsernames(context.Context, domainUser.User, int64) ([]domainUser.Username, error) {
+	return nil, nil
+}
+
+func TestUserRepository_UserByTelegramID(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	next := &fakeRepository{users: map[int64]domainUser.User{}}
+	r := NewUserRepository(next, time.Minute)
+	r.now = func() time.Time { return now }
+
+	_, err := r.UserByTelegramID(ctx, 1)
+	require.ErrorIs(t, err, core.ErrUserNotFound)
+
+	created, err := r.CreateUser(ctx, 1, 1, "", "", "first", "")
+	require.NoError(t, err)
+
+	user, err := r.UserByTelegramID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, created, user)
+	assert.Equal(t, 1, next.reads)
+
+	_, err = r.UpdateUser(ctx, user.ChangeLanguageCode("en"))
+	require.NoError(t, err)
+	user, err = r.UserByTelegramID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domainUser.LanguageCode("en"), user.LanguageCode())
+	assert.Equal(t, 1, next.reads)
+
+	synced, _ := user.SyncProfile("", "", "second")
+	_, err = r.SyncUserProfile(ctx, user, synced)
+	require.NoError(t, err)
+	user, err = r.UserByTelegramID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, domainUser.Username("second"), user.Username())
+	assert.Equal(t, 1, next.reads)
+
+	now = now.Add(time.Minute)
+	_, err = r.UserByTelegramID(ctx, 1)
+	require.NoError(t, err)
+	assert.Equal(t, 2, next.reads)
+}
+
+func TestUserRepository_Disabled(t *testing.T) {
+	ctx := context.Background()
+	next := &fakeRepository{users: map[int64]domainUser.User{}}
+	r := NewUserRepository(next, 0)
+
+	_, err := r.CreateUser(ctx, 1, 1, "", "", "first", "")
+	require.NoError(t, err)
+	for range 2 {
+		_, err := r.UserByTelegramID(ctx, 1)
+		require.NoError(t, err)
+	}
+	assert.Equal(t, 2, next.reads)
+}
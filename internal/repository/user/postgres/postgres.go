@@ -10,6 +10,7 @@ import (
 
 	domainUser "whitelist-bot/internal/domain/user"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 )
@@ -47,6 +48,7 @@ func (r *UserRepository) UserByTelegramID(ctx context.Context, telegramID int64)
 		FirstName(dbUser.FirstName).
 		LastName(dbUser.LastName).
 		Username(dbUser.Username).
+		LanguageCode(dbUser.LanguageCode).
 		CreatedAt(dbUser.CreatedAt).
 		UpdatedAt(dbUser.UpdatedAt).
 		Build()
@@ -64,6 +66,7 @@ func (r *UserRepository) CreateUser(
 	firstName domainUser.FirstName,
 	lastName domainUser.LastName,
 	username domainUser.Username,
+	languageCode domainUser.LanguageCode,
 ) (domainUser.User, error) {
 	q := New(r.db)
 
@@ -76,6 +79,7 @@ func (r *UserRepository) CreateUser(
 		FirstName(firstName).
 		LastName(lastName).
 		Username(username).
+		LanguageCode(languageCode).
 		CreatedAt(now).
 		UpdatedAt(now).
 		Build()
@@ -84,14 +88,15 @@ func (r *UserRepository) CreateUser(
 	}
 
 	_, err = q.CreateUser(ctx, CreateUserParams{
-		ID:         newUser.ID(),
-		TelegramID: newUser.TelegramID(),
-		ChatID:     newUser.ChatID(),
-		FirstName:  newUser.FirstName(),
-		LastName:   newUser.LastName(),
-		Username:   newUser.Username(),
-		CreatedAt:  now,
-		UpdatedAt:  now,
+		ID:           newUser.ID(),
+		TelegramID:   newUser.TelegramID(),
+		ChatID:       newUser.ChatID(),
+		FirstName:    newUser.FirstName(),
+		LastName:     newUser.LastName(),
+		Username:     newUser.Username(),
+		LanguageCode: newUser.LanguageCode(),
+		CreatedAt:    now,
+		UpdatedAt:    now,
 	})
 	if err != nil {
 		return domainUser.User{}, fmt.Errorf("failed to create user: %w", err)
@@ -105,13 +110,14 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user domainUser.User) (
 	user = user.UpdateTimestamp()
 
 	_, err := q.UpdateUser(ctx, UpdateUserParams{
-		ID:         user.ID(),
-		TelegramID: user.TelegramID(),
-		ChatID:     user.ChatID(),
-		FirstName:  user.FirstName(),
-		LastName:   user.LastName(),
-		Username:   user.Username(),
-		UpdatedAt:  user.UpdatedAt(),
+		ID:           user.ID(),
+		TelegramID:   user.TelegramID(),
+		ChatID:       user.ChatID(),
+		FirstName:    user.FirstName(),
+		LastName:     user.LastName(),
+		Username:     user.Username(),
+		LanguageCode: user.LanguageCode(),
+		UpdatedAt:    user.UpdatedAt(),
 	})
 	if err != nil {
 		return domainUser.User{}, fmt.Errorf("failed to update user: %w", err)
@@ -135,6 +141,7 @@ func (r *UserRepository) UserByID(ctx context.Context, id domainUser.ID) (domain
 		FirstName(dbUser.FirstName).
 		LastName(dbUser.LastName).
 		Username(dbUser.Username).
+		LanguageCode(dbUser.LanguageCode).
 		CreatedAt(dbUser.CreatedAt).
 		UpdatedAt(dbUser.UpdatedAt).
 		Build()
@@ -143,3 +150,62 @@ func (r *UserRepository) UserByID(ctx context.Context, id domainUser.ID) (domain
 	}
 	return user, nil
 }
+
+// SyncUserProfile saves the profile synced from Telegram. A replaced username is kept in the history.
+func (r *UserRepository) SyncUserProfile(ctx context.Context, previous, synced domainUser.User) (domainUser.User, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domainUser.User{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := New(tx)
+
+	synced = synced.UpdateTimestamp()
+
+	_, err = q.UpdateUser(ctx, UpdateUserParams{
+		ID:           synced.ID(),
+		TelegramID:   synced.TelegramID(),
+		ChatID:       synced.ChatID(),
+		FirstName:    synced.FirstName(),
+		LastName:     synced.LastName(),
+		Username:     synced.Username(),
+		LanguageCode: synced.LanguageCode(),
+		UpdatedAt:    synced.UpdatedAt(),
+	})
+	if err != nil {
+		return domainUser.User{}, fmt.Errorf("failed to update user: %w", err)
+	}
+
+	if !previous.Username().IsZero() && previous.Username() != synced.Username() {
+		err = q.AddUsernameHistory(ctx, AddUsernameHistoryParams{
+			ID:         uuid.New(),
+			UserID:     synced.ID(),
+			Username:   previous.Username(),
+			ReplacedAt: synced.UpdatedAt(),
+		})
+		if err != nil {
+			return domainUser.User{}, fmt.Errorf("failed to add username history: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domainUser.User{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return synced, nil
+}
+
+// PreviousUsernames returns the usernames the user had before the current one, the most recent first.
+func (r *UserRepository) PreviousUsernames(ctx context.Context, user domainUser.User, limit int64) ([]domainUser.Username, error) {
+	q := New(r.db)
+
+	usernames, err := q.PreviousUsernames(ctx, PreviousUsernamesParams{
+		UserID:   user.ID(),
+		Username: user.Username(),
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get previous usernames: %w", err)
+	}
+	return usernames, nil
+}
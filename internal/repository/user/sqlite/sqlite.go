@@ -54,6 +54,7 @@ func (r *UserRepository) UserByTelegramID(ctx context.Context, telegramID int64)
 		FirstName(dbUser.FirstName).
 		LastName(dbUser.LastName).
 		Username(dbUser.Username).
+		LanguageCode(dbUser.LanguageCode).
 		CreatedAt(createdAt).
 		UpdatedAt(updatedAt).
 		Build()
@@ -70,6 +71,7 @@ func (r *UserRepository) CreateUser(
 	firstName domainUser.FirstName,
 	lastName domainUser.LastName,
 	username domainUser.Username,
+	languageCode domainUser.LanguageCode,
 ) (domainUser.User, error) {
 	q := New(r.db)
 
@@ -81,6 +83,7 @@ func (r *UserRepository) CreateUser(
 		FirstName(firstName).
 		LastName(lastName).
 		Username(username).
+		LanguageCode(languageCode).
 		CreatedAt(now).
 		UpdatedAt(now).
 		Build()
@@ -89,13 +92,14 @@ func (r *UserRepository) CreateUser(
 	}
 
 	_, err = q.CreateUser(ctx, CreateUserParams{
-		ID:         newUser.ID().String(),
-		TelegramID: newUser.TelegramID(),
-		FirstName:  newUser.FirstName(),
-		LastName:   newUser.LastName(),
-		Username:   newUser.Username(),
-		CreatedAt:  now.Format(SQLITE_TIME_FORMAT),
-		UpdatedAt:  now.Format(SQLITE_TIME_FORMAT),
+		ID:           newUser.ID().String(),
+		TelegramID:   newUser.TelegramID(),
+		FirstName:    newUser.FirstName(),
+		LastName:     newUser.LastName(),
+		Username:     newUser.Username(),
+		LanguageCode: newUser.LanguageCode(),
+		CreatedAt:    now.Format(SQLITE_TIME_FORMAT),
+		UpdatedAt:    now.Format(SQLITE_TIME_FORMAT),
 	})
 	if err != nil {
 		return domainUser.User{}, fmt.Errorf("failed to create user: %w", err)
@@ -109,12 +113,13 @@ func (r *UserRepository) UpdateUser(ctx context.Context, user domainUser.User) (
 	user = user.UpdateTimestamp()
 
 	_, err := q.UpdateUser(ctx, UpdateUserParams{
-		ID:         user.ID().String(),
-		TelegramID: user.TelegramID(),
-		FirstName:  user.FirstName(),
-		LastName:   user.LastName(),
-		Username:   user.Username(),
-		UpdatedAt:  user.UpdatedAt().Format(SQLITE_TIME_FORMAT),
+		ID:           user.ID().String(),
+		TelegramID:   user.TelegramID(),
+		FirstName:    user.FirstName(),
+		LastName:     user.LastName(),
+		Username:     user.Username(),
+		LanguageCode: user.LanguageCode(),
+		UpdatedAt:    user.UpdatedAt().Format(SQLITE_TIME_FORMAT),
 	})
 	if err != nil {
 		return domainUser.User{}, fmt.Errorf("failed to update user: %w", err)
@@ -145,6 +150,7 @@ func (r *UserRepository) UserByID(ctx context.Context, id domainUser.ID) (domain
 		FirstName(dbUser.FirstName).
 		LastName(dbUser.LastName).
 		Username(dbUser.Username).
+		LanguageCode(dbUser.LanguageCode).
 		CreatedAt(createdAt).
 		UpdatedAt(updatedAt).
 		Build()
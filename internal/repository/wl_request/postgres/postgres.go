@@ -7,9 +7,11 @@ import (
 	"fmt"
 	"time"
 
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgconn"
 
+	"whitelist-bot/internal/core"
 	domainUser "whitelist-bot/internal/domain/user"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
 	repository "whitelist-bot/internal/repository/wl_request"
@@ -34,8 +36,15 @@ func (r *WLRequestRepository) CreateWLRequest(
 	ctx context.Context,
 	requesterID domainWLRequest.RequesterID,
 	nickname domainWLRequest.Nickname,
+	events ...repository.WLRequestEvent,
 ) (domainWLRequest.WLRequest, error) {
-	q := New(r.db)
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := New(tx)
 
 	now := time.Now()
 
@@ -64,6 +73,26 @@ func (r *WLRequestRepository) CreateWLRequest(
 	if err != nil {
 		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to create wl request: %w", err)
 	}
+
+	for _, event := range events {
+		message, err := event(newWLRequest)
+		if err != nil {
+			return domainWLRequest.WLRequest{}, fmt.Errorf("failed to build outbox message: %w", err)
+		}
+		err = q.CreateOutboxMessage(ctx, CreateOutboxMessageParams{
+			ID:        uuid.UUID(message.ID),
+			Topic:     message.Topic,
+			Data:      message.Data,
+			CreatedAt: message.CreatedAt,
+		})
+		if err != nil {
+			return domainWLRequest.WLRequest{}, fmt.Errorf("failed to create outbox message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return newWLRequest, nil
 }
 
@@ -130,6 +159,7 @@ func (r *WLRequestRepository) PendingWLRequestsWithRequester(
 			ChatID(dbRow.User.ChatID).
 			LastName(dbRow.User.LastName).
 			Username(dbRow.User.Username).
+			LanguageCode(dbRow.User.LanguageCode).
 			CreatedAt(dbRow.User.CreatedAt).
 			UpdatedAt(dbRow.User.UpdatedAt).
 			Build()
@@ -152,6 +182,9 @@ func (r *WLRequestRepository) WLRequestByID(
 
 	dbWLRequest, err := q.WLRequestByID(ctx, id)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return domainWLRequest.WLRequest{}, core.ErrWLRequestNotFound
+		}
 		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to get wl request by id: %w", err)
 	}
 
@@ -176,9 +209,53 @@ func (r *WLRequestRepository) WLRequestByID(
 	return wlRequest, nil
 }
 
+// WLRequestsByNickname returns up to limit requests in the status for the nickname, ignoring case, newest first.
+func (r *WLRequestRepository) WLRequestsByNickname(
+	ctx context.Context,
+	nickname domainWLRequest.Nickname,
+	status domainWLRequest.Status,
+	limit int64,
+) ([]domainWLRequest.WLRequest, error) {
+	q := New(r.db)
+
+	dbWLRequests, err := q.WLRequestsByNickname(ctx, WLRequestsByNicknameParams{
+		Nickname: string(nickname),
+		Status:   status,
+		Limit:    limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get wl requests by nickname: %w", err)
+	}
+
+	wlRequests := make([]domainWLRequest.WLRequest, len(dbWLRequests))
+	for i, dbWLRequest := range dbWLRequests {
+		builder := domainWLRequest.NewBuilder().
+			ID(dbWLRequest.ID).
+			Status(dbWLRequest.Status).
+			DeclineReason(dbWLRequest.DeclineReason).
+			RequesterID(dbWLRequest.RequesterID).
+			Nickname(dbWLRequest.Nickname).
+			CreatedAt(dbWLRequest.CreatedAt).
+			UpdatedAt(dbWLRequest.UpdatedAt)
+
+		if !dbWLRequest.ArbiterID.IsZero() {
+			builder = builder.ArbiterID(dbWLRequest.ArbiterID)
+		}
+
+		wlRequests[i], err = builder.Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build wl request: %s: %w", dbWLRequest.ID, err)
+		}
+	}
+	return wlRequests, nil
+}
+
+// UpdateWLRequest saves the request if it is still in the from status. It fails with
+// core.ErrWLRequestStatusChanged if another decision or the expiration got there first.
 func (r *WLRequestRepository) UpdateWLRequest(
 	ctx context.Context,
 	wlRequest domainWLRequest.WLRequest,
+	from domainWLRequest.Status,
 ) (domainWLRequest.WLRequest, error) {
 	q := New(r.db)
 
@@ -192,10 +269,139 @@ func (r *WLRequestRepository) UpdateWLRequest(
 		DeclineReason: wlRequest.DeclineReason(),
 		ArbiterID:     wlRequest.ArbiterID(),
 		UpdatedAt:     wlRequest.UpdatedAt(),
+		FromStatus:    from,
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return domainWLRequest.WLRequest{}, fmt.Errorf("%w: %s is no longer %s", core.ErrWLRequestStatusChanged, wlRequest.ID(), from)
+	}
 	if err != nil {
 		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to update wl request: %w", err)
 	}
 
 	return wlRequest, nil
 }
+
+// ExpireStaleWLRequests expires up to limit requests pending since before createdBefore, oldest first,
+// and stores their events in the same transaction. Rows claimed by another replica are skipped.
+func (r *WLRequestRepository) ExpireStaleWLRequests(
+	ctx context.Context,
+	createdBefore time.Time,
+	limit int64,
+	events ...repository.WLRequestEvent,
+) ([]domainWLRequest.WLRequest, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback(ctx) }()
+
+	q := New(tx)
+
+	dbWLRequests, err := q.StalePendingWLRequests(ctx, StalePendingWLRequestsParams{
+		CreatedBefore: createdBefore,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale pending wl requests: %w", err)
+	}
+
+	expiredWLRequests := make([]domainWLRequest.WLRequest, 0, len(dbWLRequests))
+	for _, dbWLRequest := range dbWLRequests {
+		wlRequest, err := domainWLRequest.NewBuilder().
+			ID(dbWLRequest.ID).
+			Status(dbWLRequest.Status).
+			RequesterID(dbWLRequest.RequesterID).
+			Nickname(dbWLRequest.Nickname).
+			CreatedAt(dbWLRequest.CreatedAt).
+			UpdatedAt(dbWLRequest.UpdatedAt).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build wl request: %s: %w", dbWLRequest.ID, err)
+		}
+
+		expiredWLRequest, err := wlRequest.Expire()
+		if err != nil {
+			return nil, err
+		}
+		expiredWLRequest = expiredWLRequest.UpdateTimestamp()
+
+		_, err = q.UpdateWLRequest(ctx, UpdateWLRequestParams{
+			ID:            expiredWLRequest.ID(),
+			RequesterID:   expiredWLRequest.RequesterID(),
+			Nickname:      expiredWLRequest.Nickname(),
+			Status:        expiredWLRequest.Status(),
+			DeclineReason: expiredWLRequest.DeclineReason(),
+			ArbiterID:     expiredWLRequest.ArbiterID(),
+			UpdatedAt:     expiredWLRequest.UpdatedAt(),
+			FromStatus:    domainWLRequest.StatusPending,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update wl request: %s: %w", expiredWLRequest.ID(), err)
+		}
+
+		for _, event := range events {
+			message, err := event(expiredWLRequest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build outbox message: %w", err)
+			}
+			err = q.CreateOutboxMessage(ctx, CreateOutboxMessageParams{
+				ID:        uuid.UUID(message.ID),
+				Topic:     message.Topic,
+				Data:      message.Data,
+				CreatedAt: message.CreatedAt,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create outbox message: %w", err)
+			}
+		}
+		expiredWLRequests = append(expiredWLRequests, expiredWLRequest)
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return expiredWLRequests, nil
+}
+
+func (r *WLRequestRepository) CountPendingWLRequests(ctx context.Context) (int64, error) {
+	q := New(r.db)
+
+	count, err := q.CountPendingWLRequests(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending wl requests: %w", err)
+	}
+	return count, nil
+}
+
+// PendingWLRequestsCreatedBefore returns up to limit requests pending since before createdBefore, oldest first.
+func (r *WLRequestRepository) PendingWLRequestsCreatedBefore(
+	ctx context.Context,
+	createdBefore time.Time,
+	limit int64,
+) ([]domainWLRequest.WLRequest, error) {
+	q := New(r.db)
+
+	dbWLRequests, err := q.PendingWLRequestsCreatedBefore(ctx, PendingWLRequestsCreatedBeforeParams{
+		CreatedBefore: createdBefore,
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending wl requests: %w", err)
+	}
+
+	pendingWLRequests := make([]domainWLRequest.WLRequest, len(dbWLRequests))
+	for i, dbWLRequest := range dbWLRequests {
+		pendingWLRequests[i], err = domainWLRequest.NewBuilder().
+			ID(dbWLRequest.ID).
+			Status(dbWLRequest.Status).
+			RequesterID(dbWLRequest.RequesterID).
+			Nickname(dbWLRequest.Nickname).
+			CreatedAt(dbWLRequest.CreatedAt).
+			UpdatedAt(dbWLRequest.UpdatedAt).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build wl request: %s: %w", dbWLRequest.ID, err)
+		}
+	}
+	return pendingWLRequests, nil
+}
@@ -3,9 +3,14 @@ package repository
 import (
 	domainUser "whitelist-bot/internal/domain/user"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	"whitelist-bot/internal/outbox"
 )
 
 type PendingWLRequestWithRequester struct {
 	WlRequest domainWLRequest.WLRequest
 	User      domainUser.User
 }
+
+// WLRequestEvent builds an outbox message for a wl request that is being stored.
+// The message is written in the same transaction as the wl request.
+type WLRequestEvent func(wlRequest domainWLRequest.WLRequest) (outbox.Message, error)
@@ -6,13 +6,15 @@ import (
 	"errors"
 	"fmt"
 	"time"
+	"whitelist-bot/internal/core"
 	domainWLRequest "whitelist-bot/internal/domain/wl_request"
+	repository "whitelist-bot/internal/repository/wl_request"
 )
 
 const SQLITE_TIME_FORMAT = "2006-01-02T15:04:05-0700"
 
 type iQueryable interface {
-	Begin() (*sql.Tx, error)
+	BeginTx(ctx context.Context, opts *sql.TxOptions) (*sql.Tx, error)
 	ExecContext(context.Context, string, ...any) (sql.Result, error)
 	PrepareContext(context.Context, string) (*sql.Stmt, error)
 	QueryContext(context.Context, string, ...any) (*sql.Rows, error)
@@ -27,12 +29,24 @@ func NewWLRequestRepository(db iQueryable) *WLRequestRepository {
 	return &WLRequestRepository{db: db}
 }
 
+// Times are stored in UTC, so the text columns compare in chronological order.
+func formatTime(t time.Time) string {
+	return t.UTC().Format(SQLITE_TIME_FORMAT)
+}
+
 func (r *WLRequestRepository) CreateWLRequest(
 	ctx context.Context,
 	requesterID domainWLRequest.RequesterID,
 	nickname domainWLRequest.Nickname,
+	events ...repository.WLRequestEvent,
 ) (domainWLRequest.WLRequest, error) {
-	q := New(r.db)
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	q := New(tx)
 
 	now := time.Now()
 
@@ -55,12 +69,33 @@ func (r *WLRequestRepository) CreateWLRequest(
 		Nickname:      newWLRequest.Nickname(),
 		Status:        newWLRequest.Status(),
 		DeclineReason: newWLRequest.DeclineReason(),
-		CreatedAt:     newWLRequest.CreatedAt().Format(SQLITE_TIME_FORMAT),
-		UpdatedAt:     newWLRequest.UpdatedAt().Format(SQLITE_TIME_FORMAT),
+		CreatedAt:     formatTime(newWLRequest.CreatedAt()),
+		UpdatedAt:     formatTime(newWLRequest.UpdatedAt()),
 	})
 	if err != nil {
 		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to create wl request: %w", err)
 	}
+
+	for _, event := range events {
+		message, err := event(newWLRequest)
+		if err != nil {
+			return domainWLRequest.WLRequest{}, fmt.Errorf("failed to build outbox message: %w", err)
+		}
+		err = q.CreateOutboxMessage(ctx, CreateOutboxMessageParams{
+			ID:    message.ID.String(),
+			Topic: message.Topic,
+			Data:  message.Data,
+			// UTC keeps the outbox ordered by created_at, see the outbox repository.
+			CreatedAt: formatTime(message.CreatedAt),
+		})
+		if err != nil {
+			return domainWLRequest.WLRequest{}, fmt.Errorf("failed to create outbox message: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to commit transaction: %w", err)
+	}
 	return newWLRequest, nil
 }
 
@@ -146,9 +181,12 @@ func (r *WLRequestRepository) WLRequestByID(
 	return wlRequest, nil
 }
 
+// UpdateWLRequest saves the request if it is still in the from status. It fails with
+// core.ErrWLRequestStatusChanged if another decision or the expiration got there first.
 func (r *WLRequestRepository) UpdateWLRequest(
 	ctx context.Context,
 	wlRequest domainWLRequest.WLRequest,
+	from domainWLRequest.Status,
 ) (domainWLRequest.WLRequest, error) {
 	q := New(r.db)
 
@@ -161,11 +199,159 @@ func (r *WLRequestRepository) UpdateWLRequest(
 		Status:        wlRequest.Status(),
 		DeclineReason: wlRequest.DeclineReason(),
 		ArbiterID:     wlRequest.ArbiterID().String(),
-		UpdatedAt:     wlRequest.UpdatedAt().Format(SQLITE_TIME_FORMAT),
+		UpdatedAt:     formatTime(wlRequest.UpdatedAt()),
+		FromStatus:    from,
 	})
+	if errors.Is(err, sql.ErrNoRows) {
+		return domainWLRequest.WLRequest{}, fmt.Errorf("%w: %s is no longer %s", core.ErrWLRequestStatusChanged, wlRequest.ID(), from)
+	}
 	if err != nil {
 		return domainWLRequest.WLRequest{}, fmt.Errorf("failed to update wl request: %w", err)
 	}
 
 	return wlRequest, nil
 }
+
+// ExpireStaleWLRequests expires up to limit requests pending since before createdBefore, oldest first,
+// and stores their events in the same transaction.
+func (r *WLRequestRepository) ExpireStaleWLRequests(
+	ctx context.Context,
+	createdBefore time.Time,
+	limit int64,
+	events ...repository.WLRequestEvent,
+) ([]domainWLRequest.WLRequest, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() { _ = tx.Rollback() }()
+
+	q := New(tx)
+
+	dbWLRequests, err := q.PendingWLRequestsCreatedBefore(ctx, PendingWLRequestsCreatedBeforeParams{
+		CreatedBefore: formatTime(createdBefore),
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get stale wl requests: %w", err)
+	}
+
+	expiredWLRequests := make([]domainWLRequest.WLRequest, 0, len(dbWLRequests))
+	for _, dbWLRequest := range dbWLRequests {
+		createdAt, err := time.Parse(SQLITE_TIME_FORMAT, dbWLRequest.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse createdAt: %w", err)
+		}
+		updatedAt, err := time.Parse(SQLITE_TIME_FORMAT, dbWLRequest.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse updatedAt: %w", err)
+		}
+
+		wlRequest, err := domainWLRequest.NewBuilder().
+			IDFromString(dbWLRequest.ID).
+			Status(dbWLRequest.Status).
+			RequesterIDFromString(dbWLRequest.RequesterID).
+			Nickname(dbWLRequest.Nickname).
+			CreatedAt(createdAt).
+			UpdatedAt(updatedAt).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build wl request: %s: %w", dbWLRequest.ID, err)
+		}
+
+		expiredWLRequest, err := wlRequest.Expire()
+		if err != nil {
+			return nil, err
+		}
+		expiredWLRequest = expiredWLRequest.UpdateTimestamp()
+
+		_, err = q.UpdateWLRequest(ctx, UpdateWLRequestParams{
+			ID:            expiredWLRequest.ID().String(),
+			RequesterID:   expiredWLRequest.RequesterID().String(),
+			Nickname:      expiredWLRequest.Nickname(),
+			Status:        expiredWLRequest.Status(),
+			DeclineReason: expiredWLRequest.DeclineReason(),
+			ArbiterID:     expiredWLRequest.ArbiterID().String(),
+			UpdatedAt:     formatTime(expiredWLRequest.UpdatedAt()),
+			FromStatus:    domainWLRequest.StatusPending,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to update wl request: %s: %w", expiredWLRequest.ID(), err)
+		}
+
+		for _, event := range events {
+			message, err := event(expiredWLRequest)
+			if err != nil {
+				return nil, fmt.Errorf("failed to build outbox message: %w", err)
+			}
+			err = q.CreateOutboxMessage(ctx, CreateOutboxMessageParams{
+				ID:        message.ID.String(),
+				Topic:     message.Topic,
+				Data:      message.Data,
+				CreatedAt: formatTime(message.CreatedAt),
+			})
+			if err != nil {
+				return nil, fmt.Errorf("failed to create outbox message: %w", err)
+			}
+		}
+		expiredWLRequests = append(expiredWLRequests, expiredWLRequest)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return expiredWLRequests, nil
+}
+
+func (r *WLRequestRepository) CountPendingWLRequests(ctx context.Context) (int64, error) {
+	q := New(r.db)
+
+	count, err := q.CountPendingWLRequests(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count pending wl requests: %w", err)
+	}
+	return count, nil
+}
+
+// PendingWLRequestsCreatedBefore returns up to limit requests pending since before createdBefore, oldest first.
+func (r *WLRequestRepository) PendingWLRequestsCreatedBefore(
+	ctx context.Context,
+	createdBefore time.Time,
+	limit int64,
+) ([]domainWLRequest.WLRequest, error) {
+	q := New(r.db)
+
+	dbWLRequests, err := q.PendingWLRequestsCreatedBefore(ctx, PendingWLRequestsCreatedBeforeParams{
+		CreatedBefore: formatTime(createdBefore),
+		Limit:         limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending wl requests: %w", err)
+	}
+
+	pendingWLRequests := make([]domainWLRequest.WLRequest, 0, len(dbWLRequests))
+	for _, dbWLRequest := range dbWLRequests {
+		createdAt, err := time.Parse(SQLITE_TIME_FORMAT, dbWLRequest.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse createdAt: %w", err)
+		}
+		updatedAt, err := time.Parse(SQLITE_TIME_FORMAT, dbWLRequest.UpdatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse updatedAt: %w", err)
+		}
+
+		wlRequest, err := domainWLRequest.NewBuilder().
+			IDFromString(dbWLRequest.ID).
+			Status(dbWLRequest.Status).
+			RequesterIDFromString(dbWLRequest.RequesterID).
+			Nickname(dbWLRequest.Nickname).
+			CreatedAt(createdAt).
+			UpdatedAt(updatedAt).
+			Build()
+		if err != nil {
+			return nil, fmt.Errorf("failed to build wl request: %s: %w", dbWLRequest.ID, err)
+		}
+		pendingWLRequests = append(pendingWLRequests, wlRequest)
+	}
+	return pendingWLRequests, nil
+}
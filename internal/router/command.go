@@ -0,0 +1,97 @@
+package router
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+
+	"github.com/go-telegram/bot/models"
+)
+
+// Command is a bot command a message starts with: /name@bot arg1 "quoted arg".
+type Command struct {
+	// Name is lowercased and has no slash.
+	Name string
+	// Mention is the bot username the command is addressed to in groups, empty if none.
+	Mention string
+	// Args are the arguments split by spaces, quotes keep an argument with spaces together.
+	Args []string
+	// RawArgs is the trimmed text after the command, for arguments like free-form reasons.
+	RawArgs string
+}
+
+// ParseCommand parses the command the message starts with. It relies on the bot_command entity
+// Telegram marks commands with, so "/startfoo" is the command "startfoo" and a slash in plain text is no command.
+func ParseCommand(msg *models.Message) (Command, bool) {
+	if msg == nil {
+		return Command{}, false
+	}
+	for _, entity := range msg.Entities {
+		if entity.Type != models.MessageEntityTypeBotCommand || entity.Offset != 0 {
+			continue
+		}
+		// Entity offsets and lengths are in UTF-16 code units.
+		text := utf16.Encode([]rune(msg.Text))
+		if entity.Length < 2 || entity.Length > len(text) {
+			return Command{}, false
+		}
+		name := string(utf16.Decode(text[1:entity.Length]))
+		rest := string(utf16.Decode(text[entity.Length:]))
+
+		cmd := Command{RawArgs: strings.TrimSpace(rest)}
+		name, cmd.Mention, _ = strings.Cut(name, "@")
+		cmd.Name = strings.ToLower(name)
+		cmd.Args = SplitArgs(cmd.RawArgs)
+		return cmd, true
+	}
+	return Command{}, false
+}
+
+// AddressedTo reports whether the command is for the bot: it has no mention or mentions the bot.
+func (c Command) AddressedTo(botUsername string) bool {
+	return c.Mention == "" || strings.EqualFold(c.Mention, botUsername)
+}
+
+// SplitArgs splits arguments by whitespace. Double quotes, including the curly ones mobile keyboards put,
+// group words into one argument, a backslash escapes the next character. An unclosed quote runs to the end.
+func SplitArgs(s string) []string {
+	var args []string
+	for {
+		arg, rest, found := CutArg(s)
+		if !found {
+			return args
+		}
+		args = append(args, arg)
+		s = rest
+	}
+}
+
+// CutArg splits off the first argument like SplitArgs and returns the trimmed text after it as is,
+// so a free-form tail like a reason keeps its quotes, backslashes and spacing. found is false if s has no arguments.
+func CutArg(s string) (arg, rest string, found bool) {
+	var current strings.Builder
+	inArg, quoted, escaped := false, false, false
+	for i, r := range s {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			inArg, escaped = true, true
+		case isQuote(r):
+			inArg, quoted = true, !quoted
+		case unicode.IsSpace(r) && !quoted:
+			if inArg {
+				return current.String(), strings.TrimSpace(s[i:]), true
+			}
+		default:
+			inArg = true
+			current.WriteRune(r)
+		}
+	}
+	return current.String(), "", inArg
+}
+
+func isQuote(r rune) bool {
+	return r == '"' || r == '“' || r == '”'
+}
@@ -0,0 +1,158 @@
+package router
+
+import (
+	"testing"
+
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func commandMsg(text string, offset, length int) *models.Message {
+	return &models.Message{
+		Text:     text,
+		Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Offset: offset, Length: length}},
+	}
+}
+
+func TestParseCommand(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name    string
+		msg     *models.Message
+		wantOK  bool
+		wantCmd Command
+	}{
+		{
+			name:    "plain",
+			msg:     commandMsg("/start", 0, 6),
+			wantOK:  true,
+			wantCmd: Command{Name: "start"},
+		},
+		{
+			name:    "longer command is another command",
+			msg:     commandMsg("/startfoo", 0, 9),
+			wantOK:  true,
+			wantCmd: Command{Name: "startfoo"},
+		},
+		{
+			name:    "mention and case",
+			msg:     commandMsg("/Start@OurBot", 0, 13),
+			wantOK:  true,
+			wantCmd: Command{Name: "start", Mention: "OurBot"},
+		},
+		{
+			name:   "arguments",
+			msg:    commandMsg("/decline  steve  \"no  reason\" ", 0, 8),
+			wantOK: true,
+			wantCmd: Command{
+				Name:    "decline",
+				Args:    []string{"steve", "no  reason"},
+				RawArgs: "steve  \"no  reason\"",
+			},
+		},
+		{
+			name:   "curly quotes and escapes",
+			msg:    commandMsg("/decline “a b” c\\ d \\\"e", 0, 8),
+			wantOK: true,
+			wantCmd: Command{
+				Name:    "decline",
+				Args:    []string{"a b", "c d", "\"e"},
+				RawArgs: "“a b” c\\ d \\\"e",
+			},
+		},
+		{
+			name:   "utf-16 offsets",
+			msg:    commandMsg("/say 🎉 hi", 0, 4),
+			wantOK: true,
+			wantCmd: Command{
+				Name:    "say",
+				Args:    []string{"🎉", "hi"},
+				RawArgs: "🎉 hi",
+			},
+		},
+		{
+			name:   "command not at the start",
+			msg:    commandMsg("hi /start", 3, 6),
+			wantOK: false,
+		},
+		{
+			name:   "no entity",
+			msg:    &models.Message{Text: "/start"},
+			wantOK: false,
+		},
+		{
+			name:   "no message",
+			wantOK: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			cmd, ok := ParseCommand(tt.msg)
+			assert.Equal(t, tt.wantOK, ok)
+			assert.Equal(t, tt.wantCmd, cmd)
+		})
+	}
+}
+
+func TestCommand_AddressedTo(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, Command{Name: "start"}.AddressedTo("OurBot"))
+	assert.True(t, Command{Name: "start", Mention: "ourbot"}.AddressedTo("OurBot"))
+	assert.False(t, Command{Name: "start", Mention: "OtherBot"}.AddressedTo("OurBot"))
+}
+
+func TestSplitArgs(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{in: "", want: nil},
+		{in: "a b", want: []string{"a", "b"}},
+		{in: `"" b`, want: []string{"", "b"}},
+		{in: `"unclosed quote`, want: []string{"unclosed quote"}},
+		{in: `a\`, want: []string{"a"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			assert.Equal(t, tt.want, SplitArgs(tt.in))
+		})
+	}
+}
+
+func TestCutArg(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		in        string
+		wantArg   string
+		wantRest  string
+		wantFound bool
+	}{
+		{in: "", wantFound: false},
+		{in: "   ", wantFound: false},
+		{in: "a", wantArg: "a", wantFound: true},
+		{in: `a  "b  c" \d`, wantArg: "a", wantRest: `"b  c" \d`, wantFound: true},
+		{in: `"a b" c`, wantArg: "a b", wantRest: "c", wantFound: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			t.Parallel()
+
+			arg, rest, found := CutArg(tt.in)
+			assert.Equal(t, tt.wantArg, arg)
+			assert.Equal(t, tt.wantRest, rest)
+			assert.Equal(t, tt.wantFound, found)
+		})
+	}
+}
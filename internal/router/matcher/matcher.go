@@ -1,16 +1,18 @@
 package matcher
 
 import (
-	"encoding/json"
+	"context"
 	"slices"
 	"strings"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/router"
 
-	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
-func MsgText(text string) bot.MatchFunc {
-	return func(update *models.Update) bool {
+func MsgText(text string) router.MatchFunc {
+	return func(ctx context.Context, update *models.Update) bool {
 		if update.Message == nil {
 			return false
 		}
@@ -18,10 +20,21 @@ func MsgText(text string) bot.MatchFunc {
 	}
 }
 
-func And(matchers ...bot.MatchFunc) bot.MatchFunc {
-	return func(update *models.Update) bool {
+// LocalizedMsgText matches a message whose text equals the translation of key in any supported language.
+func LocalizedMsgText(key i18n.Key) router.MatchFunc {
+	texts := i18n.Translations(key)
+	return func(ctx context.Context, update *models.Update) bool {
+		if update.Message == nil {
+			return false
+		}
+		return slices.Contains(texts, update.Message.Text)
+	}
+}
+
+func And(matchers ...router.MatchFunc) router.MatchFunc {
+	return func(ctx context.Context, update *models.Update) bool {
 		for _, m := range matchers {
-			if !m(update) {
+			if !m(ctx, update) {
 				return false
 			}
 		}
@@ -29,10 +42,10 @@ func And(matchers ...bot.MatchFunc) bot.MatchFunc {
 	}
 }
 
-func Or(matchers ...bot.MatchFunc) bot.MatchFunc {
-	return func(update *models.Update) bool {
+func Or(matchers ...router.MatchFunc) router.MatchFunc {
+	return func(ctx context.Context, update *models.Update) bool {
 		for _, m := range matchers {
-			if m(update) {
+			if m(ctx, update) {
 				return true
 			}
 		}
@@ -40,18 +53,32 @@ func Or(matchers ...bot.MatchFunc) bot.MatchFunc {
 	}
 }
 
-func Command(cmd string) bot.MatchFunc {
-	return func(update *models.Update) bool {
-		if update.Message == nil {
-			return false
-		}
-		text := strings.TrimSpace(update.Message.Text)
-		return strings.HasPrefix(text, "/"+cmd)
+// State matches updates from users in the state. The state is resolved by the router before matching.
+func State(state fsm.State) router.MatchFunc {
+	return func(ctx context.Context, _ *models.Update) bool {
+		uc, ok := router.UpdateFromContext(ctx)
+		return ok && uc.State == state
 	}
 }
 
-func MatchTelegramIDs(ids ...int64) bot.MatchFunc {
-	return func(update *models.Update) bool {
+// TextMessage matches messages with text. State routes that wait for an answer need it,
+// so callbacks from the buttons of earlier messages are not taken for the answer.
+func TextMessage() router.MatchFunc {
+	return func(_ context.Context, update *models.Update) bool {
+		return update.Message != nil && update.Message.Text != ""
+	}
+}
+
+// Role matches updates from users with the role.
+func Role(role router.Role) router.MatchFunc {
+	return func(ctx context.Context, _ *models.Update) bool {
+		uc, ok := router.UpdateFromContext(ctx)
+		return ok && uc.HasRole(role)
+	}
+}
+
+func MatchTelegramIDs(ids ...int64) router.MatchFunc {
+	return func(ctx context.Context, update *models.Update) bool {
 		var userID int64
 		if update.Message != nil && update.Message.From != nil {
 			userID = update.Message.From.ID
@@ -65,33 +92,11 @@ func MatchTelegramIDs(ids ...int64) bot.MatchFunc {
 	}
 }
 
-func CallbackPrefix(prefix string) bot.MatchFunc {
-	return func(update *models.Update) bool {
+func CallbackPrefix(prefix string) router.MatchFunc {
+	return func(ctx context.Context, update *models.Update) bool {
 		if update.CallbackQuery == nil {
 			return false
 		}
 		return strings.HasPrefix(update.CallbackQuery.Data, prefix)
 	}
 }
-
-func CallbackAction(expectedAction string) bot.MatchFunc {
-	return func(update *models.Update) bool {
-		if update.CallbackQuery == nil {
-			return false
-		}
-		var raw map[string]any
-		err := json.Unmarshal([]byte(update.CallbackQuery.Data), &raw)
-		if err != nil {
-			return false
-		}
-		action, exists := raw["action"]
-		if !exists {
-			return false
-		}
-		actionStr, ok := action.(string)
-		if !ok {
-			return false
-		}
-		return expectedAction == actionStr
-	}
-}
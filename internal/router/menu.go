@@ -0,0 +1,187 @@
+package router
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/i18n"
+	"whitelist-bot/internal/metastore"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+const (
+	keyPrefixCommandMenu = "command_menu"
+	// keyCommandMenuChats keeps the chats with a role menu, so the menu is reset when the role is taken away.
+	keyCommandMenuChats = "chats"
+)
+
+// BotCommand is a command route listed in the bot menu. The menus are built from the registered
+// commands, so they always show what the router handles.
+type BotCommand struct {
+	// Name is the command without the slash: lowercase letters, digits and underscores.
+	Name string
+	// Description is the menu text, published in every supported language.
+	Description i18n.Key
+	// Role limits the command to users with the role, they get it in the menu of their private chat.
+	// Commands without a role are for everyone.
+	Role Role
+}
+
+type iCommandsAPI interface {
+	SetMyCommands(ctx context.Context, params *bot.SetMyCommandsParams) (bool, error)
+	DeleteMyCommands(ctx context.Context, params *bot.DeleteMyCommandsParams) (bool, error)
+}
+
+type iCommandMenuStore interface {
+	Get(ctx context.Context, uniqueID string, key string) ([]byte, error)
+	Set(ctx context.Context, uniqueID string, key string, value any) error
+}
+
+// RegisterCommand adds a route for the command and lists it in the menu. The route matches the command
+// from users with its role, match narrows it further, e.g. to a state, and may be nil.
+// Handlers get the parsed command with CommandFromContext.
+// Routes are matched in the order they were added. It must be called before Start.
+func (r *TelegramRouter) RegisterCommand(cmd BotCommand, match MatchFunc, handler HandlerFunc, middlewares ...Middleware) {
+	r.commands = append(r.commands, cmd)
+	r.RegisterHandlerMatchFunc(func(ctx context.Context, update *models.Update) bool {
+		uc, ok := UpdateFromContext(ctx)
+		if !ok || uc.Command == nil || uc.Command.Name != cmd.Name {
+			return false
+		}
+		if cmd.Role != "" && !uc.HasRole(cmd.Role) {
+			return false
+		}
+		return match == nil || match(ctx, update)
+	}, handler, middlewares...)
+}
+
+// PublishCommands sets the bot menus with setMyCommands: the commands without a role in the default scope,
+// and for every user with a role, these and the commands of their roles in their private chat.
+// Each menu is published in every supported language, and without a language for the others.
+// Chats that had a role menu the last time but have no role now are reset to the default menu,
+// so the store must keep the list of these chats without expiry.
+func (r *TelegramRouter) PublishCommands(ctx context.Context, store iCommandMenuStore) error {
+	if err := r.publishMenu(ctx, &models.BotCommandScopeDefault{}, r.menu(nil)); err != nil {
+		return fmt.Errorf("failed to publish default menu: %w", err)
+	}
+	slog.InfoContext(ctx, "Default command menu published")
+
+	var previous []int64
+	data, err := store.Get(ctx, keyPrefixCommandMenu, keyCommandMenuChats)
+	if err != nil && !errors.Is(err, metastore.ErrKeyNotFound) {
+		return fmt.Errorf("failed to get command menu chats: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &previous); err != nil {
+			return fmt.Errorf("failed to unmarshal command menu chats: %w", err)
+		}
+	}
+
+	current := r.roleChats()
+	var chats []int64
+	for _, chatID := range current {
+		ctx := logger.WithLogValue(ctx, logger.ChatIDField, chatID)
+		// A chat can't get its own menu before the user starts the bot, it gets one on the next start.
+		if err := r.publishMenu(ctx, &models.BotCommandScopeChat{ChatID: chatID}, r.menu(r.roles.Of(chatID))); err != nil {
+			slog.WarnContext(ctx, "Failed to publish role command menu", logger.ErrorField, err.Error())
+			continue
+		}
+		chats = append(chats, chatID)
+	}
+	for _, chatID := range previous {
+		if slices.Contains(current, chatID) {
+			continue
+		}
+		ctx := logger.WithLogValue(ctx, logger.ChatIDField, chatID)
+		if err := r.deleteMenu(ctx, &models.BotCommandScopeChat{ChatID: chatID}); err != nil {
+			slog.WarnContext(ctx, "Failed to reset role command menu", logger.ErrorField, err.Error())
+			// Kept to be reset on the next start.
+			chats = append(chats, chatID)
+			continue
+		}
+		slog.InfoContext(ctx, "Role command menu reset")
+	}
+	slog.InfoContext(ctx, "Role command menus published", "chats", len(chats))
+
+	if err := store.Set(ctx, keyPrefixCommandMenu, keyCommandMenuChats, chats); err != nil {
+		return fmt.Errorf("failed to save command menu chats: %w", err)
+	}
+	return nil
+}
+
+// menu returns the commands for everyone followed by the commands of the roles.
+// Only the ones with a description are listed.
+func (r *TelegramRouter) menu(roles []Role) []BotCommand {
+	var commands []BotCommand
+	for _, cmd := range r.commands {
+		if cmd.Description != "" && cmd.Role == "" {
+			commands = append(commands, cmd)
+		}
+	}
+	for _, cmd := range r.commands {
+		if cmd.Description != "" && cmd.Role != "" && slices.Contains(roles, cmd.Role) {
+			commands = append(commands, cmd)
+		}
+	}
+	return commands
+}
+
+// roleChats returns the private chats of the users whose menu differs from the default one.
+func (r *TelegramRouter) roleChats() []int64 {
+	var chats []int64
+	for _, ids := range r.roles {
+		for _, id := range ids {
+			if !slices.Contains(chats, id) && len(r.menu(r.roles.Of(id))) > len(r.menu(nil)) {
+				chats = append(chats, id)
+			}
+		}
+	}
+	slices.Sort(chats)
+	return chats
+}
+
+func (r *TelegramRouter) publishMenu(ctx context.Context, scope models.BotCommandScope, commands []BotCommand) error {
+	for _, code := range menuLanguageCodes() {
+		lang := i18n.ParseLang(code)
+		botCommands := make([]models.BotCommand, 0, len(commands))
+		for _, cmd := range commands {
+			botCommands = append(botCommands, models.BotCommand{Command: cmd.Name, Description: i18n.T(lang, cmd.Description)})
+		}
+		if _, err := r.commandsAPI.SetMyCommands(ctx, &bot.SetMyCommandsParams{
+			Commands:     botCommands,
+			Scope:        scope,
+			LanguageCode: code,
+		}); err != nil {
+			return fmt.Errorf("failed to set %q commands: %w", code, err)
+		}
+	}
+	return nil
+}
+
+func (r *TelegramRouter) deleteMenu(ctx context.Context, scope models.BotCommandScope) error {
+	for _, code := range menuLanguageCodes() {
+		if _, err := r.commandsAPI.DeleteMyCommands(ctx, &bot.DeleteMyCommandsParams{
+			Scope:        scope,
+			LanguageCode: code,
+		}); err != nil {
+			return fmt.Errorf("failed to delete %q commands: %w", code, err)
+		}
+	}
+	return nil
+}
+
+// menuLanguageCodes returns the supported languages and the empty code, which Telegram shows
+// to users of other languages. Those get the menu in the default language, like the rest of the bot.
+func menuLanguageCodes() []string {
+	codes := []string{""}
+	for _, lang := range i18n.Supported() {
+		codes = append(codes, lang.String())
+	}
+	return codes
+}
@@ -0,0 +1,134 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
+	memoryMetastore "whitelist-bot/internal/metastore/memory"
+
+	domainUser "whitelist-bot/internal/domain/user"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeCommandsAPI struct {
+	set       []*bot.SetMyCommandsParams
+	deleted   []*bot.DeleteMyCommandsParams
+	failChats []int64
+}
+
+func (f *fakeCommandsAPI) SetMyCommands(_ context.Context, params *bot.SetMyCommandsParams) (bool, error) {
+	if f.fails(params.Scope) {
+		return false, errors.New("chat not found")
+	}
+	f.set = append(f.set, params)
+	return true, nil
+}
+
+func (f *fakeCommandsAPI) DeleteMyCommands(_ context.Context, params *bot.DeleteMyCommandsParams) (bool, error) {
+	if f.fails(params.Scope) {
+		return false, errors.New("chat not found")
+	}
+	f.deleted = append(f.deleted, params)
+	return true, nil
+}
+
+func (f *fakeCommandsAPI) fails(scope models.BotCommandScope) bool {
+	chat, ok := scope.(*models.BotCommandScopeChat)
+	return ok && slices.Contains(f.failChats, chat.ChatID.(int64))
+}
+
+func commandUpdate(telegramID int64, text string) *models.Update {
+	return &models.Update{
+		Message: &models.Message{
+			From:     &models.User{ID: telegramID, Username: "user"},
+			Chat:     models.Chat{ID: telegramID},
+			Text:     text,
+			Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Length: len(text)}},
+		},
+	}
+}
+
+func TestTelegramRouter_RegisterCommand(t *testing.T) {
+	tests := []struct {
+		name       string
+		telegramID int64
+		text       string
+		wantRoute  string
+	}{
+		{name: "command for everyone", telegramID: 2, text: "/start", wantRoute: "start"},
+		{name: "role command", telegramID: 1, text: "/jobs", wantRoute: "jobs"},
+		{name: "role command without the role", telegramID: 2, text: "/jobs", wantRoute: "default"},
+		{name: "match narrows the command", telegramID: 1, text: "/busy", wantRoute: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepository{users: map[int64]domainUser.User{}}
+			result := &dispatchResult{}
+			r := newTestRouter(t, repo, result)
+			r.RegisterCommand(BotCommand{Name: "start"}, nil, routeHandler("start", result))
+			r.RegisterCommand(BotCommand{Name: "jobs", Role: RoleAdmin}, nil, routeHandler("jobs", result))
+			r.RegisterCommand(BotCommand{Name: "busy"}, matchState(fsm.StateWaitingWLNickname), routeHandler("busy", result))
+
+			r.dispatch(context.Background(), nil, commandUpdate(tt.telegramID, tt.text))
+
+			require.NoError(t, result.err)
+			assert.Equal(t, tt.wantRoute, result.route)
+		})
+	}
+}
+
+func TestTelegramRouter_PublishCommands(t *testing.T) {
+	ctx := context.Background()
+	api := &fakeCommandsAPI{failChats: []int64{4}}
+	store := memoryMetastore.New("test")
+	// Chat 5 had a role menu before the role was taken away.
+	require.NoError(t, store.Set(ctx, keyPrefixCommandMenu, keyCommandMenuChats, []int64{1, 5}))
+
+	r := &TelegramRouter{
+		roles:       Roles{RoleAdmin: {1, 4}, RoleOwner: {3}},
+		commandsAPI: api,
+	}
+	r.RegisterCommand(BotCommand{Name: "jobs", Description: i18n.MenuJobs, Role: RoleAdmin}, nil, nil)
+	r.RegisterCommand(BotCommand{Name: "start", Description: i18n.MenuStart}, nil, nil)
+	r.RegisterCommand(BotCommand{Name: "hidden"}, nil, nil)
+
+	require.NoError(t, r.PublishCommands(ctx, store))
+
+	everyone := map[string][]models.BotCommand{
+		"":   {{Command: "start", Description: "Запустить бота"}},
+		"ru": {{Command: "start", Description: "Запустить бота"}},
+		"en": {{Command: "start", Description: "Start the bot"}},
+	}
+	admin := map[string][]models.BotCommand{
+		"":   {{Command: "start", Description: "Запустить бота"}, {Command: "jobs", Description: "Фоновые задачи"}},
+		"ru": {{Command: "start", Description: "Запустить бота"}, {Command: "jobs", Description: "Фоновые задачи"}},
+		"en": {{Command: "start", Description: "Start the bot"}, {Command: "jobs", Description: "Scheduled jobs"}},
+	}
+	var want []*bot.SetMyCommandsParams
+	for _, code := range []string{"", "ru", "en"} {
+		want = append(want, &bot.SetMyCommandsParams{Commands: everyone[code], Scope: &models.BotCommandScopeDefault{}, LanguageCode: code})
+	}
+	for _, code := range []string{"", "ru", "en"} {
+		want = append(want, &bot.SetMyCommandsParams{Commands: admin[code], Scope: &models.BotCommandScopeChat{ChatID: int64(1)}, LanguageCode: code})
+	}
+	assert.Equal(t, want, api.set)
+
+	var wantDeleted []*bot.DeleteMyCommandsParams
+	for _, code := range []string{"", "ru", "en"} {
+		wantDeleted = append(wantDeleted, &bot.DeleteMyCommandsParams{Scope: &models.BotCommandScopeChat{ChatID: int64(5)}, LanguageCode: code})
+	}
+	assert.Equal(t, wantDeleted, api.deleted)
+
+	// The owner has no commands of their own, and the admin who never started the bot gets the menu next time.
+	chats, err := store.Get(ctx, keyPrefixCommandMenu, keyCommandMenuChats)
+	require.NoError(t, err)
+	assert.JSONEq(t, "[1]", string(chats))
+}
@@ -0,0 +1,102 @@
+package router
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"slices"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/fsm"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+// Middleware wraps a route handler. It runs after the user is resolved and locked, with the current state known.
+// An error returned from the chain goes to the error handler and leaves the state unchanged.
+type Middleware func(next HandlerFunc) HandlerFunc
+
+// Use adds global middlewares, applied to every route including the default one.
+// It must be called before Start.
+func (r *TelegramRouter) Use(middlewares ...Middleware) {
+	r.middlewares = append(r.middlewares, middlewares...)
+}
+
+// chain wraps the handler so that the first middleware runs first: global ones in the order of Use,
+// then the route ones in the order they were passed to RegisterHandlerMatchFunc.
+func (r *TelegramRouter) chain(handler HandlerFunc, routeMiddlewares []Middleware) HandlerFunc {
+	return Chain(Chain(handler, routeMiddlewares...), r.middlewares...)
+}
+
+// Chain wraps the handler so that the first middleware runs first.
+func Chain(handler HandlerFunc, middlewares ...Middleware) HandlerFunc {
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i](handler)
+	}
+	return handler
+}
+
+// Recover turns a panic in the chain into an error, so it is reported by the error handler
+// and the user lock is released as usual. It should be the first global middleware.
+func Recover() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update, currentState fsm.State) (nextState fsm.State, resp Response, err error) {
+			defer func() {
+				if p := recover(); p != nil {
+					slog.ErrorContext(ctx, "Handler panicked", "panic", fmt.Sprint(p), "stack", string(debug.Stack()))
+					nextState, resp, err = currentState, nil, fmt.Errorf("%w: %v", core.ErrHandlerPanicked, p)
+				}
+			}()
+			return next(ctx, b, update, currentState)
+		}
+	}
+}
+
+// Logging logs every handled update with its duration and outcome.
+func Logging() Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update, currentState fsm.State) (fsm.State, Response, error) {
+			start := time.Now()
+			nextState, resp, err := next(ctx, b, update, currentState)
+			attrs := []any{logger.DurationField, time.Since(start), logger.NextStateField, nextState}
+			if err != nil {
+				slog.WarnContext(ctx, "Update handling failed", append(attrs, logger.ErrorField, err.Error())...)
+			} else {
+				slog.InfoContext(ctx, "Update handled", attrs...)
+			}
+			return nextState, resp, err
+		}
+	}
+}
+
+// RequireRole lets only users with the role through, others get core.ErrPermissionDenied.
+func RequireRole(role Role) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update, currentState fsm.State) (fsm.State, Response, error) {
+			uc, ok := UpdateFromContext(ctx)
+			if !ok || !uc.HasRole(role) {
+				return currentState, nil, core.ErrPermissionDenied
+			}
+			return next(ctx, b, update, currentState)
+		}
+	}
+}
+
+// Maintenance rejects updates with core.ErrMaintenance while enabled. The listed users are still let through.
+func Maintenance(enabled bool, allowedIDs ...int64) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		if !enabled {
+			return next
+		}
+		return func(ctx context.Context, b *bot.Bot, update *models.Update, currentState fsm.State) (fsm.State, Response, error) {
+			telegramID, ok := updateSenderID(update)
+			if !ok || !slices.Contains(allowedIDs, telegramID) {
+				return currentState, nil, core.ErrMaintenance
+			}
+			return next(ctx, b, update, currentState)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/fsm"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func messageUpdate(telegramID int64) *models.Update {
+	return &models.Update{Message: &models.Message{From: &models.User{ID: telegramID}}}
+}
+
+func okHandler(ctx context.Context, _ *bot.Bot, _ *models.Update, _ fsm.State) (fsm.State, Response, error) {
+	return fsm.StateWaitingWLNickname, nil, nil
+}
+
+func recordingMiddleware(name string, calls *[]string) Middleware {
+	return func(next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, b *bot.Bot, update *models.Update, state fsm.State) (fsm.State, Response, error) {
+			*calls = append(*calls, name)
+			return next(ctx, b, update, state)
+		}
+	}
+}
+
+func TestTelegramRouter_chain_Order(t *testing.T) {
+	var calls []string
+	r := &TelegramRouter{}
+	r.Use(recordingMiddleware("global1", &calls), recordingMiddleware("global2", &calls))
+
+	handler := r.chain(func(ctx context.Context, b *bot.Bot, update *models.Update, state fsm.State) (fsm.State, Response, error) {
+		calls = append(calls, "handler")
+		return state, nil, nil
+	}, []Middleware{recordingMiddleware("route1", &calls), recordingMiddleware("route2", &calls)})
+
+	_, _, err := handler(context.Background(), nil, messageUpdate(1), fsm.StateIdle)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"global1", "global2", "route1", "route2", "handler"}, calls)
+}
+
+func TestRecover(t *testing.T) {
+	handler := Recover()(func(ctx context.Context, _ *bot.Bot, _ *models.Update, _ fsm.State) (fsm.State, Response, error) {
+		panic("boom")
+	})
+
+	state, resp, err := handler(context.Background(), nil, messageUpdate(1), fsm.StateIdle)
+	require.ErrorIs(t, err, core.ErrHandlerPanicked)
+	assert.Contains(t, err.Error(), "boom")
+	assert.Equal(t, fsm.StateIdle, state)
+	assert.Nil(t, resp)
+}
+
+func TestMaintenance(t *testing.T) {
+	tests := []struct {
+		name       string
+		enabled    bool
+		telegramID int64
+		wantErr    error
+	}{
+		{name: "disabled", enabled: false, telegramID: 2},
+		{name: "enabled for user", enabled: true, telegramID: 2, wantErr: core.ErrMaintenance},
+		{name: "enabled for allowed user", enabled: true, telegramID: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, _, err := Maintenance(tt.enabled, 1)(okHandler)(context.Background(), nil, messageUpdate(tt.telegramID), fsm.StateIdle)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
@@ -2,12 +2,15 @@ package router
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"log/slog"
 	"slices"
 	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/core/logger"
 	"whitelist-bot/internal/core/utils"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
@@ -19,6 +22,8 @@ type Response interface {
 
 type MessageResponse struct {
 	Params []*bot.SendMessageParams
+	// Lang overrides the language from the context, e.g. right after the user changed it.
+	Lang i18n.Lang
 }
 
 func (r *MessageResponse) AddMessage(p *bot.SendMessageParams) {
@@ -40,6 +45,11 @@ func (r *MessageResponse) Answer(ctx context.Context, sender utils.IMessageSende
 	if update.Message == nil {
 		return core.ErrInvalidUpdate
 	}
+	lang := i18n.LangFromContext(ctx)
+	if r.Lang != "" {
+		lang = r.Lang
+	}
+	var errs []error
 	for _, p := range r.Params {
 		if p == nil {
 			continue
@@ -53,14 +63,14 @@ func (r *MessageResponse) Answer(ctx context.Context, sender utils.IMessageSende
 		if currentState == fsm.StateIdle {
 			buttons := [][]models.KeyboardButton{
 				{
-					{Text: core.CommandInfo},
-					{Text: core.CommandNewWLRequest},
+					{Text: i18n.T(lang, core.CommandInfo)},
+					{Text: i18n.T(lang, core.CommandNewWLRequest)},
 					// {Text: core.CommandAnketaStart},
 					// {Text: core.CommandAnketaInfo},
 				},
 			}
 			if slices.Contains(cfg.Telegram.AdminIDs, update.Message.From.ID) {
-				buttons[0] = append(buttons[0], models.KeyboardButton{Text: "Посмотреть заявки"})
+				buttons[0] = append(buttons[0], models.KeyboardButton{Text: i18n.T(lang, core.CommandViewPendingWLRequests)})
 			}
 			if p.ReplyMarkup == nil {
 				slog.DebugContext(ctx, "Success handler called with new markup")
@@ -85,13 +95,12 @@ func (r *MessageResponse) Answer(ctx context.Context, sender utils.IMessageSende
 				Selective:      true,
 			}
 		}
-		_, err := sender.SendMessage(ctx, p)
-		if err != nil {
-			slog.ErrorContext(ctx, "Failed to send message", logger.ErrorField, err.Error())
-			continue
+		// The rest of the messages are still sent, failures are reported together.
+		if _, err := sender.SendMessage(ctx, p); err != nil {
+			errs = append(errs, fmt.Errorf("failed to send message: %w", err))
 		}
 	}
-	return nil
+	return errors.Join(errs...)
 }
 
 type CallbackResponse struct {
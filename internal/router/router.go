@@ -5,21 +5,36 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"time"
 	"whitelist-bot/internal/core"
 	"whitelist-bot/internal/core/logger"
 	"whitelist-bot/internal/core/utils"
 	domainUser "whitelist-bot/internal/domain/user"
 	"whitelist-bot/internal/fsm"
+	"whitelist-bot/internal/i18n"
 	"whitelist-bot/internal/locker"
+	"whitelist-bot/internal/ratelimit"
 
 	"github.com/go-telegram/bot"
 	"github.com/go-telegram/bot/models"
 )
 
+// HandlerFunc handles an update and returns the next user state.
+// The state payload is available through fsm.LoadData, fsm.StoreData and fsm.ClearData on ctx.
 type HandlerFunc func(ctx context.Context, b *bot.Bot, update *models.Update, currentState fsm.State) (fsm.State, Response, error)
-type ErrorHandlerFunc func(ctx context.Context, b *bot.Bot, update *models.Update, err error)
+type ErrorHandlerFunc func(ctx context.Context, sender utils.IMessageSender, update *models.Update, err error)
 
-type SuccessHandlerFunc func(ctx context.Context, b *bot.Bot, update *models.Update, state fsm.State, response Response)
+type SuccessHandlerFunc func(ctx context.Context, sender utils.IMessageSender, update *models.Update, state fsm.State, response Response)
+
+// MatchFunc reports whether a route handles the update. It runs after the user is resolved,
+// so the user, the state and the roles are available through UpdateFromContext without storage queries.
+type MatchFunc func(ctx context.Context, update *models.Update) bool
+
+type route struct {
+	match       MatchFunc
+	handler     HandlerFunc
+	middlewares []Middleware
+}
 
 type iUserRepository interface {
 	UserByTelegramID(ctx context.Context, telegramID int64) (domainUser.User, error)
@@ -30,21 +45,47 @@ type iUserRepository interface {
 		firstName domainUser.FirstName,
 		lastName domainUser.LastName,
 		username domainUser.Username,
+		languageCode domainUser.LanguageCode,
 	) (domainUser.User, error)
+	SyncUserProfile(ctx context.Context, previous, synced domainUser.User) (domainUser.User, error)
+}
+
+type iRateLimiter interface {
+	Allow(ctx context.Context, class ratelimit.Class, telegramID int64) (bool, error)
 }
 
+// rateLimitTimeout bounds a rate limit check, so a slow store doesn't hold updates back.
+const rateLimitTimeout = time.Second
+
 type TelegramRouter struct {
 	fsm            fsm.IFSM
 	userRepository iUserRepository
 	locker         locker.ILocker
-	errorHandler   ErrorHandlerFunc
-	successHandler SuccessHandlerFunc
-	bot            *bot.Bot
+	lockTimeout    time.Duration
+	// profileSyncInterval is the minimum time between two saves of a user's profile changes.
+	profileSyncInterval time.Duration
+	errorHandler        ErrorHandlerFunc
+	successHandler      SuccessHandlerFunc
+	rateLimiter         iRateLimiter
+	roles               Roles
+	routes              []route
+	commands            []BotCommand
+	defaultHandler      HandlerFunc
+	middlewares         []Middleware
+	sender              utils.IMessageSender
+	bot                 *bot.Bot
+	commandsAPI         iCommandsAPI
+	// botUsername is resolved on Start, it tells the commands for this bot in groups.
+	botUsername string
 }
 
 func NewTelegramRouter(
 	fsm fsm.IFSM,
 	locker locker.ILocker,
+	lockTimeout time.Duration,
+	profileSyncInterval time.Duration,
+	rateLimiter iRateLimiter,
+	roles Roles,
 	repository iUserRepository,
 	errorHandler ErrorHandlerFunc,
 	successHandler SuccessHandlerFunc,
@@ -53,14 +94,20 @@ func NewTelegramRouter(
 	errorsHandler bot.ErrorsHandler,
 ) (*TelegramRouter, error) {
 	r := &TelegramRouter{
-		fsm:            fsm,
-		locker:         locker,
-		userRepository: repository,
-		errorHandler:   errorHandler,
-		successHandler: successHandler,
+		fsm:                 fsm,
+		locker:              locker,
+		lockTimeout:         lockTimeout,
+		profileSyncInterval: profileSyncInterval,
+		userRepository:      repository,
+		errorHandler:        errorHandler,
+		successHandler:      successHandler,
+		rateLimiter:         rateLimiter,
+		roles:               roles,
+		defaultHandler:      defaultHandler,
 	}
+	// Every update goes to dispatch, which does the routing itself once the user is resolved.
 	opts := []bot.Option{
-		bot.WithDefaultHandler(r.WrapHandler(defaultHandler)),
+		bot.WithDefaultHandler(r.dispatch),
 		bot.WithErrorsHandler(errorsHandler),
 	}
 	b, err := bot.New(string(token), opts...)
@@ -68,98 +115,141 @@ func NewTelegramRouter(
 		return nil, fmt.Errorf("failed to setup bot: %w", err)
 	}
 	r.bot = b
+	r.sender = b
+	r.commandsAPI = b
 	return r, nil
 }
 
 func (r *TelegramRouter) Start(ctx context.Context) error {
+	me, err := r.bot.GetMe(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get bot info: %w", err)
+	}
+	r.botUsername = me.Username
 	r.bot.Start(ctx)
 	return nil
 }
 
-func (r *TelegramRouter) WrapHandler(handler HandlerFunc) bot.HandlerFunc {
-	return func(ctx context.Context, b *bot.Bot, update *models.Update) {
-		var userID int64
-		var userName, firstName, lastName string
-		var chatID int64
-
-		if update.Message != nil {
-			chatID = update.Message.Chat.ID
-			userID = update.Message.From.ID
-			userName = update.Message.From.Username
-			firstName = update.Message.From.FirstName
-			lastName = update.Message.From.LastName
-			ctx = logger.WithLogValue(ctx, logger.MessageIDField, update.Message.ID)
-			ctx = logger.WithLogValue(ctx, logger.MessageChatIDField, update.Message.Chat.ID)
-			ctx = logger.WithLogValue(ctx, logger.MessageChatTypeField, update.Message.Chat.Type)
-		} else if update.CallbackQuery != nil {
-			if update.CallbackQuery.Message.Message != nil {
-				chatID = update.CallbackQuery.Message.Message.Chat.ID
-			}
-			userID = update.CallbackQuery.From.ID
-			userName = update.CallbackQuery.From.Username
-			firstName = update.CallbackQuery.From.FirstName
-			lastName = update.CallbackQuery.From.LastName
-		}
+// dispatch handles an update: it rate limits the sender before any storage access, resolves and locks the user once,
+// then runs the first matching route behind the global and the route middlewares.
+func (r *TelegramRouter) dispatch(ctx context.Context, b *bot.Bot, update *models.Update) {
+	var userID int64
+	var userName, firstName, lastName, languageCode string
+	var chatID int64
 
-		ctx = logger.WithLogValue(ctx, logger.ChatIDField, chatID)
-		ctx = logger.WithLogValue(ctx, logger.UserTelegramIDField, userID)
-		ctx = logger.WithLogValue(ctx, logger.UserNameField, userName)
-		ctx = logger.WithLogValue(ctx, logger.UserFirstNameField, firstName)
-		ctx = logger.WithLogValue(ctx, logger.UserLastNameField, lastName)
-		ctx = logger.WithLogValue(ctx, logger.UpdateIDField, update.ID)
-		ctx = logger.WithLogValue(ctx, logger.RequestIDField, utils.NewUniqueID().String())
-		ctx = logger.WithLogValue(ctx, logger.CorrelationIDField, utils.NewUniqueID().String())
-		slog.InfoContext(ctx, fmt.Sprintf("Handling update: %d", update.ID))
-
-		user, err := r.checkUser(ctx,
-			domainUser.TelegramID(userID),
-			domainUser.ChatID(chatID),
-			domainUser.FirstName(firstName),
-			domainUser.LastName(lastName),
-			domainUser.Username(userName),
-		)
-		if err != nil {
-			r.errorHandler(ctx, b, update, fmt.Errorf("failed to check user: %w", err))
-			return
+	if update.Message != nil {
+		chatID = update.Message.Chat.ID
+		userID = update.Message.From.ID
+		userName = update.Message.From.Username
+		firstName = update.Message.From.FirstName
+		lastName = update.Message.From.LastName
+		languageCode = update.Message.From.LanguageCode
+		ctx = logger.WithLogValue(ctx, logger.MessageIDField, update.Message.ID)
+		ctx = logger.WithLogValue(ctx, logger.MessageChatIDField, update.Message.Chat.ID)
+		ctx = logger.WithLogValue(ctx, logger.MessageChatTypeField, update.Message.Chat.Type)
+	} else if update.CallbackQuery != nil {
+		if update.CallbackQuery.Message.Message != nil {
+			chatID = update.CallbackQuery.Message.Message.Chat.ID
 		}
+		userID = update.CallbackQuery.From.ID
+		userName = update.CallbackQuery.From.Username
+		firstName = update.CallbackQuery.From.FirstName
+		lastName = update.CallbackQuery.From.LastName
+		languageCode = update.CallbackQuery.From.LanguageCode
+	}
 
-		slog.DebugContext(ctx, "Trying to lock user")
-		if err := r.locker.Lock(user.ID()); err != nil {
-			r.errorHandler(ctx, b, update, fmt.Errorf("failed to lock user: %w", err))
-			return
+	ctx = logger.WithLogValue(ctx, logger.ChatIDField, chatID)
+	ctx = logger.WithLogValue(ctx, logger.UserTelegramIDField, userID)
+	ctx = logger.WithLogValue(ctx, logger.UserNameField, userName)
+	ctx = logger.WithLogValue(ctx, logger.UserFirstNameField, firstName)
+	ctx = logger.WithLogValue(ctx, logger.UserLastNameField, lastName)
+	ctx = logger.WithLogValue(ctx, logger.UpdateIDField, update.ID)
+	ctx = logger.WithLogValue(ctx, logger.RequestIDField, utils.NewUniqueID().String())
+	ctx = logger.WithLogValue(ctx, logger.CorrelationIDField, utils.NewUniqueID().String())
+	ctx = i18n.WithLang(ctx, i18n.ParseLang(languageCode))
+	slog.InfoContext(ctx, fmt.Sprintf("Handling update: %d", update.ID))
+
+	if r.throttled(ctx, update, userID) {
+		r.replyThrottled(ctx, update, userID)
+		return
+	}
+
+	user, err := r.checkUser(ctx,
+		domainUser.TelegramID(userID),
+		domainUser.ChatID(chatID),
+		domainUser.FirstName(firstName),
+		domainUser.LastName(lastName),
+		domainUser.Username(userName),
+		domainUser.LanguageCode(languageCode),
+	)
+	if err != nil {
+		r.errorHandler(ctx, r.sender, update, fmt.Errorf("failed to check user: %w", err))
+		return
+	}
+	if !user.LanguageCode().IsZero() {
+		ctx = i18n.WithLang(ctx, i18n.ParseLang(string(user.LanguageCode())))
+	}
+
+	slog.DebugContext(ctx, "Trying to lock user")
+	lockCtx, cancelLock := context.WithTimeout(ctx, r.lockTimeout)
+	lock, err := r.locker.Lock(lockCtx, user.ID())
+	cancelLock()
+	if errors.Is(err, locker.ErrLockTimeout) {
+		r.errorHandler(ctx, r.sender, update, fmt.Errorf("%w: %w", core.ErrUserBusy, err))
+		return
+	}
+	if err != nil {
+		r.errorHandler(ctx, r.sender, update, fmt.Errorf("failed to lock user: %w", err))
+		return
+	}
+	slog.DebugContext(ctx, "User locked")
+	defer func() {
+		if err := lock.Unlock(ctx); err != nil {
+			slog.ErrorContext(ctx, "Failed to unlock user", logger.ErrorField, err)
 		}
-		slog.DebugContext(ctx, "User locked")
-		defer func() {
-			if err := r.locker.Unlock(user.ID()); err != nil {
-				slog.ErrorContext(ctx, "Failed to unlock user", logger.ErrorField, err)
-			}
-		}()
-
-		slog.DebugContext(ctx, "Trying to get user state")
-		currentState, err := r.fsm.GetState(user.ID())
-		if err != nil {
-			slog.ErrorContext(ctx, "Failed to get user state", logger.ErrorField, err)
-			r.errorHandler(ctx, b, update, fmt.Errorf("failed to get user state: %w", err))
-			return
+	}()
+
+	slog.DebugContext(ctx, "Trying to get user state")
+	currentState, stateData, err := r.fsm.GetState(ctx, user.ID())
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to get user state", logger.ErrorField, err)
+		r.errorHandler(ctx, r.sender, update, fmt.Errorf("failed to get user state: %w", err))
+		return
+	}
+	ctx = logger.WithLogValue(ctx, logger.CurrentStateField, currentState)
+	slog.DebugContext(ctx, "User state got")
+
+	dataBag := fsm.NewDataBag(stateData)
+	ctx = fsm.WithDataBag(ctx, dataBag)
+	ctx = WithUpdateContext(ctx, &UpdateContext{
+		User:    user,
+		State:   currentState,
+		Roles:   r.roles.Of(int64(user.TelegramID())),
+		Command: r.command(update),
+	})
+
+	handler, middlewares := r.defaultHandler, []Middleware(nil)
+	for _, route := range r.routes {
+		if route.match(ctx, update) {
+			handler, middlewares = route.handler, route.middlewares
+			break
 		}
-		ctx = logger.WithLogValue(ctx, logger.CurrentStateField, currentState)
-		slog.DebugContext(ctx, "User state got")
-		nextState, msgParams, err := handler(ctx, b, update, currentState)
-		if err != nil {
-			r.errorHandler(ctx, b, update, fmt.Errorf("failed to handle route: %w", err))
+	}
+	nextState, msgParams, err := r.chain(handler, middlewares)(ctx, b, update, currentState)
+	if err != nil {
+		r.errorHandler(ctx, r.sender, update, fmt.Errorf("failed to handle route: %w", err))
+		return
+	}
+	if nextState != currentState || dataBag.Dirty() {
+		if err := r.fsm.SetState(ctx, user.ID(), nextState, dataBag.Data()); err != nil {
+			r.errorHandler(ctx, r.sender, update, fmt.Errorf("failed to set user state: %w", err))
 			return
 		}
-		if nextState != currentState {
-			if err := r.fsm.SetState(user.ID(), nextState); err != nil {
-				r.errorHandler(ctx, b, update, fmt.Errorf("failed to set user state: %w", err))
-				return
-			}
-			ctx = logger.WithLogValue(ctx, logger.NextStateField, nextState)
-			slog.DebugContext(ctx, "User state updated")
-		}
-
-		r.successHandler(ctx, b, update, nextState, msgParams)
+		ctx = logger.WithLogValue(ctx, logger.NextStateField, nextState)
+		slog.DebugContext(ctx, "User state updated")
 	}
+
+	r.successHandler(ctx, r.sender, update, nextState, msgParams)
 }
 
 func (r *TelegramRouter) checkUser(
@@ -169,8 +259,8 @@ func (r *TelegramRouter) checkUser(
 	firstName domainUser.FirstName,
 	lastName domainUser.LastName,
 	username domainUser.Username,
+	languageCode domainUser.LanguageCode,
 ) (domainUser.User, error) {
-	// TODO: add cache for user
 	user, repoErr := r.userRepository.UserByTelegramID(ctx, int64(id))
 
 	if errors.Is(repoErr, core.ErrUserNotFound) {
@@ -183,6 +273,7 @@ func (r *TelegramRouter) checkUser(
 			firstName,
 			lastName,
 			username,
+			languageCode,
 		)
 		if err != nil {
 			return domainUser.User{}, fmt.Errorf("failed to create new user in storage: %w", err)
@@ -191,33 +282,117 @@ func (r *TelegramRouter) checkUser(
 		user = newDBUser
 	} else if repoErr != nil {
 		return domainUser.User{}, fmt.Errorf("failed to get user by telegram ID: %w", repoErr)
+	} else if synced, changed := user.SyncProfile(firstName, lastName, username); changed {
+		user = r.syncProfile(ctx, user, synced)
 	}
 	logger.WithLogValue(ctx, logger.UserIDField, user.ID().String())
 	return user, nil
 }
 
-func (r *TelegramRouter) StateMatchFunc(ctx context.Context, expectedState fsm.State) bot.MatchFunc {
-	return func(update *models.Update) bool {
-		if update.Message == nil || update.Message.From == nil {
-			return false
-		}
+// command parses the command of the update, commands addressed to other bots in groups are ignored.
+func (r *TelegramRouter) command(update *models.Update) *Command {
+	cmd, ok := ParseCommand(update.Message)
+	if !ok || !cmd.AddressedTo(r.botUsername) {
+		return nil
+	}
+	return &cmd
+}
 
-		user, err := r.userRepository.UserByTelegramID(ctx, update.Message.From.ID)
-		if err != nil {
-			return false
+// syncProfile saves the profile changes made in Telegram, at most once per profileSyncInterval.
+// The update goes on with the stored user if the changes are not saved.
+func (r *TelegramRouter) syncProfile(ctx context.Context, user, synced domainUser.User) domainUser.User {
+	if time.Since(user.UpdatedAt()) < r.profileSyncInterval {
+		slog.DebugContext(ctx, "User profile changed, sync postponed")
+		return user
+	}
+	updated, err := r.userRepository.SyncUserProfile(ctx, user, synced)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to sync user profile", logger.ErrorField, err.Error())
+		return user
+	}
+	slog.InfoContext(ctx, "User profile synced")
+	return updated
+}
+
+// throttled takes a token for the update class and reports whether the sender is over the limit.
+// Limiter failures let the update through.
+func (r *TelegramRouter) throttled(ctx context.Context, update *models.Update, telegramID int64) bool {
+	var class ratelimit.Class
+	switch {
+	case update.Message != nil:
+		class = ratelimit.ClassMessage
+	case update.CallbackQuery != nil:
+		class = ratelimit.ClassCallback
+	default:
+		return false
+	}
+	return !r.allow(ctx, class, telegramID)
+}
+
+// replyThrottled asks the user to slow down. The reply itself is rate limited, so a flood gets only a few of them.
+func (r *TelegramRouter) replyThrottled(ctx context.Context, update *models.Update, telegramID int64) {
+	if r.allow(ctx, ratelimit.ClassWarning, telegramID) {
+		r.errorHandler(ctx, r.sender, update, core.ErrTooManyRequests)
+		return
+	}
+	slog.DebugContext(ctx, "Update throttled")
+	if update.CallbackQuery != nil {
+		// Stops the loading indicator on the button without showing anything.
+		if _, err := r.sender.AnswerCallbackQuery(ctx, &bot.AnswerCallbackQueryParams{
+			CallbackQueryID: update.CallbackQuery.ID,
+		}); err != nil {
+			slog.WarnContext(ctx, "Failed to answer throttled callback query", logger.ErrorField, err.Error())
 		}
+	}
+}
 
-		currentState, err := r.fsm.GetState(user.ID())
-		if err != nil {
-			return false
+func (r *TelegramRouter) allow(ctx context.Context, class ratelimit.Class, telegramID int64) bool {
+	if r.rateLimiter == nil {
+		return true
+	}
+	ctx, cancel := context.WithTimeout(ctx, rateLimitTimeout)
+	defer cancel()
+	allowed, err := r.rateLimiter.Allow(ctx, class, telegramID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to check rate limit", logger.ErrorField, err.Error())
+		return true
+	}
+	return allowed
+}
+
+// RateLimited limits a route with its own class on top of the per update type limits.
+func (r *TelegramRouter) RateLimited(class ratelimit.Class, handler HandlerFunc) HandlerFunc {
+	return func(ctx context.Context, b *bot.Bot, update *models.Update, currentState fsm.State) (fsm.State, Response, error) {
+		telegramID, ok := updateSenderID(update)
+		if ok && !r.allow(ctx, class, telegramID) {
+			r.replyThrottled(ctx, update, telegramID)
+			return currentState, nil, nil
 		}
+		return handler(ctx, b, update, currentState)
+	}
+}
 
-		return currentState == expectedState
+func updateSenderID(update *models.Update) (int64, bool) {
+	switch {
+	case update.Message != nil && update.Message.From != nil:
+		return update.Message.From.ID, true
+	case update.CallbackQuery != nil:
+		return update.CallbackQuery.From.ID, true
+	default:
+		return 0, false
 	}
 }
 
-func (r *TelegramRouter) RegisterHandlerMatchFunc(matcher bot.MatchFunc, handler HandlerFunc) {
-	r.bot.RegisterHandlerMatchFunc(matcher, r.WrapHandler(handler))
+// RegisterHandlerMatchFunc adds a route. Routes are matched in the order they were added, the first match wins.
+// It must be called before Start.
+func (r *TelegramRouter) RegisterHandlerMatchFunc(match MatchFunc, handler HandlerFunc, middlewares ...Middleware) {
+	r.routes = append(r.routes, route{match: match, handler: handler, middlewares: middlewares})
+}
+
+// SetSender replaces the bot as the sender of replies and errors, e.g. with one that retries.
+// It must be called before Start.
+func (r *TelegramRouter) SetSender(sender utils.IMessageSender) {
+	r.sender = sender
 }
 
 func (r *TelegramRouter) Bot() *bot.Bot {
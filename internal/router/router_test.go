@@ -0,0 +1,294 @@
+package router
+
+import (
+	"context"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core"
+	"whitelist-bot/internal/core/utils"
+	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/fsm"
+	memoryFSM "whitelist-bot/internal/fsm/memory"
+	memoryLocker "whitelist-bot/internal/locker/memory"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeUserRepository struct {
+	users map[int64]domainUser.User
+	reads int
+	syncs int
+}
+
+func (f *fakeUserRepository) UserByTelegramID(_ context.Context, telegramID int64) (domainUser.User, error) {
+	f.reads++
+	user, ok := f.users[telegramID]
+	if !ok {
+		return domainUser.User{}, core.ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (f *fakeUserRepository) CreateUser(
+	_ context.Context,
+	telegramID domainUser.TelegramID,
+	chatID domainUser.ChatID,
+	firstName domainUser.FirstName,
+	lastName domainUser.LastName,
+	username domainUser.Username,
+	languageCode domainUser.LanguageCode,
+) (domainUser.User, error) {
+	user, err := domainUser.NewBuilder().
+		IDFromUUID(uuid.New()).
+		TelegramID(telegramID).
+		ChatID(chatID).
+		FirstName(firstName).
+		LastName(lastName).
+		Username(username).
+		LanguageCode(languageCode).
+		CreatedAt(time.Now()).
+		UpdatedAt(time.Now()).
+		Build()
+	if err != nil {
+		return domainUser.User{}, err
+	}
+	f.users[int64(telegramID)] = user
+	return user, nil
+}
+
+func (f *fakeUserRepository) SyncUserProfile(_ context.Context, _, synced domainUser.User) (domainUser.User, error) {
+	f.syncs++
+	f.users[int64(synced.TelegramID())] = synced
+	return synced, nil
+}
+
+type dispatchResult struct {
+	route string
+	state fsm.State
+	err   error
+}
+
+func newTestRouter(t *testing.T, repo *fakeUserRepository, result *dispatchResult) *TelegramRouter {
+	t.Helper()
+	r := &TelegramRouter{
+		fsm:            memoryFSM.New(nil),
+		locker:         memoryLocker.New(time.Minute),
+		lockTimeout:    time.Second,
+		userRepository: repo,
+		roles:          Roles{RoleAdmin: {1}},
+		errorHandler: func(_ context.Context, _ utils.IMessageSender, _ *models.Update, err error) {
+			result.err = err
+		},
+		successHandler: func(_ context.Context, _ utils.IMessageSender, _ *models.Update, state fsm.State, _ Response) {
+			result.state = state
+		},
+	}
+	r.defaultHandler = routeHandler("default", result)
+	return r
+}
+
+func routeHandler(name string, result *dispatchResult) HandlerFunc {
+	return func(ctx context.Context, _ *bot.Bot, _ *models.Update, state fsm.State) (fsm.State, Response, error) {
+		result.route = name
+		if _, err := UserFromContext(ctx); err != nil {
+			return state, nil, err
+		}
+		return fsm.StateWaitingWLNickname, nil, nil
+	}
+}
+
+func matchState(state fsm.State) MatchFunc {
+	return func(ctx context.Context, _ *models.Update) bool {
+		uc, ok := UpdateFromContext(ctx)
+		return ok && uc.State == state
+	}
+}
+
+func matchRole(role Role) MatchFunc {
+	return func(ctx context.Context, _ *models.Update) bool {
+		uc, ok := UpdateFromContext(ctx)
+		return ok && uc.HasRole(role)
+	}
+}
+
+// matchWaitingText matches text messages from users in the state, like matcher.State with matcher.TextMessage.
+func matchWaitingText(state fsm.State) MatchFunc {
+	return func(ctx context.Context, update *models.Update) bool {
+		return matchState(state)(ctx, update) && update.Message != nil && update.Message.Text != ""
+	}
+}
+
+func TestTelegramRouter_dispatch(t *testing.T) {
+	tests := []struct {
+		name       string
+		telegramID int64
+		wantRoute  string
+	}{
+		{name: "first matching route", telegramID: 1, wantRoute: "admin"},
+		{name: "next route", telegramID: 2, wantRoute: "idle"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepository{users: map[int64]domainUser.User{}}
+			result := &dispatchResult{}
+			r := newTestRouter(t, repo, result)
+			r.RegisterHandlerMatchFunc(matchState(fsm.StateWaitingWLNickname), routeHandler("waiting", result))
+			r.RegisterHandlerMatchFunc(matchRole(RoleAdmin), routeHandler("admin", result))
+			r.RegisterHandlerMatchFunc(matchState(fsm.StateIdle), routeHandler("idle", result))
+
+			r.dispatch(context.Background(), nil, &models.Update{
+				Message: &models.Message{
+					From: &models.User{ID: tt.telegramID, Username: "user"},
+					Chat: models.Chat{ID: tt.telegramID},
+				},
+			})
+
+			require.NoError(t, result.err)
+			assert.Equal(t, tt.wantRoute, result.route)
+			assert.Equal(t, fsm.StateWaitingWLNickname, result.state)
+			// The user is resolved once, however many routes are matched.
+			assert.Equal(t, 1, repo.reads)
+			assert.Len(t, repo.users, 1)
+		})
+	}
+}
+
+func TestTelegramRouter_dispatch_DefaultHandler(t *testing.T) {
+	repo := &fakeUserRepository{users: map[int64]domainUser.User{}}
+	result := &dispatchResult{}
+	r := newTestRouter(t, repo, result)
+	r.RegisterHandlerMatchFunc(matchState(fsm.StateWaitingWLNickname), routeHandler("waiting", result))
+
+	r.dispatch(context.Background(), nil, &models.Update{
+		Message: &models.Message{
+			From: &models.User{ID: 2, Username: "user"},
+			Chat: models.Chat{ID: 2},
+		},
+	})
+
+	require.NoError(t, result.err)
+	assert.Equal(t, "default", result.route)
+}
+
+func TestTelegramRouter_dispatch_Command(t *testing.T) {
+	tests := []struct {
+		name      string
+		text      string
+		wantRoute string
+	}{
+		{name: "command", text: "/start", wantRoute: "start"},
+		{name: "command for the bot", text: "/start@OurBot", wantRoute: "start"},
+		{name: "command for another bot", text: "/start@OtherBot", wantRoute: "default"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo := &fakeUserRepository{users: map[int64]domainUser.User{}}
+			result := &dispatchResult{}
+			r := newTestRouter(t, repo, result)
+			r.botUsername = "OurBot"
+			r.RegisterHandlerMatchFunc(func(ctx context.Context, _ *models.Update) bool {
+				cmd, ok := CommandFromContext(ctx)
+				return ok && cmd.Name == "start"
+			}, routeHandler("start", result))
+
+			r.dispatch(context.Background(), nil, &models.Update{
+				Message: &models.Message{
+					From:     &models.User{ID: 2, Username: "user"},
+					Chat:     models.Chat{ID: -100},
+					Text:     tt.text,
+					Entities: []models.MessageEntity{{Type: models.MessageEntityTypeBotCommand, Length: len(tt.text)}},
+				},
+			})
+
+			require.NoError(t, result.err)
+			assert.Equal(t, tt.wantRoute, result.route)
+		})
+	}
+}
+
+func TestTelegramRouter_dispatch_SyncProfile(t *testing.T) {
+	tests := []struct {
+		name         string
+		updatedAgo   time.Duration
+		username     string
+		wantSyncs    int
+		wantUsername domainUser.Username
+	}{
+		{name: "unchanged profile", updatedAgo: time.Hour, username: "old", wantUsername: "old"},
+		{name: "changed profile", updatedAgo: time.Hour, username: "new", wantSyncs: 1, wantUsername: "new"},
+		{name: "changed profile recently synced", updatedAgo: time.Second, username: "new", wantUsername: "old"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stored, err := domainUser.NewBuilder().
+				IDFromUUID(uuid.New()).
+				TelegramID(2).
+				ChatID(2).
+				Username("old").
+				CreatedAt(time.Now().Add(-tt.updatedAgo)).
+				UpdatedAt(time.Now().Add(-tt.updatedAgo)).
+				Build()
+			require.NoError(t, err)
+			repo := &fakeUserRepository{users: map[int64]domainUser.User{2: stored}}
+			result := &dispatchResult{}
+			r := newTestRouter(t, repo, result)
+			r.profileSyncInterval = time.Minute
+
+			r.dispatch(context.Background(), nil, &models.Update{
+				Message: &models.Message{
+					From: &models.User{ID: 2, Username: tt.username},
+					Chat: models.Chat{ID: 2},
+				},
+			})
+
+			require.NoError(t, result.err)
+			assert.Equal(t, tt.wantSyncs, repo.syncs)
+			assert.Equal(t, tt.wantUsername, repo.users[2].Username())
+		})
+	}
+}
+
+func TestRoles_Of(t *testing.T) {
+	roles := Roles{RoleAdmin: {1, 2}, RoleOwner: {1}}
+
+	assert.Equal(t, []Role{RoleAdmin, RoleOwner}, roles.Of(1))
+	assert.Equal(t, []Role{RoleAdmin}, roles.Of(2))
+	assert.Empty(t, roles.Of(3))
+}
+
+func TestTelegramRouter_dispatch_CallbackWhileWaitingForText(t *testing.T) {
+	repo := &fakeUserRepository{users: map[int64]domainUser.User{}}
+	result := &dispatchResult{}
+	r := newTestRouter(t, repo, result)
+	// The same order as in main: the state route waiting for a text answer goes before the callbacks.
+	r.RegisterHandlerMatchFunc(matchWaitingText(fsm.StateWaitingWLNickname), routeHandler("nickname", result))
+	r.RegisterHandlerMatchFunc(func(_ context.Context, update *models.Update) bool {
+		return update.CallbackQuery != nil
+	}, routeHandler("callback", result))
+
+	// The default route puts the user into the waiting state.
+	r.dispatch(context.Background(), nil, &models.Update{
+		Message: &models.Message{From: &models.User{ID: 2, Username: "user"}, Chat: models.Chat{ID: 2}, Text: "New request"},
+	})
+	require.NoError(t, result.err)
+	require.Equal(t, "default", result.route)
+
+	r.dispatch(context.Background(), nil, &models.Update{
+		CallbackQuery: &models.CallbackQuery{ID: "1", From: models.User{ID: 2, Username: "user"}, Data: "approve"},
+	})
+	require.NoError(t, result.err)
+	assert.Equal(t, "callback", result.route)
+
+	r.dispatch(context.Background(), nil, &models.Update{
+		Message: &models.Message{From: &models.User{ID: 2, Username: "user"}, Chat: models.Chat{ID: 2}, Text: "steve"},
+	})
+	require.NoError(t, result.err)
+	assert.Equal(t, "nickname", result.route)
+}
@@ -0,0 +1,77 @@
+package router
+
+import (
+	"context"
+	"errors"
+	"slices"
+	domainUser "whitelist-bot/internal/domain/user"
+	"whitelist-bot/internal/fsm"
+)
+
+var ErrNoUpdateContext = errors.New("no update context")
+
+type Role string
+
+const (
+	RoleAdmin  Role = "admin"
+	RoleOnDuty Role = "on_duty"
+	RoleOwner  Role = "owner"
+)
+
+// Roles lists the Telegram IDs of every role.
+type Roles map[Role][]int64
+
+// Of returns the roles of the user.
+func (r Roles) Of(telegramID int64) []Role {
+	var roles []Role
+	for role, ids := range r {
+		if slices.Contains(ids, telegramID) {
+			roles = append(roles, role)
+		}
+	}
+	slices.Sort(roles)
+	return roles
+}
+
+// UpdateContext is what the router resolved about the update before routing it.
+type UpdateContext struct {
+	User domainUser.User
+	// State is the user state the update arrived in.
+	State fsm.State
+	Roles []Role
+	// Command is the command the message starts with, nil for other updates and commands for other bots.
+	Command *Command
+}
+
+func (u *UpdateContext) HasRole(role Role) bool {
+	return slices.Contains(u.Roles, role)
+}
+
+type updateContextKey struct{}
+
+func WithUpdateContext(ctx context.Context, uc *UpdateContext) context.Context {
+	return context.WithValue(ctx, updateContextKey{}, uc)
+}
+
+func UpdateFromContext(ctx context.Context) (*UpdateContext, bool) {
+	uc, ok := ctx.Value(updateContextKey{}).(*UpdateContext)
+	return uc, ok
+}
+
+// UserFromContext returns the user the update came from.
+func UserFromContext(ctx context.Context) (domainUser.User, error) {
+	uc, ok := UpdateFromContext(ctx)
+	if !ok {
+		return domainUser.User{}, ErrNoUpdateContext
+	}
+	return uc.User, nil
+}
+
+// CommandFromContext returns the command of the update.
+func CommandFromContext(ctx context.Context) (Command, bool) {
+	uc, ok := UpdateFromContext(ctx)
+	if !ok || uc.Command == nil {
+		return Command{}, false
+	}
+	return *uc.Command, true
+}
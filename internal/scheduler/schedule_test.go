@@ -0,0 +1,141 @@
+package scheduler
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseCron_Next(t *testing.T) {
+	t.Parallel()
+
+	// Wednesday.
+	from := time.Date(2025, time.January, 15, 10, 30, 20, 0, time.UTC)
+
+	tests := []struct {
+		name string
+		expr string
+		want time.Time
+	}{
+		{
+			name: "every_minute",
+			expr: "* * * * *",
+			want: time.Date(2025, time.January, 15, 10, 31, 0, 0, time.UTC),
+		},
+		{
+			name: "step",
+			expr: "*/15 * * * *",
+			want: time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "value_with_step",
+			expr: "5/20 * * * *",
+			want: time.Date(2025, time.January, 15, 10, 45, 0, 0, time.UTC),
+		},
+		{
+			name: "next_day",
+			expr: "0 9 * * *",
+			want: time.Date(2025, time.January, 16, 9, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "list_and_range",
+			expr: "0 8-9,12 * * *",
+			want: time.Date(2025, time.January, 15, 12, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "weekday_name",
+			expr: "0 0 * * mon",
+			want: time.Date(2025, time.January, 20, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "sunday_as_seven",
+			expr: "0 0 * * 7",
+			want: time.Date(2025, time.January, 19, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "month_name",
+			expr: "0 0 1 mar *",
+			want: time.Date(2025, time.March, 1, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "day_of_month_or_weekday",
+			expr: "0 0 20 * fri",
+			want: time.Date(2025, time.January, 17, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "leap_day",
+			expr: "0 0 29 2 *",
+			want: time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "descriptor",
+			expr: "@hourly",
+			want: time.Date(2025, time.January, 15, 11, 0, 0, 0, time.UTC),
+		},
+		{
+			name: "never",
+			expr: "0 0 31 2 *",
+			want: time.Time{},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			s, err := ParseCron(tt.expr, time.UTC)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, s.Next(from))
+			assert.Equal(t, tt.expr, s.String())
+		})
+	}
+}
+
+func TestParseCron_Location(t *testing.T) {
+	t.Parallel()
+
+	loc := time.FixedZone("UTC+3", 3*60*60)
+	s, err := ParseCron("0 9 * * *", loc)
+	require.NoError(t, err)
+
+	from := time.Date(2025, time.January, 15, 5, 0, 0, 0, time.UTC)
+	assert.Equal(t, time.Date(2025, time.January, 15, 6, 0, 0, 0, time.UTC), s.Next(from))
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * * 13 *",
+		"* * * * 8",
+		"*/0 * * * *",
+		"10-5 * * * *",
+		"a * * * *",
+		"@every 1h",
+	}
+	for _, expr := range tests {
+		t.Run(expr, func(t *testing.T) {
+			t.Parallel()
+
+			_, err := ParseCron(expr, time.UTC)
+			require.ErrorIs(t, err, ErrInvalidCron)
+		})
+	}
+}
+
+func TestEvery(t *testing.T) {
+	t.Parallel()
+
+	from := time.Date(2025, time.January, 15, 10, 30, 20, 0, time.UTC)
+	s := Every(time.Minute)
+
+	assert.Equal(t, from.Add(time.Minute), s.Next(from))
+	assert.Equal(t, "every 1m0s", s.String())
+}
@@ -0,0 +1,233 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"runtime/debug"
+	"sync"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+)
+
+var (
+	ErrJobExists       = errors.New("job already exists")
+	ErrSchedulerLocked = errors.New("scheduler is already started")
+)
+
+// JobFunc is the work of a job. ctx is cancelled when the job timeout passes or the app stops.
+type JobFunc func(ctx context.Context) error
+
+// Job is a periodic task. Runs of the same job never overlap: an activation that comes while
+// the previous run is still going is skipped. Jobs run on every replica, so they must be idempotent.
+type Job struct {
+	Name     string
+	Schedule Schedule
+	Run      JobFunc
+	// Timeout limits a single run. Zero means no limit besides the app context.
+	Timeout time.Duration
+	// Jitter delays every activation by a random duration up to Jitter, so replicas don't run at once.
+	Jitter time.Duration
+}
+
+// Status is the last known state of a job.
+type Status struct {
+	Name         string
+	Schedule     string
+	Running      bool
+	Runs         int
+	Failures     int
+	LastRun      time.Time
+	LastDuration time.Duration
+	LastError    string
+	NextRun      time.Time
+}
+
+type jobState struct {
+	job Job
+
+	mu     sync.Mutex
+	status Status
+}
+
+type Scheduler struct {
+	mu      sync.RWMutex
+	jobs    []*jobState
+	started bool
+	wg      sync.WaitGroup
+	now     func() time.Time
+}
+
+func New() *Scheduler {
+	return &Scheduler{now: time.Now}
+}
+
+// Add registers a job. Jobs must be added before Start.
+func (s *Scheduler) Add(job Job) error {
+	if job.Name == "" || job.Schedule == nil || job.Run == nil {
+		return fmt.Errorf("job %q: name, schedule and run are required", job.Name)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return ErrSchedulerLocked
+	}
+	for _, state := range s.jobs {
+		if state.job.Name == job.Name {
+			return fmt.Errorf("%w: %s", ErrJobExists, job.Name)
+		}
+	}
+	s.jobs = append(s.jobs, &jobState{
+		job:    job,
+		status: Status{Name: job.Name, Schedule: job.Schedule.String()},
+	})
+	return nil
+}
+
+// Start runs every job on its schedule until ctx is done. Use Wait to let running jobs finish.
+func (s *Scheduler) Start(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.started {
+		return
+	}
+	s.started = true
+
+	for _, state := range s.jobs {
+		s.wg.Add(1)
+		go func() {
+			defer s.wg.Done()
+			s.loop(ctx, state)
+		}()
+	}
+	slog.InfoContext(ctx, "Scheduler started", "jobs", len(s.jobs))
+}
+
+// Wait blocks until all job loops and running jobs are stopped.
+func (s *Scheduler) Wait() {
+	s.wg.Wait()
+}
+
+// Statuses returns the state of every job in registration order.
+func (s *Scheduler) Statuses() []Status {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	statuses := make([]Status, 0, len(s.jobs))
+	for _, state := range s.jobs {
+		state.mu.Lock()
+		statuses = append(statuses, state.status)
+		state.mu.Unlock()
+	}
+	return statuses
+}
+
+func (s *Scheduler) loop(ctx context.Context, state *jobState) {
+	var running sync.WaitGroup
+	defer running.Wait()
+
+	for {
+		next := state.job.Schedule.Next(s.now())
+		if next.IsZero() {
+			slog.WarnContext(ctx, "Job schedule has no next activation, stopping", logger.JobField, state.job.Name)
+			return
+		}
+		if state.job.Jitter > 0 {
+			next = next.Add(rand.N(state.job.Jitter))
+		}
+		state.setNextRun(next)
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		if !state.tryStart(s.now()) {
+			slog.WarnContext(ctx, "Previous job run is still going, skipping", logger.JobField, state.job.Name)
+			continue
+		}
+		running.Add(1)
+		go func() {
+			defer running.Done()
+			s.run(ctx, state)
+		}()
+	}
+}
+
+// run executes the job once with the same log context fields as update handlers.
+func (s *Scheduler) run(ctx context.Context, state *jobState) {
+	ctx = logger.WithLogValue(ctx, logger.JobField, state.job.Name)
+	ctx = logger.WithLogValue(ctx, logger.RequestIDField, utils.NewUniqueID().String())
+	ctx = logger.WithLogValue(ctx, logger.CorrelationIDField, utils.NewUniqueID().String())
+
+	if state.job.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, state.job.Timeout)
+		defer cancel()
+	}
+
+	slog.DebugContext(ctx, "Job started")
+	start := s.now()
+	err := runSafely(ctx, state.job.Run)
+	duration := s.now().Sub(start)
+	state.finish(duration, err)
+
+	if err != nil {
+		slog.ErrorContext(ctx, "Job failed", logger.ErrorField, err.Error(), logger.DurationField, duration.String())
+		return
+	}
+	slog.InfoContext(ctx, "Job finished", logger.DurationField, duration.String())
+}
+
+// runSafely turns a panic in the job into an error, so one broken job doesn't stop the app.
+func runSafely(ctx context.Context, run JobFunc) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.ErrorContext(ctx, "Job panicked", "panic", r, "stack", string(debug.Stack()))
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+	return run(ctx)
+}
+
+func (st *jobState) setNextRun(next time.Time) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.status.NextRun = next
+}
+
+func (st *jobState) tryStart(now time.Time) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	if st.status.Running {
+		return false
+	}
+	st.status.Running = true
+	st.status.LastRun = now
+	return true
+}
+
+func (st *jobState) finish(duration time.Duration, err error) {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+
+	st.status.Running = false
+	st.status.Runs++
+	st.status.LastDuration = duration
+	st.status.LastError = ""
+	if err != nil {
+		st.status.Failures++
+		st.status.LastError = err.Error()
+	}
+}
@@ -0,0 +1,194 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+	"whitelist-bot/internal/core/logger"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const tick = 10 * time.Millisecond
+
+func TestScheduler_Add(t *testing.T) {
+	t.Parallel()
+
+	noop := func(context.Context) error { return nil }
+	s := New()
+
+	require.NoError(t, s.Add(Job{Name: "a", Schedule: Every(time.Hour), Run: noop}))
+	require.ErrorIs(t, s.Add(Job{Name: "a", Schedule: Every(time.Hour), Run: noop}), ErrJobExists)
+	require.Error(t, s.Add(Job{Name: "b", Run: noop}))
+	require.Error(t, s.Add(Job{Name: "c", Schedule: Every(time.Hour)}))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	s.Start(ctx)
+	cancel()
+	s.Wait()
+
+	require.ErrorIs(t, s.Add(Job{Name: "d", Schedule: Every(time.Hour), Run: noop}), ErrSchedulerLocked)
+}
+
+func TestScheduler_RunsJobs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		runs     atomic.Int32
+		jobName  atomic.Value
+		hasReqID atomic.Bool
+	)
+	s := New()
+	require.NoError(t, s.Add(Job{
+		Name:     "counter",
+		Schedule: Every(tick),
+		Run: func(ctx context.Context) error {
+			name, _ := logger.LogValue(ctx, logger.JobField)
+			jobName.Store(name)
+			_, ok := logger.LogValue(ctx, logger.RequestIDField)
+			hasReqID.Store(ok)
+			runs.Add(1)
+			return nil
+		},
+	}))
+	s.Start(ctx)
+
+	require.Eventually(t, func() bool { return runs.Load() >= 3 }, time.Second, tick)
+	cancel()
+	s.Wait()
+
+	assert.Equal(t, "counter", jobName.Load())
+	assert.True(t, hasReqID.Load())
+
+	statuses := s.Statuses()
+	require.Len(t, statuses, 1)
+	assert.Equal(t, "counter", statuses[0].Name)
+	assert.Equal(t, "every 10ms", statuses[0].Schedule)
+	assert.GreaterOrEqual(t, statuses[0].Runs, 3)
+	assert.Zero(t, statuses[0].Failures)
+	assert.Empty(t, statuses[0].LastError)
+	assert.False(t, statuses[0].LastRun.IsZero())
+	assert.False(t, statuses[0].Running)
+}
+
+func TestScheduler_Failures(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name      string
+		run       JobFunc
+		timeout   time.Duration
+		wantError string
+	}{
+		{
+			name:      "error",
+			run:       func(context.Context) error { return errors.New("boom") },
+			wantError: "boom",
+		},
+		{
+			name:      "panic",
+			run:       func(context.Context) error { panic("boom") },
+			wantError: "job panicked: boom",
+		},
+		{
+			name: "timeout",
+			run: func(ctx context.Context) error {
+				<-ctx.Done()
+				return ctx.Err()
+			},
+			timeout:   tick,
+			wantError: context.DeadlineExceeded.Error(),
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			s := New()
+			require.NoError(t, s.Add(Job{Name: tt.name, Schedule: Every(tick), Run: tt.run, Timeout: tt.timeout}))
+			s.Start(ctx)
+
+			require.Eventually(t, func() bool {
+				status := s.Statuses()[0]
+				return status.Failures > 0 && status.LastError == tt.wantError
+			}, time.Second, tick)
+			cancel()
+			s.Wait()
+		})
+	}
+}
+
+func TestScheduler_SkipsOverlappingRuns(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var (
+		running atomic.Int32
+		maxRun  atomic.Int32
+		runs    atomic.Int32
+	)
+	s := New()
+	require.NoError(t, s.Add(Job{
+		Name:     "slow",
+		Schedule: Every(tick),
+		Run: func(context.Context) error {
+			n := running.Add(1)
+			defer running.Add(-1)
+			if n > maxRun.Load() {
+				maxRun.Store(n)
+			}
+			runs.Add(1)
+			time.Sleep(5 * tick)
+			return nil
+		},
+	}))
+	s.Start(ctx)
+
+	require.Eventually(t, func() bool { return runs.Load() >= 2 }, time.Second, tick)
+	cancel()
+	s.Wait()
+
+	assert.Equal(t, int32(1), maxRun.Load())
+}
+
+func TestScheduler_WaitsForRunningJobs(t *testing.T) {
+	t.Parallel()
+
+	ctx, cancel := context.WithCancel(context.Background())
+
+	started := make(chan struct{})
+	var finished atomic.Bool
+	s := New()
+	require.NoError(t, s.Add(Job{
+		Name:     "graceful",
+		Schedule: Every(tick),
+		Run: func(ctx context.Context) error {
+			select {
+			case started <- struct{}{}:
+			default:
+			}
+			<-ctx.Done()
+			time.Sleep(tick)
+			finished.Store(true)
+			return nil
+		},
+	}))
+	s.Start(ctx)
+
+	<-started
+	cancel()
+	s.Wait()
+
+	assert.True(t, finished.Load())
+}
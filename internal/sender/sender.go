@@ -0,0 +1,379 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+	"whitelist-bot/internal/core/logger"
+	"whitelist-bot/internal/core/utils"
+	"whitelist-bot/internal/ratelimit"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+)
+
+var ErrStopped = errors.New("sender stopped")
+
+// Priority orders the outbound queue. Lower values are sent first.
+type Priority int
+
+const (
+	// PriorityInteractive is for replies to users' own actions.
+	PriorityInteractive Priority = iota
+	// PriorityNotification is for messages triggered by events, e.g. a new request for admins.
+	PriorityNotification
+	// PriorityBulk is for broadcasts and background job reports.
+	PriorityBulk
+)
+
+const chatSweepInterval = time.Minute
+
+// serverErrorRe matches errors of 5xx responses, the bot library doesn't expose the status code.
+var serverErrorRe = regexp.MustCompile(`error response from telegram for method \w+, 5\d\d `)
+
+type Config struct {
+	// GlobalLimit is the budget of all requests of the bot.
+	GlobalLimit ratelimit.Limit
+	// ChatLimit is the budget of requests to a single chat. Callback answers don't belong to a chat.
+	ChatLimit ratelimit.Limit
+	// MaxRetries is how many times a request is retried after 429 and 5xx responses.
+	MaxRetries  int
+	BaseBackoff time.Duration
+	MaxBackoff  time.Duration
+	// Workers is the number of requests in flight.
+	Workers int
+	// ParseMode is set on messages that don't have one.
+	ParseMode models.ParseMode
+}
+
+type request struct {
+	ctx       context.Context
+	priority  Priority
+	seq       uint64
+	chat      string
+	notBefore time.Time
+	attempt   int
+	call      func(ctx context.Context) error
+	done      chan error
+}
+
+type chatState struct {
+	bucket       ratelimit.Bucket
+	blockedUntil time.Time
+}
+
+// Sender sends requests to Telegram through a priority queue, keeping within the global and the per-chat budgets.
+// Flood control responses and server errors are retried. Run must be running for requests to be sent.
+type Sender struct {
+	inner utils.IMessageSender
+	cfg   Config
+	now   func() time.Time
+
+	mu                 sync.Mutex
+	queue              []*request
+	seq                uint64
+	stopped            bool
+	global             ratelimit.Bucket
+	globalBlockedUntil time.Time
+	chats              map[string]chatState
+	lastSweep          time.Time
+
+	wake    chan struct{}
+	workers chan struct{}
+}
+
+func New(inner utils.IMessageSender, cfg Config) *Sender {
+	return &Sender{
+		inner:   inner,
+		cfg:     cfg,
+		now:     time.Now,
+		chats:   make(map[string]chatState),
+		wake:    make(chan struct{}, 1),
+		workers: make(chan struct{}, max(cfg.Workers, 1)),
+	}
+}
+
+func (s *Sender) SendMessage(ctx context.Context, params *bot.SendMessageParams) (*models.Message, error) {
+	return s.sendMessage(ctx, PriorityInteractive, params)
+}
+
+func (s *Sender) AnswerCallbackQuery(ctx context.Context, params *bot.AnswerCallbackQueryParams) (bool, error) {
+	return s.answerCallbackQuery(ctx, PriorityInteractive, params)
+}
+
+func (s *Sender) EditMessageText(ctx context.Context, params *bot.EditMessageTextParams) (*models.Message, error) {
+	return s.editMessageText(ctx, PriorityInteractive, params)
+}
+
+// WithPriority returns a sender that queues requests with the priority.
+func (s *Sender) WithPriority(priority Priority) utils.IMessageSender {
+	return &prioritySender{sender: s, priority: priority}
+}
+
+type prioritySender struct {
+	sender   *Sender
+	priority Priority
+}
+
+func (p *prioritySender) SendMessage(ctx context.Context, params *bot.SendMessageParams) (*models.Message, error) {
+	return p.sender.sendMessage(ctx, p.priority, params)
+}
+
+func (p *prioritySender) AnswerCallbackQuery(ctx context.Context, params *bot.AnswerCallbackQueryParams) (bool, error) {
+	return p.sender.answerCallbackQuery(ctx, p.priority, params)
+}
+
+func (p *prioritySender) EditMessageText(ctx context.Context, params *bot.EditMessageTextParams) (*models.Message, error) {
+	return p.sender.editMessageText(ctx, p.priority, params)
+}
+
+func (s *Sender) sendMessage(ctx context.Context, priority Priority, params *bot.SendMessageParams) (*models.Message, error) {
+	if params.ParseMode == "" {
+		params.ParseMode = s.cfg.ParseMode
+	}
+	var msg *models.Message
+	err := s.do(ctx, priority, chatKey(params.ChatID), func(ctx context.Context) (err error) {
+		msg, err = s.inner.SendMessage(ctx, params)
+		return err
+	})
+	return msg, err
+}
+
+func (s *Sender) answerCallbackQuery(ctx context.Context, priority Priority, params *bot.AnswerCallbackQueryParams) (bool, error) {
+	var ok bool
+	err := s.do(ctx, priority, "", func(ctx context.Context) (err error) {
+		ok, err = s.inner.AnswerCallbackQuery(ctx, params)
+		return err
+	})
+	return ok, err
+}
+
+func (s *Sender) editMessageText(ctx context.Context, priority Priority, params *bot.EditMessageTextParams) (*models.Message, error) {
+	if params.ParseMode == "" {
+		params.ParseMode = s.cfg.ParseMode
+	}
+	var msg *models.Message
+	err := s.do(ctx, priority, chatKey(params.ChatID), func(ctx context.Context) (err error) {
+		msg, err = s.inner.EditMessageText(ctx, params)
+		return err
+	})
+	return msg, err
+}
+
+// do queues the call and waits for its result.
+func (s *Sender) do(ctx context.Context, priority Priority, chat string, call func(ctx context.Context) error) error {
+	req := &request{
+		ctx:      ctx,
+		priority: priority,
+		chat:     chat,
+		call:     call,
+		done:     make(chan error, 1),
+	}
+
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return ErrStopped
+	}
+	s.seq++
+	req.seq = s.seq
+	s.push(req)
+	s.mu.Unlock()
+	s.notify()
+
+	select {
+	case err := <-req.done:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Run dispatches queued requests until ctx is done. Requests still queued then fail with ErrStopped.
+func (s *Sender) Run(ctx context.Context) {
+	defer s.stop()
+
+	for {
+		select {
+		case s.workers <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+
+		s.mu.Lock()
+		req, wait := s.next(s.now())
+		s.mu.Unlock()
+		if req != nil {
+			go s.execute(req)
+			continue
+		}
+		<-s.workers
+
+		// A zero wait means an empty queue, a nil timer channel then blocks until a request is queued.
+		var timer *time.Timer
+		var timerC <-chan time.Time
+		if wait > 0 {
+			timer = time.NewTimer(wait)
+			timerC = timer.C
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-s.wake:
+		case <-timerC:
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+	}
+}
+
+func (s *Sender) stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.stopped = true
+	for _, req := range s.queue {
+		req.done <- ErrStopped
+	}
+	s.queue = nil
+}
+
+func (s *Sender) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// push inserts the request keeping the queue ordered by priority, then by arrival.
+func (s *Sender) push(req *request) {
+	i, _ := slices.BinarySearchFunc(s.queue, req, func(a, b *request) int {
+		if a.priority != b.priority {
+			return int(a.priority - b.priority)
+		}
+		return int(a.seq) - int(b.seq)
+	})
+	s.queue = slices.Insert(s.queue, i, req)
+}
+
+// next pops the first request in queue order that can be sent now and takes its tokens.
+// Otherwise it returns how long to wait until one can, or zero if the queue is empty.
+func (s *Sender) next(now time.Time) (*request, time.Duration) {
+	s.sweepChats(now)
+
+	globalWait := max(s.global.Wait(s.cfg.GlobalLimit, now), s.globalBlockedUntil.Sub(now))
+	var minWait time.Duration
+	for i := 0; i < len(s.queue); {
+		req := s.queue[i]
+		if err := req.ctx.Err(); err != nil {
+			s.queue = slices.Delete(s.queue, i, i+1)
+			req.done <- err
+			continue
+		}
+
+		wait := max(globalWait, req.notBefore.Sub(now))
+		chat := s.chats[req.chat]
+		if req.chat != "" {
+			wait = max(wait, chat.blockedUntil.Sub(now), chat.bucket.Wait(s.cfg.ChatLimit, now))
+		}
+		if wait <= 0 {
+			s.queue = slices.Delete(s.queue, i, i+1)
+			s.global, _ = s.global.Take(s.cfg.GlobalLimit, now)
+			if req.chat != "" {
+				chat.bucket, _ = chat.bucket.Take(s.cfg.ChatLimit, now)
+				s.chats[req.chat] = chat
+			}
+			return req, 0
+		}
+		if minWait == 0 || wait < minWait {
+			minWait = wait
+		}
+		i++
+	}
+	return nil, minWait
+}
+
+// sweepChats forgets chats that have a full budget again.
+func (s *Sender) sweepChats(now time.Time) {
+	if now.Sub(s.lastSweep) < chatSweepInterval {
+		return
+	}
+	s.lastSweep = now
+	for key, chat := range s.chats {
+		if now.After(chat.blockedUntil) && now.Sub(chat.bucket.UpdatedAt) >= s.cfg.ChatLimit.RefillTime() {
+			delete(s.chats, key)
+		}
+	}
+}
+
+func (s *Sender) execute(req *request) {
+	defer func() { <-s.workers }()
+
+	err := req.call(req.ctx)
+	if err == nil || req.attempt >= s.cfg.MaxRetries {
+		req.done <- err
+		return
+	}
+
+	now := s.now()
+	var tooManyRequests *bot.TooManyRequestsError
+	switch {
+	case errors.As(err, &tooManyRequests):
+		blockedUntil := now.Add(time.Duration(tooManyRequests.RetryAfter) * time.Second)
+		s.mu.Lock()
+		if req.chat == "" {
+			s.globalBlockedUntil = blockedUntil
+		} else {
+			chat := s.chats[req.chat]
+			chat.blockedUntil = blockedUntil
+			s.chats[req.chat] = chat
+		}
+		s.mu.Unlock()
+	case serverErrorRe.MatchString(err.Error()):
+		req.notBefore = now.Add(s.backoff(req.attempt))
+	default:
+		req.done <- err
+		return
+	}
+
+	req.attempt++
+	slog.WarnContext(req.ctx, "Telegram request failed, retrying",
+		logger.ErrorField, err.Error(),
+		"attempt", req.attempt,
+	)
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		req.done <- fmt.Errorf("%w: %w", ErrStopped, err)
+		return
+	}
+	s.push(req)
+	s.mu.Unlock()
+	s.notify()
+}
+
+func (s *Sender) backoff(attempt int) time.Duration {
+	backoff := s.cfg.BaseBackoff << attempt
+	if backoff <= 0 || backoff > s.cfg.MaxBackoff {
+		return s.cfg.MaxBackoff
+	}
+	return backoff
+}
+
+func chatKey(chatID any) string {
+	switch id := chatID.(type) {
+	case nil:
+		return ""
+	case int64:
+		return strconv.FormatInt(id, 10)
+	default:
+		return fmt.Sprint(id)
+	}
+}
@@ -0,0 +1,237 @@
+package sender
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+	"whitelist-bot/internal/ratelimit"
+
+	"github.com/go-telegram/bot"
+	"github.com/go-telegram/bot/models"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errServer = errors.New("error response from telegram for method sendMessage, 502 Bad Gateway")
+
+type sentMessage struct {
+	params *bot.SendMessageParams
+	at     time.Time
+}
+
+type fakeInner struct {
+	mu    sync.Mutex
+	sent  []sentMessage
+	errs  []error
+	gate  chan struct{}
+	calls int
+}
+
+func (f *fakeInner) SendMessage(ctx context.Context, params *bot.SendMessageParams) (*models.Message, error) {
+	if f.gate != nil && params.Text == "gate" {
+		<-f.gate
+	}
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.calls++
+	if len(f.errs) > 0 {
+		err := f.errs[0]
+		f.errs = f.errs[1:]
+		if err != nil {
+			return nil, err
+		}
+	}
+	f.sent = append(f.sent, sentMessage{params: params, at: time.Now()})
+	return &models.Message{Text: params.Text}, nil
+}
+
+func (f *fakeInner) AnswerCallbackQuery(context.Context, *bot.AnswerCallbackQueryParams) (bool, error) {
+	return true, nil
+}
+
+func (f *fakeInner) EditMessageText(context.Context, *bot.EditMessageTextParams) (*models.Message, error) {
+	return &models.Message{}, nil
+}
+
+func (f *fakeInner) texts() []string {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	texts := make([]string, 0, len(f.sent))
+	for _, m := range f.sent {
+		texts = append(texts, m.params.Text)
+	}
+	return texts
+}
+
+func testConfig() Config {
+	return Config{
+		GlobalLimit: ratelimit.Limit{Burst: 100, Interval: time.Millisecond},
+		ChatLimit:   ratelimit.Limit{Burst: 100, Interval: time.Millisecond},
+		MaxRetries:  2,
+		BaseBackoff: time.Millisecond,
+		MaxBackoff:  10 * time.Millisecond,
+		Workers:     4,
+		ParseMode:   models.ParseModeHTML,
+	}
+}
+
+func startSender(t *testing.T, inner *fakeInner, cfg Config) *Sender {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	s := New(inner, cfg)
+	done := make(chan struct{})
+	go func() {
+		s.Run(ctx)
+		close(done)
+	}()
+	t.Cleanup(func() {
+		cancel()
+		<-done
+	})
+	return s
+}
+
+func TestSender_SendMessage(t *testing.T) {
+	tests := []struct {
+		name      string
+		errs      []error
+		wantErr   bool
+		wantCalls int
+	}{
+		{name: "success", wantCalls: 1},
+		{name: "server error is retried", errs: []error{errServer, errServer}, wantCalls: 3},
+		{name: "server error retries are limited", errs: []error{errServer, errServer, errServer}, wantErr: true, wantCalls: 3},
+		{name: "bad request is not retried", errs: []error{bot.ErrorBadRequest}, wantErr: true, wantCalls: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := &fakeInner{errs: tt.errs}
+			s := startSender(t, inner, testConfig())
+
+			msg, err := s.SendMessage(context.Background(), &bot.SendMessageParams{ChatID: int64(1), Text: "hi"})
+			if tt.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "hi", msg.Text)
+			}
+			assert.Equal(t, tt.wantCalls, inner.calls)
+		})
+	}
+}
+
+func TestSender_SendMessage_DefaultParseMode(t *testing.T) {
+	inner := &fakeInner{}
+	s := startSender(t, inner, testConfig())
+
+	_, err := s.SendMessage(context.Background(), &bot.SendMessageParams{ChatID: int64(1), Text: "plain"})
+	require.NoError(t, err)
+	_, err = s.SendMessage(context.Background(), &bot.SendMessageParams{ChatID: int64(1), Text: "md", ParseMode: models.ParseModeMarkdown})
+	require.NoError(t, err)
+
+	require.Len(t, inner.sent, 2)
+	assert.Equal(t, models.ParseModeHTML, inner.sent[0].params.ParseMode)
+	assert.Equal(t, models.ParseModeMarkdown, inner.sent[1].params.ParseMode)
+}
+
+func TestSender_SendMessage_RetryAfter(t *testing.T) {
+	inner := &fakeInner{errs: []error{&bot.TooManyRequestsError{Message: "too many requests", RetryAfter: 1}}}
+	s := startSender(t, inner, testConfig())
+
+	start := time.Now()
+	_, err := s.SendMessage(context.Background(), &bot.SendMessageParams{ChatID: int64(1), Text: "hi"})
+	require.NoError(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), time.Second)
+	assert.Equal(t, 2, inner.calls)
+}
+
+func TestSender_ChatLimit(t *testing.T) {
+	cfg := testConfig()
+	cfg.ChatLimit = ratelimit.Limit{Burst: 1, Interval: 200 * time.Millisecond}
+	inner := &fakeInner{}
+	s := startSender(t, inner, cfg)
+
+	var wg sync.WaitGroup
+	for _, p := range []*bot.SendMessageParams{
+		{ChatID: int64(1), Text: "first"},
+		{ChatID: int64(1), Text: "second"},
+		{ChatID: int64(2), Text: "other"},
+	} {
+		wg.Go(func() {
+			_, err := s.SendMessage(context.Background(), p)
+			assert.NoError(t, err)
+		})
+	}
+	wg.Wait()
+
+	require.Len(t, inner.sent, 3)
+	at := map[string]time.Time{}
+	for _, m := range inner.sent {
+		at[m.params.Text] = m.at
+	}
+	first, second := at["first"], at["second"]
+	if second.Before(first) {
+		first, second = second, first
+	}
+	assert.GreaterOrEqual(t, second.Sub(first), 150*time.Millisecond)
+	assert.Less(t, at["other"].Sub(first).Abs(), 150*time.Millisecond)
+}
+
+func TestSender_Priority(t *testing.T) {
+	cfg := testConfig()
+	cfg.Workers = 1
+	inner := &fakeInner{gate: make(chan struct{})}
+	s := startSender(t, inner, cfg)
+	ctx := context.Background()
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		_, err := s.SendMessage(ctx, &bot.SendMessageParams{ChatID: int64(1), Text: "gate"})
+		assert.NoError(t, err)
+	})
+	// The only worker is busy with the gate message, so the others wait in the queue.
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.seq == 1 && len(s.queue) == 0
+	}, time.Second, time.Millisecond)
+
+	wg.Go(func() {
+		_, err := s.WithPriority(PriorityBulk).SendMessage(ctx, &bot.SendMessageParams{ChatID: int64(2), Text: "bulk"})
+		assert.NoError(t, err)
+	})
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.queue) == 1
+	}, time.Second, time.Millisecond)
+	wg.Go(func() {
+		_, err := s.SendMessage(ctx, &bot.SendMessageParams{ChatID: int64(3), Text: "reply"})
+		assert.NoError(t, err)
+	})
+	require.Eventually(t, func() bool {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return len(s.queue) == 2
+	}, time.Second, time.Millisecond)
+
+	close(inner.gate)
+	wg.Wait()
+	assert.Equal(t, []string{"gate", "reply", "bulk"}, inner.texts())
+}
+
+func TestSender_Stopped(t *testing.T) {
+	inner := &fakeInner{}
+	s := New(inner, testConfig())
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	s.Run(ctx)
+
+	_, err := s.SendMessage(context.Background(), &bot.SendMessageParams{ChatID: int64(1), Text: "hi"})
+	require.ErrorIs(t, err, ErrStopped)
+}
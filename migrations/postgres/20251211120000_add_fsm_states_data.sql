@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+ALTER TABLE fsm_states ADD COLUMN IF NOT EXISTS data JSONB;
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+ALTER TABLE fsm_states DROP COLUMN IF EXISTS data;
+-- +goose StatementEnd
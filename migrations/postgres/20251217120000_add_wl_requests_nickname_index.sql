@@ -0,0 +1,9 @@
+-- +goose Up
+-- +goose StatementBegin
+CREATE INDEX IF NOT EXISTS idx_wl_requests_nickname_status ON wl_requests(LOWER(nickname), status);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+DROP INDEX IF EXISTS idx_wl_requests_nickname_status;
+-- +goose StatementEnd
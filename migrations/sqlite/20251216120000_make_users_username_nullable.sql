@@ -0,0 +1,46 @@
+-- +goose Up
+-- +goose StatementBegin
+-- SQLite can't drop a NOT NULL constraint, so the table is rebuilt.
+CREATE TABLE users_new (
+    id TEXT PRIMARY KEY NOT NULL,
+    telegram_id INTEGER UNIQUE NOT NULL,
+    chat_id INTEGER NOT NULL,
+    first_name TEXT,
+    last_name TEXT,
+    username TEXT,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    language_code TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO users_new (id, telegram_id, chat_id, first_name, last_name, username, created_at, updated_at, language_code)
+SELECT id, telegram_id, chat_id, first_name, last_name, NULLIF(username, ''), created_at, updated_at, language_code FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_new RENAME TO users;
+
+CREATE INDEX IF NOT EXISTS idx_users_telegram_id ON users(telegram_id);
+-- +goose StatementEnd
+
+-- +goose Down
+-- +goose StatementBegin
+CREATE TABLE users_old (
+    id TEXT PRIMARY KEY NOT NULL,
+    telegram_id INTEGER UNIQUE NOT NULL,
+    chat_id INTEGER NOT NULL,
+    first_name TEXT,
+    last_name TEXT,
+    username TEXT NOT NULL,
+    created_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    updated_at TEXT NOT NULL DEFAULT (strftime('%Y-%m-%d %H:%M:%f', 'now')),
+    language_code TEXT NOT NULL DEFAULT ''
+);
+
+INSERT INTO users_old (id, telegram_id, chat_id, first_name, last_name, username, created_at, updated_at, language_code)
+SELECT id, telegram_id, chat_id, first_name, last_name, COALESCE(username, ''), created_at, updated_at, language_code FROM users;
+
+DROP TABLE users;
+ALTER TABLE users_old RENAME TO users;
+
+CREATE INDEX IF NOT EXISTS idx_users_telegram_id ON users(telegram_id);
+-- +goose StatementEnd
@@ -0,0 +1,17 @@
+-- Times were stored with the local offset, e.g. 2025-12-01T02:30:00+0300. In UTC the text columns compare
+-- in chronological order, so the age queries can filter and sort in SQL.
+
+-- +goose Up
+-- +goose StatementBegin
+UPDATE wl_requests
+SET created_at = strftime('%Y-%m-%dT%H:%M:%S', substr(created_at, 1, 19) || substr(created_at, 20, 3) || ':' || substr(created_at, 23, 2)) || '+0000'
+WHERE created_at NOT LIKE '%+0000';
+-- +goose StatementEnd
+-- +goose StatementBegin
+UPDATE wl_requests
+SET updated_at = strftime('%Y-%m-%dT%H:%M:%S', substr(updated_at, 1, 19) || substr(updated_at, 20, 3) || ':' || substr(updated_at, 23, 2)) || '+0000'
+WHERE updated_at NOT LIKE '%+0000';
+-- +goose StatementEnd
+
+-- +goose Down
+-- UTC times are still valid, nothing to revert.
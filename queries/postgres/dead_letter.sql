@@ -0,0 +1,22 @@
+-- Dead Letter Queries
+--
+-- name: CreateDeadLetter :exec
+INSERT INTO dead_letters (id, topic, data, error, attempts, failed_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (id) DO NOTHING;
+
+-- name: DeadLetters :many
+SELECT * FROM dead_letters
+ORDER BY failed_at DESC
+LIMIT sqlc.arg('limit')::bigint;
+
+-- name: DeadLetterByID :one
+SELECT * FROM dead_letters
+WHERE id = $1;
+
+-- name: DeleteDeadLetter :execrows
+DELETE FROM dead_letters
+WHERE id = $1;
+
+-- name: DeleteAllDeadLetters :execrows
+DELETE FROM dead_letters;
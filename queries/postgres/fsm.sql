@@ -0,0 +1,15 @@
+-- FSM Queries
+--
+-- name: FSMStateByUserID :one
+SELECT * FROM fsm_states
+WHERE user_id = $1;
+
+-- name: UpsertFSMState :exec
+INSERT INTO fsm_states (user_id, state, data, expires_at, updated_at)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (user_id) DO UPDATE
+SET state = EXCLUDED.state, data = EXCLUDED.data, expires_at = EXCLUDED.expires_at, updated_at = EXCLUDED.updated_at;
+
+-- name: DeleteFSMState :exec
+DELETE FROM fsm_states
+WHERE user_id = $1;
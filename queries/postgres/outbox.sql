@@ -0,0 +1,23 @@
+-- Outbox Queries
+--
+-- name: ClaimOutboxMessages :many
+UPDATE outbox
+SET locked_until = sqlc.arg('locked_until')::timestamptz
+WHERE id IN (
+    SELECT id FROM outbox
+    WHERE delivered_at IS NULL
+      AND (locked_until IS NULL OR locked_until < sqlc.arg('now')::timestamptz)
+    ORDER BY created_at, id
+    LIMIT sqlc.arg('limit')::bigint
+    FOR UPDATE SKIP LOCKED
+)
+RETURNING *;
+
+-- name: MarkOutboxMessageDelivered :exec
+UPDATE outbox
+SET delivered_at = sqlc.arg('delivered_at')::timestamptz, locked_until = NULL
+WHERE id = sqlc.arg('id');
+
+-- name: DeleteDeliveredOutboxMessages :execrows
+DELETE FROM outbox
+WHERE delivered_at < sqlc.arg('before')::timestamptz;
@@ -5,16 +5,27 @@ SELECT * FROM users
 WHERE telegram_id = $1;
 
 -- name: CreateUser :one
-INSERT INTO users (id, telegram_id, chat_id, first_name, last_name, username, created_at, updated_at)
-VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+INSERT INTO users (id, telegram_id, chat_id, first_name, last_name, username, language_code, created_at, updated_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
 RETURNING *;
 
 -- name: UpdateUser :one
 UPDATE users
-SET telegram_id = $1, chat_id = $2, first_name = $3, last_name = $4, username = $5, updated_at = $6
-WHERE id = $7
+SET telegram_id = $1, chat_id = $2, first_name = $3, last_name = $4, username = $5, language_code = $6, updated_at = $7
+WHERE id = $8
 RETURNING *;
 
 -- name: UserByID :one
 SELECT * FROM users
 WHERE id = $1;
+
+-- name: AddUsernameHistory :exec
+INSERT INTO user_username_history (id, user_id, username, replaced_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: PreviousUsernames :many
+SELECT username FROM user_username_history
+WHERE user_id = $1 AND username IS DISTINCT FROM $2
+GROUP BY username
+ORDER BY MAX(replaced_at) DESC
+LIMIT $3;
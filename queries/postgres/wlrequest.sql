@@ -10,15 +10,22 @@ VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: UpdateWLRequest :one
+-- The status the request was decided from guards against a concurrent decision or expiration.
 UPDATE wl_requests
 SET requester_id = $1, nickname = $2, status = $3, decline_reason = $4, arbiter_id = $5, updated_at = $6
-WHERE id = $7
+WHERE id = $7 AND status = sqlc.arg('from_status')
 RETURNING *;
 
 -- name: WLRequestByID :one
 SELECT * FROM wl_requests
 WHERE id = $1;
 
+-- name: WLRequestsByNickname :many
+SELECT * FROM wl_requests
+WHERE LOWER(nickname) = LOWER(sqlc.arg('nickname')::text) AND status = sqlc.arg('status')
+ORDER BY created_at DESC
+LIMIT sqlc.arg('limit')::bigint;
+
 -- name: PendingWLRequests :many
 SELECT * FROM wl_requests
 WHERE status = 'pending'
@@ -29,3 +36,24 @@ SELECT sqlc.embed(wl_requests), sqlc.embed(users) FROM wl_requests
 JOIN users ON wl_requests.requester_id = users.id
 WHERE status = 'pending'
 LIMIT sqlc.arg('limit')::bigint;
+
+-- name: CountPendingWLRequests :one
+SELECT COUNT(*) FROM wl_requests
+WHERE status = 'pending';
+
+-- name: PendingWLRequestsCreatedBefore :many
+SELECT * FROM wl_requests
+WHERE status = 'pending' AND created_at < sqlc.arg('created_before')::timestamptz
+ORDER BY created_at
+LIMIT sqlc.arg('limit')::bigint;
+
+-- name: StalePendingWLRequests :many
+SELECT * FROM wl_requests
+WHERE status = 'pending' AND created_at < sqlc.arg('created_before')::timestamptz
+ORDER BY created_at
+LIMIT sqlc.arg('limit')::bigint
+FOR UPDATE SKIP LOCKED;
+
+-- name: CreateOutboxMessage :exec
+INSERT INTO outbox (id, topic, data, created_at)
+VALUES ($1, $2, $3, $4);
@@ -0,0 +1,22 @@
+-- Outbox Queries
+--
+-- name: ClaimOutboxMessages :many
+UPDATE outbox
+SET locked_until = :locked_until
+WHERE id IN (
+    SELECT id FROM outbox
+    WHERE delivered_at = ''
+      AND locked_until < :now
+    ORDER BY created_at, id
+    LIMIT :limit
+)
+RETURNING *;
+
+-- name: MarkOutboxMessageDelivered :exec
+UPDATE outbox
+SET delivered_at = :delivered_at, locked_until = ''
+WHERE id = :id;
+
+-- name: DeleteDeliveredOutboxMessages :execrows
+DELETE FROM outbox
+WHERE delivered_at != '' AND delivered_at < :before;
@@ -5,13 +5,13 @@ SELECT * FROM users
 WHERE telegram_id = :telegram_id;
 
 -- name: CreateUser :one
-INSERT INTO users (id, telegram_id, first_name, last_name, username, created_at, updated_at)
-VALUES (:id, :telegram_id, :first_name, :last_name, :username, :created_at, :updated_at)
+INSERT INTO users (id, telegram_id, first_name, last_name, username, language_code, created_at, updated_at)
+VALUES (:id, :telegram_id, :first_name, :last_name, :username, :language_code, :created_at, :updated_at)
 RETURNING *;
 
 -- name: UpdateUser :one
 UPDATE users
-SET telegram_id = :telegram_id, first_name = :first_name, last_name = :last_name, username = :username, updated_at = :updated_at
+SET telegram_id = :telegram_id, first_name = :first_name, last_name = :last_name, username = :username, language_code = :language_code, updated_at = :updated_at
 WHERE id = :id
 RETURNING *;
 
@@ -10,9 +10,10 @@ VALUES (:id, :requester_id, :nickname, :status, :decline_reason, :arbiter_id, :c
 RETURNING *;
 
 -- name: UpdateWLRequest :one
+-- The status the request was decided from guards against a concurrent decision or expiration.
 UPDATE wl_requests
 SET requester_id = :requester_id, nickname = :nickname, status = :status, decline_reason = :decline_reason, arbiter_id = :arbiter_id, updated_at = :updated_at
-WHERE id = :id
+WHERE id = :id AND status = :from_status
 RETURNING *;
 
 -- name: WLRequestByID :one
@@ -29,3 +30,17 @@ SELECT * FROM wl_requests
 WHERE status = 'pending'
 ORDER BY created_at ASC
 LIMIT 1;
+
+-- name: CountPendingWLRequests :one
+SELECT COUNT(*) FROM wl_requests
+WHERE status = 'pending';
+
+-- name: PendingWLRequestsCreatedBefore :many
+SELECT * FROM wl_requests
+WHERE status = 'pending' AND created_at < :created_before
+ORDER BY created_at ASC
+LIMIT :limit;
+
+-- name: CreateOutboxMessage :exec
+INSERT INTO outbox (id, topic, data, created_at)
+VALUES (:id, :topic, :data, :created_at);